@@ -9,6 +9,7 @@ import (
 
 	"cloud.google.com/go/storage"
 	"github.com/routeviews/google-cloud-storage/pkg/auth"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
 	"github.com/routeviews/google-cloud-storage/pkg/synchronizer"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -29,7 +30,8 @@ var (
 	useTLS = flag.Bool("use_tls", true, "Enable TLS if true. Disable TLS if testing with a local instance.")
 
 	runHTTP = flag.Bool("http_server", true, `If true, this will be run as an
-	 HTTP server, and users can trigger sync by accessing path '/'. Otherwise,
+	 HTTP server, and users can trigger sync by accessing path '/'. Metrics are
+	 served on '/metrics'. Otherwise,
 	 it will run a one-off synchronization.`)
 )
 
@@ -101,6 +103,7 @@ func main() {
 			w.Write([]byte(err.Error()))
 		}
 	})
+	http.Handle(metrics.Path, metrics.Handler())
 	log.Infof("Listening on port %s", port)
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		log.Fatal(err)
@@ -4,13 +4,17 @@ package main
 import (
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 
 	"github.com/routeviews/google-cloud-storage/pkg/auth"
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
@@ -19,21 +23,33 @@ import (
 const (
 	// Max message size set to 50mb.
 	maxMsgSize = 512 * 1024 * 1024
+	// Size of each content message when streaming a file: 1mb.
+	chunkSize = 1024 * 1024
 )
 
 var (
-	server  = flag.String("server", "localhost:9876", "The host:port of the gRPC server.")
-	file    = flag.String("file", "", "A local File to transfer to cloud storage.")
-	saKey   = flag.String("sa_key", "", "Service account private key.")
-	project = flag.String("project", "", "Determines which project this file belongs to.")
-	useTLS  = flag.Bool("use_tls", true, "Enable TLS if true.")
+	server   = flag.String("server", "localhost:9876", "The host:port of the gRPC server.")
+	file     = flag.String("file", "", "A local File to transfer to cloud storage.")
+	saKey    = flag.String("sa_key", "", "Service account private key.")
+	project  = flag.String("project", "", "Determines which project this file belongs to.")
+	useTLS   = flag.Bool("use_tls", true, "Enable TLS if true.")
+	stream   = flag.Bool("stream", false, "Stream the file in chunks instead of a single request.")
+	caFile   = flag.String("ca_file", "", "PEM bundle of the CAs of the server certificate, instead of the system roots.")
+	certFile = flag.String("cert_file", "", "PEM client certificate, for servers requiring mTLS.")
+	keyFile  = flag.String("key_file", "", "PEM key of the client certificate.")
 )
 
+// newConn connects to host. With a client certificate and no service
+// account key, the caller is identified by its certificate alone.
 func newConn(ctx context.Context, host string, saPath string) (*grpc.ClientConn, error) {
-	if *useTLS {
-		return auth.NewAuthConn(ctx, host, saPath)
+	if !*useTLS {
+		return auth.InsecureConn(host)
 	}
-	return auth.InsecureConn(host)
+	o := &auth.TLSOptions{CAFile: *caFile, CertFile: *certFile, KeyFile: *keyFile}
+	if *certFile != "" && saPath == "" {
+		return auth.NewTLSConn(host, o)
+	}
+	return auth.NewAuthConnTLS(ctx, host, saPath, o)
 }
 
 func upload(ctx context.Context, conn *grpc.ClientConn, p *pb.FileRequest) (*pb.FileResponse, error) {
@@ -41,6 +57,61 @@ func upload(ctx context.Context, conn *grpc.ClientConn, p *pb.FileRequest) (*pb.
 	return client.FileUpload(ctx, p)
 }
 
+// uploadStream sends the file at path in chunks, the file is never held
+// in memory as a whole.
+func uploadStream(ctx context.Context, conn *grpc.ClientConn, path string, proj pb.FileRequest_Project) (*pb.FileResponse, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Checksum the file before sending, the header leads the stream.
+	h, sh := md5.New(), sha256.New()
+	if _, err := io.Copy(io.MultiWriter(h, sh), f); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	client := pb.NewRVClient(conn)
+	s, err := client.FileUploadStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+		Filename: path,
+		Md5Sum:   fmt.Sprintf("%x", h.Sum(nil)),
+		Checksum: &pb.FileChunk_Header_Sha256{Sha256: fmt.Sprintf("%x", sh.Sum(nil))},
+		Project:  proj,
+	}}}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			err := s.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: buf[:n]}})
+			// The server ends the stream early if the file is already stored
+			// or the upload failed; the response tells which.
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.CloseAndRecv()
+}
+
 func makeReq(path string, proj pb.FileRequest_Project) (*pb.FileRequest, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -54,6 +125,7 @@ func makeReq(path string, proj pb.FileRequest_Project) (*pb.FileRequest, error)
 		Filename: path,
 		Content:  raw,
 		Md5Sum:   fmt.Sprintf("%x", md5.Sum(raw)),
+		Checksum: &pb.FileRequest_Sha256{Sha256: fmt.Sprintf("%x", sha256.Sum256(raw))},
 		Project:  proj,
 	}, nil
 }
@@ -71,15 +143,30 @@ func main() {
 	}
 	defer conn.Close()
 
-	req, err := makeReq(*file, pb.FileRequest_Project(pb.FileRequest_Project_value[*project]))
-	if err != nil {
-		log.Fatalf("fail to makeReq(%v): %v", *file, err)
+	proj := pb.FileRequest_Project(pb.FileRequest_Project_value[*project])
+	var resp *pb.FileResponse
+	if *stream {
+		resp, err = uploadStream(ctx, conn, *file, proj)
+	} else {
+		var req *pb.FileRequest
+		req, err = makeReq(*file, proj)
+		if err != nil {
+			log.Fatalf("fail to makeReq(%v): %v", *file, err)
+		}
+		resp, err = upload(ctx, conn, req)
 	}
-
-	resp, err := upload(ctx, conn, req)
 	if err != nil {
+		st := status.Convert(err)
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok {
+				log.Fatalf("failed to upload file(%v): %s %s: %s %v", *file, st.Code(), info.GetReason(), st.Message(), info.GetMetadata())
+			}
+		}
 		log.Fatalf("failed to upload file(%v): %v", *file, err)
 	}
 
 	fmt.Printf("Successfully uploaded file(%v) status: %v\n", *file, resp.GetStatus())
+	if resp.GetUrl() != "" {
+		fmt.Printf("Stored %v generation: %d size: %d crc32c: %08x\n", resp.GetUrl(), resp.GetGeneration(), resp.GetSize(), resp.GetCrc32C())
+	}
 }
@@ -5,15 +5,14 @@
 // Basic flow is:
 //   1) start at the top of an FTP site.
 //   2) download each file in turn, walking the remote directory tree.
-//   3) calculate the md5() checksum for each file downloaded.
-//   4) validate that the checksum matches the cloud-storage object's MD5 value.
+//   3) calculate the md5() and crc32c() checksums for each file downloaded.
+//   4) validate that the checksum matches the cloud-storage object's MD5 value,
+//      or its CRC32C value for composite objects which have no MD5.
 //   5) if there is a mis-match, upload the ftp content to cloud-storage.
 package main
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -25,6 +24,8 @@ import (
 	"github.com/golang/glog"
 	"github.com/jlaffaye/ftp"
 	"github.com/routeviews/google-cloud-storage/pkg/auth"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
+	uploadutils "github.com/routeviews/google-cloud-storage/pkg/utils/upload"
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
 	"google.golang.org/grpc"
 )
@@ -55,6 +56,8 @@ var (
 	threads       = flag.Int("threads", 10, "Number of ftp/cloud processing threads.")
 
 	useTLS = flag.Bool("use_tls", true, "Enable TLS if true.")
+
+	metricsAddr = flag.String("metrics_addr", ":9090", "Address to serve Prometheus metrics on, disabled if empty.")
 )
 
 type client struct {
@@ -179,9 +182,10 @@ func (c *client) metric(k string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.metrics[k]++
+	metrics.ArchivesSynced.WithLabelValues(k).Inc()
 }
 
-// readChannel reads FTP file results from a channel, collects and compares MD5 checksums
+// readChannel reads FTP file results from a channel, collects and compares checksums
 // and uploads files to cloud-storage if mismatches occur.
 func (c *client) readChannel(ctx context.Context) {
 	defer c.wg.Done()
@@ -214,23 +218,24 @@ func (c *client) readChannel(ctx context.Context) {
 			continue
 		}
 
-		csSum, err := c.md5FromGCS(ctx, fn)
+		csSums, err := uploadutils.ChecksumsFromGCS(ctx, c.bh, fn)
 		if err != nil {
-			csSum = ""
+			csSums = nil
 		}
 
-		fSum, fc, err := c.md5FromFTP(ef.name, f)
+		fc, err := c.fromFTP(ef.name, f)
 		if err != nil {
 			if ftpErrs < maxFTPErrs {
-				glog.Infof("error getting md5(%s): %v", ef.name, err)
+				glog.Infof("error getting content(%s): %v", ef.name, err)
 				ftpErrs++
 				continue
 			}
 			// Enough failures have happened, exit and restart.
-			glog.Fatalf("failed to get ftp md5 for file(%s): %v", ef.name, err)
+			glog.Fatalf("failed to get ftp content for file(%s): %v", ef.name, err)
 		}
 
-		if csSum == fSum {
+		fSums := uploadutils.ChecksumsOf(fc)
+		if fSums.Match(csSums) {
 			c.metric("skip")
 			continue
 		}
@@ -239,7 +244,8 @@ func (c *client) readChannel(ctx context.Context) {
 		req := pb.FileRequest{
 			Filename: ef.name,
 			Content:  fc,
-			Md5Sum:   fSum,
+			Md5Sum:   fSums.MD5,
+			Checksum: &pb.FileRequest_Sha256{Sha256: fSums.SHA256},
 			Project:  pb.FileRequest_ROUTEVIEWS,
 		}
 		resp, err := c.gClient.FileUpload(ctx, &req)
@@ -257,23 +263,14 @@ func (c *client) readChannel(ctx context.Context) {
 	}
 }
 
-func (c *client) md5FromGCS(ctx context.Context, path string) (string, error) {
-	attrs, err := c.bh.Object(path).Attrs(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to get attrs for obj: %v", err)
-	}
-	return hex.EncodeToString(attrs.MD5), nil
-}
-
-func (c *client) md5FromFTP(path string, fc *ftp.ServerConn) (string, []byte, error) {
+func (c *client) fromFTP(path string, fc *ftp.ServerConn) ([]byte, error) {
 	r, err := fc.Retr(path)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to RETR the path: %v", err)
+		return nil, fmt.Errorf("failed to RETR the path: %v", err)
 	}
 	defer r.Close()
 
-	buf, err := ioutil.ReadAll(r)
-	return fmt.Sprintf("%x", md5.Sum(buf)), buf, nil
+	return ioutil.ReadAll(r)
 }
 
 func main() {
@@ -305,6 +302,14 @@ func main() {
 		glog.Fatalf("failed to create the client: %v", err)
 	}
 
+	if *metricsAddr != "" {
+		go func() {
+			if err := metrics.ListenAndServe(*metricsAddr); err != nil {
+				glog.Errorf("failed to serve metrics: %v", err)
+			}
+		}()
+	}
+
 	// Start the readChannel threads.
 	for i := 0; i < *threads; i++ {
 		go c.readChannel(ctx)
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/audit"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	defaultAuditRollInterval = 10 * time.Minute
+	defaultAuditRollRecords  = 1000
+)
+
+// auditConfig sets where the audit records of uploads go: a local file, or
+// a bucket.
+type auditConfig struct {
+	// File is the path of a local JSONL file records are appended to.
+	File string `yaml:"file"`
+	// Bucket receives records rolled into JSONL objects, every
+	// RollInterval or RollRecords records.
+	Bucket       string        `yaml:"bucket"`
+	RollInterval time.Duration `yaml:"roll_interval"`
+	RollRecords  int           `yaml:"roll_records"`
+}
+
+// newSink checks the audit config, filling in defaults, and returns the
+// sink of audit records.
+func (a *auditConfig) newSink(ctx context.Context, clients *objstore.Clients) (audit.Sink, error) {
+	switch {
+	case a.File != "" && a.Bucket != "":
+		return nil, fmt.Errorf("audit requires one of file and bucket, not both")
+	case a.File != "":
+		return audit.NewFileSink(a.File)
+	case a.Bucket == "":
+		return nil, fmt.Errorf("audit requires a file or a bucket")
+	}
+	if a.RollInterval == 0 {
+		a.RollInterval = defaultAuditRollInterval
+	}
+	if a.RollRecords == 0 {
+		a.RollRecords = defaultAuditRollRecords
+	}
+	st, err := objstore.Open(a.Bucket, clients)
+	if err == nil {
+		err = st.Check(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad audit bucket %s: %v", a.Bucket, err)
+	}
+	return audit.NewStoreSink(st, a.RollInterval, a.RollRecords)
+}
+
+// auditUpload records the outcome of an upload in the audit log, if one is
+// configured. A nil resp with a nil err is recorded as a failure.
+func (r rvServer) auditUpload(ctx context.Context, proj pb.FileRequest_Project, fn string, size int64, sums checksums, resp *pb.FileResponse, err error) {
+	if r.audit == nil {
+		return
+	}
+	rec := &audit.Record{
+		Time:     time.Now().UTC(),
+		Project:  proj.String(),
+		Filename: fn,
+		Size:     size,
+		MD5:      sums.MD5,
+		SHA256:   sums.SHA256,
+		CRC32C:   sums.CRC32C,
+		Result:   pb.FileResponse_FAIL.String(),
+	}
+	if m, ok := grpc.Method(ctx); ok {
+		rec.Method = path.Base(m)
+	}
+	rec.Caller, _ = callerFrom(ctx)
+	if p, ok := peer.FromContext(ctx); ok {
+		rec.Peer = p.Addr.String()
+	}
+	switch {
+	case err != nil:
+		rec.Error = err.Error()
+	case resp != nil:
+		rec.Result = resp.GetStatus().String()
+		rec.Error = resp.GetErrorMessage()
+		if inv := resp.GetInvalidArchive(); inv != nil && rec.Error == "" {
+			rec.Error = inv.GetReason()
+		}
+	}
+	if err := r.audit.Write(ctx, rec); err != nil {
+		glog.Errorf("Failed to write audit record %+v: %v", rec, err)
+	}
+}
+
+// auditRequest records the outcome of the upload of a FileRequest.
+func (r rvServer) auditRequest(ctx context.Context, req *pb.FileRequest, resp *pb.FileResponse, err error) {
+	r.auditUpload(ctx, req.GetProject(), req.GetFilename(), int64(len(req.GetContent())),
+		newChecksums(req.GetMd5Sum(), req.GetChecksum()), resp, err)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/routeviews/google-cloud-storage/pkg/audit"
+	"github.com/routeviews/google-cloud-storage/pkg/auth/authtest"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUploadAudit(t *testing.T) {
+	const aud = "https://rv-server.example.com"
+	iss := authtest.NewIssuer(t, "https://accounts.google.com")
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	srv.CreateBucket("bar")
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Buckets: map[string]string{
+			pb.FileRequest_ROUTEVIEWS.String(): "foo",
+			pb.FileRequest_RPKI_RARC.String():  "bar",
+		},
+		Auth: &authConfig{
+			JWKSURL:  iss.JWKSURL,
+			Audience: aud,
+			Allow: map[string][]string{
+				"uploader@example.com": {pb.FileRequest_ROUTEVIEWS.String()},
+			},
+		},
+		Audit: &auditConfig{File: path},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	client := startTestServer(t, fs, fs.interceptors()...)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+iss.Token(t, "uploader@example.com", aud))
+
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+	md5sum := hex.EncodeToString(sum[:])
+	for _, req := range []*pb.FileRequest{{
+		Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+		Md5Sum:   md5sum,
+		Content:  content,
+		Project:  pb.FileRequest_ROUTEVIEWS,
+	}, {
+		Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+		Md5Sum:   "abcdef",
+		Content:  content,
+		Project:  pb.FileRequest_ROUTEVIEWS,
+	}, {
+		Filename: "rpki/2021.11/20211101.tgz",
+		Md5Sum:   md5sum,
+		Content:  content,
+		Project:  pb.FileRequest_RPKI_RARC,
+	}} {
+		client.FileUpload(ctx, req)
+	}
+	stream, err := client.FileUploadStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+		Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0030.bz2",
+		Md5Sum:   md5sum,
+		Project:  pb.FileRequest_ROUTEVIEWS,
+	}}})
+	stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: content}})
+	if _, err := stream.CloseAndRecv(); err != nil {
+		t.Fatalf("FileUploadStream(): %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []*audit.Record
+	if err := audit.Read(f, &audit.Query{}, func(rec *audit.Record) error {
+		if rec.Time.IsZero() || rec.Peer == "" {
+			t.Errorf("record %+v has no time or peer", rec)
+		}
+		got = append(got, rec)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []*audit.Record{{
+		Method:   "FileUpload",
+		Caller:   "uploader@example.com",
+		Project:  "ROUTEVIEWS",
+		Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+		Size:     int64(len(content)),
+		MD5:      md5sum,
+		Result:   "SUCCESS",
+	}, {
+		Method:   "FileUpload",
+		Caller:   "uploader@example.com",
+		Project:  "ROUTEVIEWS",
+		Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+		Size:     int64(len(content)),
+		MD5:      "abcdef",
+		Result:   "FAIL",
+		Error:    `checksum failure req("abcdef") != calc("` + md5sum + `")`,
+	}, {
+		Method:   "FileUpload",
+		Caller:   "uploader@example.com",
+		Project:  "RPKI_RARC",
+		Filename: "rpki/2021.11/20211101.tgz",
+		Size:     int64(len(content)),
+		MD5:      md5sum,
+		Result:   "FAIL",
+		Error:    "rpc error: code = PermissionDenied desc = uploader@example.com may not upload to project RPKI_RARC",
+	}, {
+		Method:   "FileUploadStream",
+		Caller:   "uploader@example.com",
+		Project:  "ROUTEVIEWS",
+		Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0030.bz2",
+		Size:     int64(len(content)),
+		MD5:      md5sum,
+		Result:   "SUCCESS",
+	}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(audit.Record{}, "Time", "Peer")); diff != "" {
+		t.Errorf("audit records diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/auth"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authConfig enables the verification of callers' ID tokens, and limits
+// each caller to the projects it may upload to.
+type authConfig struct {
+	// JWKSURL serves the keys which sign ID tokens, Google's by default.
+	JWKSURL string `yaml:"jwks_url"`
+	// Issuers of ID tokens, Google's by default.
+	Issuers []string `yaml:"issuers"`
+	// Audience of ID tokens, the URL of the server. It may be left empty
+	// if callers are identified by their client certificates only.
+	Audience string `yaml:"audience"`
+	// Allow maps caller emails, or the identities of client certificates,
+	// to the projects they may upload to.
+	Allow map[string][]string `yaml:"allow"`
+	// Admins are the caller emails allowed to call admin RPCs, ie:
+	// RetractFile.
+	Admins []string `yaml:"admins"`
+}
+
+// newVerifier checks the auth config, filling in defaults, and returns the
+// verifier of ID tokens. With clientCerts, callers may be identified by
+// their client certificates instead, and the verifier is nil if no audience
+// is set.
+func (a *authConfig) newVerifier(clientCerts bool) (*auth.Verifier, error) {
+	if a.Audience == "" && !clientCerts {
+		return nil, fmt.Errorf("auth requires an audience, or tls client certificates")
+	}
+	if a.JWKSURL == "" {
+		a.JWKSURL = auth.GoogleJWKSURL
+	}
+	if len(a.Issuers) == 0 {
+		a.Issuers = auth.GoogleIssuers
+	}
+	for email, projs := range a.Allow {
+		for _, proj := range projs {
+			if pb.FileRequest_Project_value[proj] == int32(pb.FileRequest_UNKNOWN) {
+				return nil, fmt.Errorf("bad project %s allowed to %s", proj, email)
+			}
+		}
+	}
+	if a.Audience == "" {
+		return nil, nil
+	}
+	return auth.NewVerifier(a.JWKSURL, a.Issuers, a.Audience), nil
+}
+
+type callerKey struct{}
+
+// callerFrom returns the identity of the caller, set by the interceptors.
+func callerFrom(ctx context.Context) (string, bool) {
+	c, ok := ctx.Value(callerKey{}).(string)
+	return c, ok
+}
+
+// interceptors returns the server options which authenticate and authorize
+// calls to the RV service. They let calls through while auth is not
+// configured, so auth may be set by a reload.
+func (r rvServer) interceptors() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(r.unaryAuth),
+		grpc.StreamInterceptor(r.streamAuth),
+	}
+}
+
+// rvMethod is true for methods of the RV service. Other services, like
+// health checks and reflection, are not authenticated.
+func rvMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+pb.RV_ServiceDesc.ServiceName+"/")
+}
+
+// authenticate verifies the bearer ID token of the call, or else its
+// client certificate, and returns the context with the caller's identity.
+func (r rvServer) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, v := range md.Get("authorization") {
+		if strings.HasPrefix(v, "Bearer ") {
+			token = strings.TrimPrefix(v, "Bearer ")
+		}
+	}
+	if token == "" {
+		if id, ok := certIdentity(ctx); ok {
+			return context.WithValue(ctx, callerKey{}, id), nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	if r.verifier == nil {
+		return nil, status.Error(codes.Unauthenticated, "ID tokens are not accepted, without an auth audience")
+	}
+	c, err := r.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid ID token: %v", err)
+	}
+	if c.Email == "" || !c.EmailVerified {
+		return nil, status.Error(codes.Unauthenticated, "ID token has no verified email")
+	}
+	return context.WithValue(ctx, callerKey{}, c.Email), nil
+}
+
+// authorize checks that the caller may upload to, and list, proj.
+func (r rvServer) authorize(ctx context.Context, proj pb.FileRequest_Project) error {
+	if r.conf.Auth == nil {
+		return nil
+	}
+	c, ok := callerFrom(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unknown caller")
+	}
+	if proj == pb.FileRequest_UNKNOWN {
+		return status.Errorf(codes.PermissionDenied, "%s must name a project", c)
+	}
+	for _, p := range r.conf.Auth.Allow[c] {
+		if p == proj.String() {
+			return nil
+		}
+	}
+	glog.Warningf("Denied %s access to project %s", c, proj)
+	return status.Errorf(codes.PermissionDenied, "%s may not upload to project %s", c, proj)
+}
+
+// authorizeSession checks that the caller may use the upload session s:
+// the caller must be the one who started it, and still be allowed to its
+// project.
+func (r rvServer) authorizeSession(ctx context.Context, s *uploadSession) error {
+	if r.conf.Auth == nil {
+		return nil
+	}
+	if c, _ := callerFrom(ctx); c != s.Caller {
+		glog.Warningf("Denied %s access to upload session %s of %s", c, s.ID, s.Caller)
+		return status.Errorf(codes.PermissionDenied, "%s may not use the upload session of another caller", c)
+	}
+	return r.authorize(ctx, s.Project)
+}
+
+// requestProject returns the project of a request which starts an upload,
+// lists an archive or reads the status of files. Listing the status of all
+// projects is denied while auth is configured. Calls on upload sessions are
+// authorized by their session, see authorizeSession.
+func requestProject(req interface{}) (pb.FileRequest_Project, bool) {
+	switch req := req.(type) {
+	case *pb.FileRequest:
+		return req.GetProject(), true
+	case *pb.StartUploadRequest:
+		return req.GetProject(), true
+	case *pb.ListArchiveRequest:
+		return req.GetProject(), true
+	case *pb.FileStatusRequest:
+		return req.GetProject(), true
+	case *pb.ListFileStatusRequest:
+		return req.GetProject(), true
+	}
+	return pb.FileRequest_UNKNOWN, false
+}
+
+func (r rvServer) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	r = r.load()
+	if !rvMethod(info.FullMethod) || r.conf.Auth == nil {
+		return handler(ctx, req)
+	}
+	ctx, err := r.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if proj, ok := requestProject(req); ok {
+		if err := r.authorize(ctx, proj); err != nil {
+			if f, ok := req.(*pb.FileRequest); ok {
+				r.auditRequest(ctx, f, nil, err)
+			}
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+func (r rvServer) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	r = r.load()
+	if !rvMethod(info.FullMethod) || r.conf.Auth == nil {
+		return handler(srv, ss)
+	}
+	ctx, err := r.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authzStream{ServerStream: ss, ctx: ctx, r: r})
+}
+
+// authzStream authorizes the project of each FileChunk header, or
+// ListArchiveRequest, received.
+type authzStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	r   rvServer
+}
+
+func (s *authzStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authzStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if c, ok := m.(*pb.FileChunk); ok && c.GetHeader() != nil {
+		return s.r.authorize(s.ctx, c.GetHeader().GetProject())
+	}
+	if proj, ok := requestProject(m); ok {
+		return s.r.authorize(s.ctx, proj)
+	}
+	return nil
+}
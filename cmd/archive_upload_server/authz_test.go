@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/routeviews/google-cloud-storage/pkg/auth/authtest"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorization(t *testing.T) {
+	const aud = "https://rv-server.example.com"
+	iss := authtest.NewIssuer(t, "https://accounts.google.com")
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+
+	tests := []struct {
+		desc  string
+		token string
+		proj  pb.FileRequest_Project
+		want  codes.Code
+	}{{
+		desc:  "allowed project",
+		token: iss.Token(t, "uploader@example.com", aud),
+		proj:  pb.FileRequest_ROUTEVIEWS,
+		want:  codes.OK,
+	}, {
+		desc:  "project not allowed",
+		token: iss.Token(t, "uploader@example.com", aud),
+		proj:  pb.FileRequest_RPKI_RARC,
+		want:  codes.PermissionDenied,
+	}, {
+		desc:  "unknown caller",
+		token: iss.Token(t, "stranger@example.com", aud),
+		proj:  pb.FileRequest_ROUTEVIEWS,
+		want:  codes.PermissionDenied,
+	}, {
+		desc:  "wrong audience",
+		token: iss.Token(t, "uploader@example.com", "https://elsewhere.example.com"),
+		proj:  pb.FileRequest_ROUTEVIEWS,
+		want:  codes.Unauthenticated,
+	}, {
+		desc: "no token",
+		proj: pb.FileRequest_ROUTEVIEWS,
+		want: codes.Unauthenticated,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			srv.CreateBucket("bar")
+			fs, err := newRVServer(context.Background(), createConf(t, &config{
+				Buckets: map[string]string{
+					pb.FileRequest_ROUTEVIEWS.String(): "foo",
+					pb.FileRequest_RPKI_RARC.String():  "bar",
+				},
+				Auth: &authConfig{
+					JWKSURL:  iss.JWKSURL,
+					Audience: aud,
+					Allow: map[string][]string{
+						"uploader@example.com": {pb.FileRequest_ROUTEVIEWS.String()},
+					},
+				},
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+			client := startTestServer(t, fs, fs.interceptors()...)
+			ctx := context.Background()
+			if test.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+test.token)
+			}
+
+			_, err = client.FileUpload(ctx, &pb.FileRequest{
+				Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+				Md5Sum:   hex.EncodeToString(sum[:]),
+				Content:  content,
+				Project:  test.proj,
+			})
+			if got := status.Code(err); got != test.want {
+				t.Errorf("FileUpload() code = %v; want %v (%v)", got, test.want, err)
+			}
+
+			stream, err := client.FileUploadStream(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+				Md5Sum:   hex.EncodeToString(sum[:]),
+				Project:  test.proj,
+			}}})
+			stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: content}})
+			_, err = stream.CloseAndRecv()
+			if got := status.Code(err); got != test.want {
+				t.Errorf("FileUploadStream() code = %v; want %v (%v)", got, test.want, err)
+			}
+
+			list, err := client.ListArchive(ctx, &pb.ListArchiveRequest{Project: test.proj})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for err == nil {
+				_, err = list.Recv()
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			if got := status.Code(err); got != test.want {
+				t.Errorf("ListArchive() code = %v; want %v (%v)", got, test.want, err)
+			}
+
+			_, err = client.GetFileStatus(ctx, &pb.FileStatusRequest{
+				Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+				Project:  test.proj,
+			})
+			if got := status.Code(err); got != test.want {
+				t.Errorf("GetFileStatus() code = %v; want %v (%v)", got, test.want, err)
+			}
+			_, err = client.ListFileStatus(ctx, &pb.ListFileStatusRequest{Project: test.proj})
+			if got := status.Code(err); got != test.want {
+				t.Errorf("ListFileStatus() code = %v; want %v (%v)", got, test.want, err)
+			}
+			if test.want == codes.OK {
+				// The status of all projects is only listed without auth.
+				_, err = client.ListFileStatus(ctx, &pb.ListFileStatusRequest{})
+				if got := status.Code(err); got != codes.PermissionDenied {
+					t.Errorf("ListFileStatus() of all projects code = %v; want %v (%v)", got, codes.PermissionDenied, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizationSessions(t *testing.T) {
+	const aud = "https://rv-server.example.com"
+	iss := authtest.NewIssuer(t, "https://accounts.google.com")
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	srv.CreateBucket("bar")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Buckets: map[string]string{
+			pb.FileRequest_ROUTEVIEWS.String(): "foo",
+			pb.FileRequest_RPKI_RARC.String():  "bar",
+		},
+		Auth: &authConfig{
+			JWKSURL:  iss.JWKSURL,
+			Audience: aud,
+			Allow: map[string][]string{
+				"uploader@example.com": {pb.FileRequest_ROUTEVIEWS.String()},
+				"other@example.com":    {pb.FileRequest_ROUTEVIEWS.String()},
+				"rpki@example.com":     {pb.FileRequest_RPKI_RARC.String()},
+			},
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	client := startTestServer(t, fs, fs.interceptors()...)
+	uploader := metadata.AppendToOutgoingContext(context.Background(),
+		"authorization", "Bearer "+iss.Token(t, "uploader@example.com", aud))
+	rpki := metadata.AppendToOutgoingContext(context.Background(),
+		"authorization", "Bearer "+iss.Token(t, "rpki@example.com", aud))
+	other := metadata.AppendToOutgoingContext(context.Background(),
+		"authorization", "Bearer "+iss.Token(t, "other@example.com", aud))
+
+	s, err := client.StartUpload(uploader, &pb.StartUploadRequest{
+		Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+		Md5Sum:   "d41d8cd98f00b204e9800998ecf8427e",
+		Project:  pb.FileRequest_ROUTEVIEWS,
+	})
+	if err != nil {
+		t.Fatalf("StartUpload(): %v", err)
+	}
+	// A session may only be used by callers allowed to its project.
+	_, err = client.AppendUpload(rpki, &pb.AppendUploadRequest{SessionId: s.GetSessionId(), Content: []byte("x")})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("AppendUpload() code = %v; want %v", got, codes.PermissionDenied)
+	}
+	// Nor by other callers of the same project.
+	_, err = client.AppendUpload(other, &pb.AppendUploadRequest{SessionId: s.GetSessionId(), Content: []byte("x")})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("AppendUpload() by another caller code = %v; want %v", got, codes.PermissionDenied)
+	}
+	_, err = client.QueryUpload(other, &pb.QueryUploadRequest{SessionId: s.GetSessionId()})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("QueryUpload() by another caller code = %v; want %v", got, codes.PermissionDenied)
+	}
+	_, err = client.FinishUpload(other, &pb.FinishUploadRequest{SessionId: s.GetSessionId()})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("FinishUpload() by another caller code = %v; want %v", got, codes.PermissionDenied)
+	}
+	if _, err := client.QueryUpload(uploader, &pb.QueryUploadRequest{SessionId: s.GetSessionId()}); err != nil {
+		t.Errorf("QueryUpload(): %v", err)
+	}
+}
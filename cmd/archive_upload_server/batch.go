@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/glog"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+)
+
+// defaultBatchConcurrency is the number of files of a batch processed at
+// once, unless configured otherwise.
+const defaultBatchConcurrency = 8
+
+// FileUploadBatch handles each file of the batch as a FileUpload, up to
+// BatchConcurrency files at once. Failures are reported in the response of
+// the failed file, the call itself does not fail.
+func (r rvServer) FileUploadBatch(ctx context.Context, req *pb.FileBatchRequest) (*pb.FileBatchResponse, error) {
+	r = r.load()
+	files := req.GetFiles()
+	resps := make([]*pb.FileResponse, len(files))
+
+	n := r.conf.BatchConcurrency
+	if n < 1 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, f := range files {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			// The caller is gone, the remaining files are not processed.
+			resps[i] = &pb.FileResponse{Status: pb.FileResponse_FAIL, ErrorMessage: ctx.Err().Error()}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, f *pb.FileRequest) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			resps[i] = r.uploadBatchFile(ctx, f)
+		}(i, f)
+	}
+	wg.Wait()
+	glog.Infof("Finished processing a batch of %d files", len(files))
+	return &pb.FileBatchResponse{Responses: resps}, nil
+}
+
+// uploadBatchFile handles a file of a batch, and turns errors into a FAIL
+// response.
+func (r rvServer) uploadBatchFile(ctx context.Context, f *pb.FileRequest) *pb.FileResponse {
+	// The interceptors only authenticate batches, each file is authorized
+	// on its own.
+	err := r.authorize(ctx, f.GetProject())
+	var resp *pb.FileResponse
+	if err == nil {
+		resp, err = r.FileUpload(ctx, f)
+	} else {
+		r.auditRequest(ctx, f, nil, err)
+	}
+	if err != nil {
+		glog.Errorf("failed to upload %s of a batch: %v", f.GetFilename(), err)
+		return failResponse(err)
+	}
+	return resp
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/routeviews/google-cloud-storage/pkg/auth/authtest"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestFileUploadBatch(t *testing.T) {
+	const aud = "https://rv-server.example.com"
+	iss := authtest.NewIssuer(t, "https://accounts.google.com")
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	srv.CreateBucket("bar")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Buckets: map[string]string{
+			pb.FileRequest_ROUTEVIEWS.String(): "foo",
+			pb.FileRequest_RPKI_RARC.String():  "bar",
+		},
+		Auth: &authConfig{
+			JWKSURL:  iss.JWKSURL,
+			Audience: aud,
+			Allow: map[string][]string{
+				"uploader@example.com": {pb.FileRequest_ROUTEVIEWS.String()},
+			},
+		},
+		BatchConcurrency: 2,
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	client := startTestServer(t, fs, fs.interceptors()...)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+iss.Token(t, "uploader@example.com", aud))
+
+	file := func(i int, proj pb.FileRequest_Project) *pb.FileRequest {
+		content := []byte(fmt.Sprintf("Hello, RouteViews %d!", i))
+		sum := md5.Sum(content)
+		return &pb.FileRequest{
+			Filename: fmt.Sprintf("bgpdata/2021.11/UPDATES/updates.20211101.%04d.bz2", i),
+			Md5Sum:   hex.EncodeToString(sum[:]),
+			Content:  content,
+			Project:  proj,
+		}
+	}
+	badSum := file(1, pb.FileRequest_ROUTEVIEWS)
+	badSum.Md5Sum = "abcdef"
+	req := &pb.FileBatchRequest{Files: []*pb.FileRequest{
+		file(0, pb.FileRequest_ROUTEVIEWS),
+		badSum,
+		// The caller may not upload to RPKI_RARC.
+		file(2, pb.FileRequest_RPKI_RARC),
+		file(3, pb.FileRequest_ROUTEVIEWS),
+		{Filename: "empty", Project: pb.FileRequest_ROUTEVIEWS},
+	}}
+	resp, err := client.FileUploadBatch(ctx, req)
+	if err != nil {
+		t.Fatalf("FileUploadBatch(): %v", err)
+	}
+	var got []pb.FileResponse_Status
+	for _, r := range resp.GetResponses() {
+		got = append(got, r.GetStatus())
+		if r.GetStatus() == pb.FileResponse_FAIL && r.GetErrorMessage() == "" {
+			t.Errorf("FAIL response without an error message: %v", r)
+		}
+	}
+	want := []pb.FileResponse_Status{
+		pb.FileResponse_SUCCESS,
+		pb.FileResponse_FAIL,
+		pb.FileResponse_FAIL,
+		pb.FileResponse_SUCCESS,
+		pb.FileResponse_FAIL,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FileUploadBatch() statuses diff (-want +got):\n%s", diff)
+	}
+	for _, i := range []int{0, 3} {
+		if _, err := srv.GetObject("foo", req.Files[i].Filename); err != nil {
+			t.Errorf("file %d not stored: %v", i, err)
+		}
+	}
+	if _, err := srv.GetObject("bar", req.Files[2].Filename); err == nil {
+		t.Error("file of a denied project stored")
+	}
+
+	// Files already stored are skipped.
+	resp, err = client.FileUploadBatch(ctx, &pb.FileBatchRequest{Files: req.Files[:1]})
+	if err != nil || resp.GetResponses()[0].GetStatus() != pb.FileResponse_ALREADY_EXISTS {
+		t.Errorf("FileUploadBatch() again = %v, %v; want status %s", resp, err, pb.FileResponse_ALREADY_EXISTS)
+	}
+}
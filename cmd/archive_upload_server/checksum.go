@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksums are the checksums an upload claims for its content: the md5sum,
+// and at most one of SHA256 and CRC32C.
+type checksums struct {
+	MD5    string
+	SHA256 string
+	CRC32C *uint32
+}
+
+// newChecksums returns the checksums of an upload, from its md5sum and the
+// checksum oneof of the FileRequest, FileChunk_Header or StartUploadRequest.
+func newChecksums(md5sum string, oneof interface{}) checksums {
+	c := checksums{MD5: strings.ToLower(md5sum)}
+	var crc uint32
+	switch s := oneof.(type) {
+	case *pb.FileRequest_Sha256:
+		c.SHA256 = s.Sha256
+	case *pb.FileChunk_Header_Sha256:
+		c.SHA256 = s.Sha256
+	case *pb.StartUploadRequest_Sha256:
+		c.SHA256 = s.Sha256
+	case *pb.FileRequest_Crc32C:
+		crc = s.Crc32C
+		c.CRC32C = &crc
+	case *pb.FileChunk_Header_Crc32C:
+		crc = s.Crc32C
+		c.CRC32C = &crc
+	case *pb.StartUploadRequest_Crc32C:
+		crc = s.Crc32C
+		c.CRC32C = &crc
+	}
+	c.SHA256 = strings.ToLower(c.SHA256)
+	return c
+}
+
+// empty reports whether no checksum is claimed at all.
+func (c checksums) empty() bool {
+	return c.MD5 == "" && c.SHA256 == "" && c.CRC32C == nil
+}
+
+// matches reports whether the stored object with attrs has the claimed
+// content. The MD5 is compared if the store knows it, then the SHA-256 kept
+// in the metadata, then the CRC32C; an object without any comparable
+// checksum does not match.
+func (c checksums) matches(attrs *objstore.Attrs) bool {
+	switch {
+	case c.MD5 != "" && attrs.MD5 != nil:
+		return hex.EncodeToString(attrs.MD5) == c.MD5
+	case c.SHA256 != "" && attrs.Metadata[converter.SHA256MetadataKey] != "":
+		return attrs.Metadata[converter.SHA256MetadataKey] == c.SHA256
+	case c.CRC32C != nil && attrs.HasCRC32C:
+		return attrs.CRC32C == *c.CRC32C
+	}
+	return false
+}
+
+// withSHA256 returns c with the SHA-256 of the content of r, unless one is
+// claimed already. Uploads whose content is at hand before it is stored
+// set it, so the SHA-256 is stored in the same write as the object.
+func (c checksums) withSHA256(r io.Reader) (checksums, error) {
+	if c.SHA256 != "" {
+		return c, nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return c, err
+	}
+	c.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return c, nil
+}
+
+// hasher computes all checksums of the content written to it.
+type hasher struct {
+	md5, sha256 hash.Hash
+	crc32c      hash.Hash32
+}
+
+func newHasher() *hasher {
+	return &hasher{md5: md5.New(), sha256: sha256.New(), crc32c: crc32.New(crc32cTable)}
+}
+
+func (h *hasher) Write(p []byte) (int, error) {
+	h.md5.Write(p)
+	h.sha256.Write(p)
+	h.crc32c.Write(p)
+	return len(p), nil
+}
+
+// sha256Sum returns the hex encoded SHA-256 of the content.
+func (h *hasher) sha256Sum() string {
+	return hex.EncodeToString(h.sha256.Sum(nil))
+}
+
+// verify checks the content against each claimed checksum, and returns a
+// *checksumError on the first mismatch.
+func (h *hasher) verify(c checksums) error {
+	if calc := hex.EncodeToString(h.md5.Sum(nil)); c.MD5 != "" && calc != c.MD5 {
+		return &checksumError{algorithm: "md5", req: c.MD5, calc: calc}
+	}
+	if calc := h.sha256Sum(); c.SHA256 != "" && calc != c.SHA256 {
+		return &checksumError{algorithm: "sha256", req: c.SHA256, calc: calc}
+	}
+	if calc := h.crc32c.Sum32(); c.CRC32C != nil && calc != *c.CRC32C {
+		return &checksumError{algorithm: "crc32c", req: fmt.Sprint(*c.CRC32C), calc: fmt.Sprint(calc)}
+	}
+	return nil
+}
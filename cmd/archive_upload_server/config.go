@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/template"
+
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/auth"
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// Storage classes of stored archives, by the scheme of their bucket. Local
+// directories keep any class in the attributes of their files.
+var storageClasses = map[string][]string{
+	"gs":   {"STANDARD", "NEARLINE", "COLDLINE", "ARCHIVE"},
+	"s3":   {"STANDARD", "STANDARD_IA", "ONEZONE_IA", "INTELLIGENT_TIERING", "GLACIER", "GLACIER_IR", "DEEP_ARCHIVE", "REDUCED_REDUNDANCY"},
+	"file": nil,
+}
+
+// projectConfig are the settings of the archive of a project.
+type projectConfig struct {
+	// Bucket is where the archives are stored, as in config.Buckets.
+	Bucket string `yaml:"bucket"`
+	// PathPrefix is a text/template of the prefix of the object names of
+	// files, e.g. "{{.Collector}}/". See prefixData for its fields.
+	PathPrefix string `yaml:"path_prefix"`
+	// MaxSize is the size, in bytes, of the largest file accepted; any size
+	// if 0.
+	MaxSize int64 `yaml:"max_size"`
+	// FilenamePattern is a regexp which filenames must match, if set.
+	FilenamePattern string `yaml:"filename_pattern"`
+	// StorageClass of stored archives, the bucket's default if empty.
+	StorageClass string `yaml:"storage_class"`
+	// Validate decompresses archives and walks their MRT records before they
+	// are stored.
+	Validate bool `yaml:"validate"`
+	// Convert publishes the events of stored archives, which trigger their
+	// conversion; true if unset.
+	Convert *bool `yaml:"convert"`
+
+	prefix   *template.Template
+	filename *regexp.Regexp
+}
+
+// prefixData are the fields of PathPrefix templates.
+type prefixData struct {
+	// Project is the name of the file's project, e.g. ROUTEVIEWS.
+	Project string
+	// Collector is the RouteViews collector of the file's path, e.g.
+	// route-views2, if any.
+	Collector string
+}
+
+// compile checks the settings of the project proj, and compiles its
+// template and pattern.
+func (p *projectConfig) compile(proj string) error {
+	if p.Bucket == "" {
+		return fmt.Errorf("project %s has no bucket", proj)
+	}
+	if p.MaxSize < 0 {
+		return fmt.Errorf("bad max_size %d of project %s", p.MaxSize, proj)
+	}
+	if p.PathPrefix != "" {
+		t, err := template.New(proj).Option("missingkey=error").Parse(p.PathPrefix)
+		if err == nil {
+			// Templates naming unknown fields only fail when executed.
+			err = t.Execute(io.Discard, &prefixData{})
+		}
+		if err != nil {
+			return fmt.Errorf("bad path_prefix of project %s: %v", proj, err)
+		}
+		p.prefix = t
+	}
+	if p.FilenamePattern != "" {
+		re, err := regexp.Compile(p.FilenamePattern)
+		if err != nil {
+			return fmt.Errorf("bad filename_pattern of project %s: %v", proj, err)
+		}
+		p.filename = re
+	}
+	if p.StorageClass != "" {
+		scheme := "gs"
+		if i := strings.Index(p.Bucket, "://"); i >= 0 {
+			scheme = p.Bucket[:i]
+		}
+		known, ok := storageClasses[scheme]
+		if !ok {
+			return fmt.Errorf("project %s sets a storage_class, which %s does not support", proj, p.Bucket)
+		}
+		if known != nil && !contains(known, p.StorageClass) {
+			return fmt.Errorf("unknown storage_class %s of project %s, want one of %v", p.StorageClass, proj, known)
+		}
+	}
+	return nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// convert is whether stored archives of the project are converted.
+func (p *projectConfig) convert() bool {
+	return p.Convert == nil || *p.Convert
+}
+
+// objectName returns the name of the object storing the file fn of the
+// project proj: fn, after the prefix of PathPrefix.
+func (p *projectConfig) objectName(proj, fn string) string {
+	// The objects kept next to the archives stay at the root of the bucket.
+	if p.prefix == nil || converter.Reserved(fn) {
+		return fn
+	}
+	d := &prefixData{Project: proj}
+	d.Collector, _ = converter.RouteViewsCollectorFromPath(fn)
+	var b strings.Builder
+	if err := p.prefix.Execute(&b, d); err != nil {
+		// The template was executed when loaded, it does not fail on other
+		// data.
+		glog.Errorf("Failed to execute the path_prefix of %s for %s: %v", proj, fn, err)
+		return fn
+	}
+	return b.String() + fn
+}
+
+// prefixStore stores the files of a project whose objects are named by a
+// PathPrefix. Names given to, and returned by, the store are filenames.
+type prefixStore struct {
+	objstore.ObjectStore
+	proj string
+	p    *projectConfig
+}
+
+func (s *prefixStore) name(fn string) string {
+	return s.p.objectName(s.proj, fn)
+}
+
+// renamed returns a func which sets the name of attrs returned for the
+// file fn to fn.
+func renamed(fn string) func(*objstore.Attrs, error) (*objstore.Attrs, error) {
+	return func(a *objstore.Attrs, err error) (*objstore.Attrs, error) {
+		if err != nil {
+			return nil, err
+		}
+		a.Name = fn
+		return a, nil
+	}
+}
+
+func (s *prefixStore) Put(ctx context.Context, name string, r io.Reader, attrs *objstore.Attrs) (*objstore.Attrs, error) {
+	return renamed(name)(s.ObjectStore.Put(ctx, s.name(name), r, attrs))
+}
+
+func (s *prefixStore) Stat(ctx context.Context, name string) (*objstore.Attrs, error) {
+	return renamed(name)(s.ObjectStore.Stat(ctx, s.name(name)))
+}
+
+func (s *prefixStore) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	return s.ObjectStore.Read(ctx, s.name(name))
+}
+
+// List lists the objects under the prefix of the listed prefix, taken as a
+// filename. Prefixes which do not name the collector of a template using
+// it only list the files without a collector.
+func (s *prefixStore) List(ctx context.Context, prefix string, fn func(*objstore.Attrs) error) error {
+	p := s.name(prefix)
+	return s.ObjectStore.List(ctx, p, func(a *objstore.Attrs) error {
+		a.Name = prefix + strings.TrimPrefix(a.Name, p)
+		return fn(a)
+	})
+}
+
+func (s *prefixStore) Delete(ctx context.Context, name string) error {
+	return s.ObjectStore.Delete(ctx, s.name(name))
+}
+
+func (s *prefixStore) UpdateMetadata(ctx context.Context, name string, md map[string]string) (*objstore.Attrs, error) {
+	return renamed(name)(s.ObjectStore.UpdateMetadata(ctx, s.name(name), md))
+}
+
+func (s *prefixStore) URL(name string) string {
+	return s.ObjectStore.URL(s.name(name))
+}
+
+// serving is the state of the server set by its config. It is swapped as
+// a whole when the config is reloaded.
+type serving struct {
+	conf *config
+	// stores hold the archives of each project.
+	stores map[pb.FileRequest_Project]objstore.ObjectStore
+	// quarantineStore receives invalid archives, if set.
+	quarantineStore objstore.ObjectStore
+	// convertedStore holds the converted updates of archives, if set.
+	convertedStore objstore.ObjectStore
+	verifier       *auth.Verifier
+}
+
+// openStore opens the store at loc, and checks that it is reachable.
+func openStore(ctx context.Context, loc string, clients *objstore.Clients) (objstore.ObjectStore, error) {
+	st, err := objstore.Open(loc, clients)
+	if err == nil {
+		err = st.Check(ctx)
+	}
+	return st, err
+}
+
+// newServing opens the stores of c. With clientCerts, callers may be
+// identified by their client certificates.
+func newServing(ctx context.Context, c *config, clients *objstore.Clients, clientCerts bool) (*serving, error) {
+	s := &serving{
+		conf:   c,
+		stores: make(map[pb.FileRequest_Project]objstore.ObjectStore),
+	}
+	for proj, p := range c.Projects {
+		st, err := openStore(ctx, p.Bucket, clients)
+		if err != nil {
+			return nil, fmt.Errorf("bad bucket %s: %v", p.Bucket, err)
+		}
+		if p.prefix != nil {
+			st = &prefixStore{ObjectStore: st, proj: proj, p: p}
+		}
+		s.stores[pb.FileRequest_Project(pb.FileRequest_Project_value[proj])] = st
+	}
+	var err error
+	if c.QuarantineBucket != "" {
+		if s.quarantineStore, err = openStore(ctx, c.QuarantineBucket, clients); err != nil {
+			return nil, fmt.Errorf("bad quarantine bucket %s: %v", c.QuarantineBucket, err)
+		}
+	}
+	if c.ConvertedBucket != "" {
+		if s.convertedStore, err = openStore(ctx, c.ConvertedBucket, clients); err != nil {
+			return nil, fmt.Errorf("bad converted bucket %s: %v", c.ConvertedBucket, err)
+		}
+	}
+	if c.Auth != nil {
+		if s.verifier, err = c.Auth.newVerifier(clientCerts); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// project returns the settings of proj, empty if it has none.
+func (s *serving) project(proj pb.FileRequest_Project) *projectConfig {
+	if p, ok := s.conf.Projects[proj.String()]; ok {
+		return p
+	}
+	return &projectConfig{}
+}
+
+// record returns the metadata record of the file fn of proj, keyed by the
+// name it is stored under.
+func (s *serving) record(proj pb.FileRequest_Project, fn string) *metadata.Record {
+	return &metadata.Record{
+		Project:  proj.String(),
+		Object:   s.project(proj).objectName(proj.String(), fn),
+		Filename: fn,
+	}
+}
+
+// checkFile checks the filename, and size if known (not negative), of a
+// file uploaded to proj against the settings of proj. Names of the objects
+// the server keeps next to the archives are rejected, as they are not
+// prefixed and would pass for tombstones or audit records.
+func (s *serving) checkFile(proj pb.FileRequest_Project, fn string, size int64) error {
+	if converter.Reserved(fn) {
+		return &reservedFilenameError{proj: proj, filename: fn}
+	}
+	p := s.project(proj)
+	if p.filename != nil && !p.filename.MatchString(fn) {
+		return &filenameError{proj: proj, filename: fn, pattern: p.FilenamePattern}
+	}
+	if p.MaxSize > 0 && size > p.MaxSize {
+		return &fileTooLargeError{proj: proj, max: p.MaxSize}
+	}
+	return nil
+}
+
+// maxReader reads up to max bytes of r, and fails with a
+// *fileTooLargeError past that.
+type maxReader struct {
+	r    io.Reader
+	proj pb.FileRequest_Project
+	max  int64
+	n    int64
+	err  error
+}
+
+func (m *maxReader) Read(p []byte) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	n, err := m.r.Read(p)
+	m.n += int64(n)
+	if m.n > m.max {
+		m.err = &fileTooLargeError{proj: m.proj, max: m.max}
+		return 0, m.err
+	}
+	return n, err
+}
+
+// restartKeys are the keys of the config which are only read at startup.
+// A reload fails if they change.
+func restartKeys(c *config) map[string]interface{} {
+	return map[string]interface{}{
+		"s3":             c.S3,
+		"session_bucket": c.SessionBucket,
+		"metadata_db":    c.MetadataDB,
+		"tls":            c.TLS,
+		"audit":          c.Audit,
+		"events":         c.Events,
+	}
+}
+
+// encodeRestartKeys returns the YAML of the restart keys of c, by key.
+func encodeRestartKeys(c *config) (map[string]string, error) {
+	enc := make(map[string]string)
+	for k, v := range restartKeys(c) {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %v", k, err)
+		}
+		enc[k] = string(b)
+	}
+	return enc, nil
+}
+
+// liveConfig holds the serving state of the config file at path, which may
+// be reloaded while calls are served.
+type liveConfig struct {
+	path    string
+	clients *objstore.Clients
+	// clientCerts is whether callers may be identified by their client
+	// certificates.
+	clientCerts bool
+	// restart holds the YAML of the restart keys of the config loaded at
+	// startup.
+	restart map[string]string
+
+	// mu serializes reloads.
+	mu  sync.Mutex
+	cur atomic.Pointer[serving]
+}
+
+// reload reads the config file again and swaps in its serving state. The
+// current state is kept if the file is invalid, or changes a key only read
+// at startup.
+func (l *liveConfig) reload(ctx context.Context) (*serving, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, err := l.read(ctx)
+	if err != nil {
+		metrics.ConfigReloads.WithLabelValues("error").Inc()
+		glog.Errorf("Failed to reload config %s, keeping the current one: %v", l.path, err)
+		return nil, err
+	}
+	l.cur.Store(s)
+	metrics.ConfigReloads.WithLabelValues("ok").Inc()
+	glog.Infof("Reloaded config %s (sha256 %s)", l.path, s.conf.sha256)
+	return s, nil
+}
+
+func (l *liveConfig) read(ctx context.Context) (*serving, error) {
+	c, err := readConfigFile(l.path)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := encodeRestartKeys(c)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range enc {
+		if v != l.restart[k] {
+			return nil, fmt.Errorf("%s cannot change without a restart", k)
+		}
+	}
+	return newServing(ctx, c, l.clients, l.clientCerts)
+}
+
+// load returns r with the current serving state. Calls load it once, when
+// they start, and are served by the same config until they end.
+func (r rvServer) load() rvServer {
+	if r.live != nil {
+		r.serving = r.live.cur.Load()
+	}
+	return r
+}
+
+// reloadOnHangup reloads the config on each SIGHUP, until ctx is done.
+func (r rvServer) reloadOnHangup(ctx context.Context) {
+	hups := make(chan os.Signal, 1)
+	signal.Notify(hups, syscall.SIGHUP)
+	defer signal.Stop(hups)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hups:
+			glog.Infof("Received SIGHUP, reloading config %s", r.live.path)
+			r.live.reload(ctx)
+		}
+	}
+}
+
+// ReloadConfig reloads the config file, admins only.
+func (r rvServer) ReloadConfig(ctx context.Context, req *pb.ReloadConfigRequest) (*pb.ReloadConfigResponse, error) {
+	r = r.load()
+	caller, err := r.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	glog.Infof("%s requested to reload config %s", caller, r.live.path)
+	s, err := r.live.reload(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to reload config: %v", err)
+	}
+	resp := &pb.ReloadConfigResponse{Sha256: s.conf.sha256}
+	for proj := range s.stores {
+		resp.Projects = append(resp.Projects, proj)
+	}
+	sort.Slice(resp.Projects, func(i, j int) bool { return resp.Projects[i] < resp.Projects[j] })
+	return resp, nil
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// TestProjectSettings tests uploads against the per-project settings.
+func TestProjectSettings(t *testing.T) {
+	content := []byte("Hello, RouteViews!")
+	dir := t.TempDir()
+
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Projects: map[string]*projectConfig{
+			pb.FileRequest_ROUTEVIEWS.String(): {
+				Bucket:          "foo",
+				PathPrefix:      "{{.Collector}}/",
+				MaxSize:         int64(len(content)),
+				FilenamePattern: `^(route-views[^/]*/)?bgpdata/\d{4}\.\d{2}/UPDATES/updates\.\d{8}\.\d{4}\.bz2$`,
+			},
+			pb.FileRequest_RPKI_RARC.String(): {
+				Bucket:       "file://" + dir,
+				StorageClass: "NEARLINE",
+			},
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	client := startTestServer(t, fs)
+
+	tests := []struct {
+		desc    string
+		proj    pb.FileRequest_Project
+		fn      string
+		content []byte
+		stream  bool
+		// wantURL is the URL of the stored file, if it is stored.
+		wantURL    string
+		wantReason string
+	}{{
+		desc:    "prefixed by collector",
+		proj:    pb.FileRequest_ROUTEVIEWS,
+		fn:      "route-views.sydney/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+		content: content,
+		wantURL: "gs://foo/route-views.sydney/route-views.sydney/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+	}, {
+		desc:    "prefixed by the default collector",
+		proj:    pb.FileRequest_ROUTEVIEWS,
+		fn:      "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+		content: content,
+		stream:  true,
+		wantURL: "gs://foo/route-views2/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+	}, {
+		desc:       "filename not matching",
+		proj:       pb.FileRequest_ROUTEVIEWS,
+		fn:         "bgpdata/2021.11/RIBS/rib.20211101.0000.bz2",
+		content:    content,
+		wantReason: reasonBadFilename,
+	}, {
+		desc:       "streamed filename not matching",
+		proj:       pb.FileRequest_ROUTEVIEWS,
+		fn:         "bgpdata/2021.11/RIBS/rib.20211101.0000.bz2",
+		content:    content,
+		stream:     true,
+		wantReason: reasonBadFilename,
+	}, {
+		desc:       "too large",
+		proj:       pb.FileRequest_ROUTEVIEWS,
+		fn:         "bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+		content:    append(content, '!'),
+		wantReason: reasonFileTooLarge,
+	}, {
+		desc:       "streamed too large",
+		proj:       pb.FileRequest_ROUTEVIEWS,
+		fn:         "bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+		content:    append(content, '!'),
+		stream:     true,
+		wantReason: reasonFileTooLarge,
+	}, {
+		desc:    "any filename or size",
+		proj:    pb.FileRequest_RPKI_RARC,
+		fn:      "rpki/2021/11/01/rarc.tgz",
+		content: bytes.Repeat(content, 10),
+		wantURL: "file://" + dir + "/rpki/2021/11/01/rarc.tgz",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			sum := md5.Sum(test.content)
+			var (
+				resp *pb.FileResponse
+				err  error
+			)
+			if test.stream {
+				stream, serr := client.FileUploadStream(ctx)
+				if serr != nil {
+					t.Fatal(serr)
+				}
+				stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+					Filename: test.fn,
+					Md5Sum:   hex.EncodeToString(sum[:]),
+					Project:  test.proj,
+				}}})
+				stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: test.content}})
+				resp, err = stream.CloseAndRecv()
+			} else {
+				resp, err = client.FileUpload(ctx, &pb.FileRequest{
+					Filename: test.fn,
+					Md5Sum:   hex.EncodeToString(sum[:]),
+					Content:  test.content,
+					Project:  test.proj,
+				})
+			}
+			if test.wantReason != "" {
+				info := errorInfo(status.Convert(err))
+				if status.Code(err) != codes.InvalidArgument || info.GetReason() != test.wantReason {
+					t.Fatalf("upload = %v, %v; want code %v with reason %s", resp, err, codes.InvalidArgument, test.wantReason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("upload: %v", err)
+			}
+			if resp.GetUrl() != test.wantURL {
+				t.Errorf("stored at %s; want %s", resp.GetUrl(), test.wantURL)
+			}
+		})
+	}
+
+	// The prefix is transparent to calls naming files.
+	entries, err := client.ListArchive(context.Background(), &pb.ListArchiveRequest{Project: pb.FileRequest_ROUTEVIEWS, Prefix: "bgpdata/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for {
+		e, err := entries.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, e.GetFilename())
+	}
+	if want := "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"; len(names) != 1 || names[0] != want {
+		t.Errorf("ListArchive() = %v; want [%s]", names, want)
+	}
+
+	st, err := objstore.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := st.Stat(context.Background(), "rpki/2021/11/01/rarc.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.StorageClass != "NEARLINE" {
+		t.Errorf("stored with storage class %q; want NEARLINE", attrs.StorageClass)
+	}
+}
+
+// TestReservedFilenames tests that files named like the objects the server
+// keeps next to the archives are rejected by each upload RPC.
+func TestReservedFilenames(t *testing.T) {
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+	md5sum := hex.EncodeToString(sum[:])
+
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Projects: map[string]*projectConfig{
+			pb.FileRequest_ROUTEVIEWS.String(): {Bucket: "foo", PathPrefix: "{{.Collector}}/"},
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	client := startTestServer(t, fs)
+
+	uploads := map[string]func(ctx context.Context, fn string) error{
+		"FileUpload": func(ctx context.Context, fn string) error {
+			_, err := client.FileUpload(ctx, &pb.FileRequest{Filename: fn, Md5Sum: md5sum, Content: content, Project: pb.FileRequest_ROUTEVIEWS})
+			return err
+		},
+		"FileUploadStream": func(ctx context.Context, fn string) error {
+			stream, err := client.FileUploadStream(ctx)
+			if err != nil {
+				return err
+			}
+			stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: fn,
+				Md5Sum:   md5sum,
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}}})
+			stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: content}})
+			_, err = stream.CloseAndRecv()
+			return err
+		},
+		"StartUpload": func(ctx context.Context, fn string) error {
+			_, err := client.StartUpload(ctx, &pb.StartUploadRequest{Filename: fn, Md5Sum: md5sum, Project: pb.FileRequest_ROUTEVIEWS})
+			return err
+		},
+	}
+	for name, upload := range uploads {
+		for _, fn := range []string{
+			auditPrefix + "20211101T000000.000000000Z-1.json",
+			tombstonePrefix + "1/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			supersededPrefix + "1/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			uploadPartPrefix + "0123456789abcdef/00000000000000000000",
+		} {
+			err := upload(context.Background(), fn)
+			if info := errorInfo(status.Convert(err)); status.Code(err) != codes.InvalidArgument || info.GetReason() != reasonBadFilename {
+				t.Errorf("%s(%s) = %v; want code %v with reason %s", name, fn, err, codes.InvalidArgument, reasonBadFilename)
+			}
+		}
+	}
+	objs, _, err := srv.ListObjectsWithOptions("foo", fakestorage.ListOptions{})
+	if err != nil || len(objs) != 0 {
+		t.Errorf("stored %v, %v; want no object", objs, err)
+	}
+}
+
+// writeConf replaces the config file path with conf.
+func writeConf(t *testing.T, path string, conf *config) {
+	t.Helper()
+	raw, err := yaml.Marshal(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	srv.CreateBucket("bar")
+	auth := &authConfig{
+		Audience: "https://rv-server.example.com",
+		Admins:   []string{"admin@example.com"},
+		Allow: map[string][]string{
+			"uploader@example.com": {pb.FileRequest_ROUTEVIEWS.String(), pb.FileRequest_RPKI_RARC.String()},
+		},
+	}
+	conf := &config{
+		Buckets: map[string]string{pb.FileRequest_ROUTEVIEWS.String(): "foo"},
+		Auth:    auth,
+	}
+	path := createConf(t, conf)
+	fs, err := newRVServer(context.Background(), path, srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	admin := context.WithValue(context.Background(), callerKey{}, "admin@example.com")
+	upload := func(proj pb.FileRequest_Project, fn string) error {
+		_, err := fs.FileUpload(context.Background(), &pb.FileRequest{
+			Filename: fn,
+			Md5Sum:   hex.EncodeToString(sum[:]),
+			Content:  content,
+			Project:  proj,
+		})
+		return err
+	}
+
+	if err := upload(pb.FileRequest_RPKI_RARC, "rpki/rarc.tgz"); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("upload to an unconfigured project = %v; want code %v", err, codes.Unimplemented)
+	}
+
+	// Uploads go on while the config is reloaded.
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- upload(pb.FileRequest_ROUTEVIEWS, fmt.Sprintf("bgpdata/2021.11/UPDATES/updates.20211101.%04d.bz2", i))
+		}(i)
+	}
+	conf.Buckets[pb.FileRequest_RPKI_RARC.String()] = "bar"
+	writeConf(t, path, conf)
+	resp, err := fs.ReloadConfig(admin, &pb.ReloadConfigRequest{})
+	if err != nil {
+		t.Fatalf("ReloadConfig(): %v", err)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("upload during reload: %v", err)
+		}
+	}
+	want := []pb.FileRequest_Project{pb.FileRequest_ROUTEVIEWS, pb.FileRequest_RPKI_RARC}
+	if len(resp.GetProjects()) != 2 || resp.GetProjects()[0] != want[0] || resp.GetProjects()[1] != want[1] {
+		t.Errorf("ReloadConfig() projects = %v; want %v", resp.GetProjects(), want)
+	}
+	if len(resp.GetSha256()) != 64 {
+		t.Errorf("ReloadConfig() sha256 = %q; want a hex SHA-256", resp.GetSha256())
+	}
+	if err := upload(pb.FileRequest_RPKI_RARC, "rpki/rarc.tgz"); err != nil {
+		t.Errorf("upload to a reloaded project: %v", err)
+	}
+
+	for _, test := range []struct {
+		desc string
+		ctx  context.Context
+		data []byte
+		want codes.Code
+	}{
+		{"not an admin", context.WithValue(context.Background(), callerKey{}, "uploader@example.com"), nil, codes.PermissionDenied},
+		{"unknown key", admin, []byte("bukkits: {ROUTEVIEWS: foo}"), codes.FailedPrecondition},
+		{"unknown project", admin, []byte("buckets: {NOPE: foo}"), codes.FailedPrecondition},
+		{"restart key", admin, []byte("buckets: {ROUTEVIEWS: foo}\nmetadata_db: /tmp/meta.db"), codes.FailedPrecondition},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if test.data != nil {
+				if err := os.WriteFile(path, test.data, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := fs.ReloadConfig(test.ctx, &pb.ReloadConfigRequest{}); status.Code(err) != test.want {
+				t.Errorf("ReloadConfig() = %v; want code %v", err, test.want)
+			}
+			// The config in use is kept.
+			if err := upload(pb.FileRequest_RPKI_RARC, "rpki/rarc.tgz"); err != nil {
+				t.Errorf("upload after a failed reload: %v", err)
+			}
+		})
+	}
+}
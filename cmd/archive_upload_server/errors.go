@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the domain of the ErrorInfo details of failed uploads.
+const errorDomain = "routeviews.org"
+
+// Reasons of the ErrorInfo details, one for each class of upload failure.
+const (
+	reasonBadRequest         = "BAD_REQUEST"
+	reasonChecksumMismatch   = "CHECKSUM_MISMATCH"
+	reasonUnsupportedProject = "UNSUPPORTED_PROJECT"
+	reasonConflict           = "CONFLICT"
+	reasonInvalidArchive     = "INVALID_ARCHIVE"
+	reasonStorageFailure     = "STORAGE_FAILURE"
+	reasonBadFilename        = "BAD_FILENAME"
+	reasonFileTooLarge       = "FILE_TOO_LARGE"
+	reasonBadOffset          = "BAD_OFFSET"
+	reasonStreamFailure      = "STREAM_FAILURE"
+	reasonInternal           = "INTERNAL"
+)
+
+// The errors below implement GRPCStatus, so the RPCs return them with their
+// code and details, while the server keeps their plain message for logs
+// and metadata.
+
+// withDetails returns the status of code and msg with the ErrorInfo of
+// reason and md, followed by details.
+func withDetails(code codes.Code, msg, reason string, md map[string]string, details ...protoadapt.MessageV1) *status.Status {
+	st := status.New(code, msg)
+	details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain, Metadata: md}}, details...)
+	if ds, err := st.WithDetails(details...); err == nil {
+		return ds
+	}
+	return st
+}
+
+// requestError is a request missing required fields, or setting fields
+// it may not.
+type requestError struct {
+	msg string
+	// fields lists the missing fields.
+	fields []string
+	// desc describes the violation of fields, "required" if empty.
+	desc string
+}
+
+func (e *requestError) Error() string {
+	return e.msg
+}
+
+func (e *requestError) GRPCStatus() *status.Status {
+	desc := e.desc
+	if desc == "" {
+		desc = "required"
+	}
+	br := &errdetails.BadRequest{}
+	for _, f := range e.fields {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: f, Description: desc})
+	}
+	return withDetails(codes.InvalidArgument, e.msg, reasonBadRequest, nil, br)
+}
+
+// field is a required field of a request, and whether it is set.
+type field struct {
+	name string
+	set  bool
+}
+
+// checkRequired returns a *requestError with msg, naming the fields which
+// are not set, if any.
+func checkRequired(msg string, fields ...field) error {
+	e := &requestError{msg: msg}
+	for _, f := range fields {
+		if !f.set {
+			e.fields = append(e.fields, f.name)
+		}
+	}
+	if len(e.fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// checksumError is content which does not match a claimed checksum.
+type checksumError struct {
+	// algorithm is md5, sha256 or crc32c.
+	algorithm string
+	req, calc string
+}
+
+func (e *checksumError) Error() string {
+	switch e.algorithm {
+	case "md5":
+		return fmt.Sprintf("checksum failure req(%q) != calc(%q)", e.req, e.calc)
+	case "crc32c":
+		return fmt.Sprintf("crc32c checksum failure req(%s) != calc(%s)", e.req, e.calc)
+	}
+	return fmt.Sprintf("%s checksum failure req(%q) != calc(%q)", e.algorithm, e.req, e.calc)
+}
+
+func (e *checksumError) GRPCStatus() *status.Status {
+	return withDetails(codes.DataLoss, e.Error(), reasonChecksumMismatch, map[string]string{
+		"algorithm":  e.algorithm,
+		"requested":  e.req,
+		"calculated": e.calc,
+	})
+}
+
+// unsupportedProjectError is an upload to a project without a bucket.
+type unsupportedProjectError struct {
+	proj pb.FileRequest_Project
+}
+
+func (e *unsupportedProjectError) Error() string {
+	return fmt.Sprintf("%s is not supported", e.proj)
+}
+
+func (e *unsupportedProjectError) GRPCStatus() *status.Status {
+	return withDetails(codes.Unimplemented, e.Error(), reasonUnsupportedProject, map[string]string{"project": e.proj.String()})
+}
+
+// filenameError is an upload whose filename does not match the
+// filename_pattern of its project.
+type filenameError struct {
+	proj     pb.FileRequest_Project
+	filename string
+	pattern  string
+}
+
+func (e *filenameError) Error() string {
+	return fmt.Sprintf("filename %s does not match the pattern %s of %s", e.filename, e.pattern, e.proj)
+}
+
+func (e *filenameError) GRPCStatus() *status.Status {
+	return withDetails(codes.InvalidArgument, e.Error(), reasonBadFilename, map[string]string{
+		"project": e.proj.String(),
+		"pattern": e.pattern,
+	})
+}
+
+// reservedFilenameError is an upload named like the objects the server
+// keeps next to the archives, see converter.Reserved.
+type reservedFilenameError struct {
+	proj     pb.FileRequest_Project
+	filename string
+}
+
+func (e *reservedFilenameError) Error() string {
+	return fmt.Sprintf("filename %s of %s is reserved", e.filename, e.proj)
+}
+
+func (e *reservedFilenameError) GRPCStatus() *status.Status {
+	return withDetails(codes.InvalidArgument, e.Error(), reasonBadFilename, map[string]string{"project": e.proj.String()})
+}
+
+// fileTooLargeError is an upload over the max_size of its project.
+type fileTooLargeError struct {
+	proj pb.FileRequest_Project
+	max  int64
+}
+
+func (e *fileTooLargeError) Error() string {
+	return fmt.Sprintf("file is over the %d bytes allowed to %s", e.max, e.proj)
+}
+
+func (e *fileTooLargeError) GRPCStatus() *status.Status {
+	return withDetails(codes.InvalidArgument, e.Error(), reasonFileTooLarge, map[string]string{
+		"project":  e.proj.String(),
+		"max_size": strconv.FormatInt(e.max, 10),
+	})
+}
+
+// offsetError is content appended to an upload session at an offset other
+// than one within its committed content.
+type offsetError struct {
+	offset, committed int64
+}
+
+func (e *offsetError) Error() string {
+	if e.offset < 0 {
+		return fmt.Sprintf("bad offset %d", e.offset)
+	}
+	return fmt.Sprintf("offset %d is past the committed offset %d", e.offset, e.committed)
+}
+
+// GRPCStatus returns OUT_OF_RANGE for negative offsets, and
+// FAILED_PRECONDITION for offsets past the committed offset, which is in the
+// details: the client resumes from it.
+func (e *offsetError) GRPCStatus() *status.Status {
+	code := codes.FailedPrecondition
+	if e.offset < 0 {
+		code = codes.OutOfRange
+	}
+	return withDetails(code, e.Error(), reasonBadOffset, map[string]string{
+		"offset":           strconv.FormatInt(e.offset, 10),
+		"committed_offset": strconv.FormatInt(e.committed, 10),
+	})
+}
+
+// conflictError is an upload rejected by the conflict policy, as different
+// content is stored under its name.
+type conflictError struct {
+	url string
+	md5 []byte
+}
+
+func (e *conflictError) Error() string {
+	return fmt.Sprintf("%s already exists with a different checksum(%x)", e.url, e.md5)
+}
+
+func (e *conflictError) GRPCStatus() *status.Status {
+	return withDetails(codes.AlreadyExists, e.Error(), reasonConflict, map[string]string{"url": e.url})
+}
+
+// storageError is a failure of the object store.
+type storageError struct {
+	msg string
+	url string
+	err error
+}
+
+func newStorageError(url string, err error, format string, args ...interface{}) *storageError {
+	return &storageError{msg: fmt.Sprintf(format, args...), url: url, err: err}
+}
+
+func (e *storageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.msg, e.err)
+}
+
+func (e *storageError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns UNAVAILABLE, so callers retry, unless the call was
+// cancelled or timed out.
+func (e *storageError) GRPCStatus() *status.Status {
+	if errors.Is(e.err, context.Canceled) || errors.Is(e.err, context.DeadlineExceeded) {
+		return status.FromContextError(e.err)
+	}
+	return withDetails(codes.Unavailable, e.Error(), reasonStorageFailure, map[string]string{"url": e.url})
+}
+
+// streamError is a failure to receive the messages of a client stream.
+type streamError struct {
+	msg string
+	err error
+}
+
+func (e *streamError) Error() string {
+	return fmt.Sprintf("%s: %v", e.msg, e.err)
+}
+
+func (e *streamError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus keeps the code of the receive error, e.g. CANCELLED when the
+// client went away, and returns ABORTED, so callers retry, otherwise.
+func (e *streamError) GRPCStatus() *status.Status {
+	if errors.Is(e.err, context.Canceled) || errors.Is(e.err, context.DeadlineExceeded) {
+		return status.FromContextError(e.err)
+	}
+	code := codes.Aborted
+	if st, ok := status.FromError(e.err); ok && st.Code() != codes.Unknown {
+		code = st.Code()
+	}
+	return withDetails(code, e.Error(), reasonStreamFailure, nil)
+}
+
+// internalError is an unexpected failure of the server.
+type internalError struct {
+	msg string
+	err error
+}
+
+func (e *internalError) Error() string {
+	return fmt.Sprintf("%s: %v", e.msg, e.err)
+}
+
+func (e *internalError) Unwrap() error {
+	return e.err
+}
+
+func (e *internalError) GRPCStatus() *status.Status {
+	return withDetails(codes.Internal, e.Error(), reasonInternal, nil)
+}
+
+func (e *invalidArchiveError) GRPCStatus() *status.Status {
+	md := map[string]string{
+		"offset": strconv.FormatInt(e.Offset, 10),
+		"reason": e.Reason,
+	}
+	if e.quarantineURL != "" {
+		md["quarantine_url"] = e.quarantineURL
+	}
+	return withDetails(codes.InvalidArgument, e.Error(), reasonInvalidArchive, md)
+}
+
+// storedResponse returns the SUCCESS response of an upload stored as attrs
+// in st.
+func storedResponse(st objstore.ObjectStore, attrs *objstore.Attrs) *pb.FileResponse {
+	resp := &pb.FileResponse{
+		Status:     pb.FileResponse_SUCCESS,
+		Url:        st.URL(attrs.Name),
+		Generation: attrs.Generation,
+		Size:       attrs.Size,
+	}
+	if attrs.HasCRC32C {
+		crc := attrs.CRC32C
+		resp.Crc32C = &crc
+	}
+	return resp
+}
+
+// failResponse returns the FAIL response of an upload which failed with
+// err, used where a response is sent for each of many uploads.
+func failResponse(err error) *pb.FileResponse {
+	resp := &pb.FileResponse{Status: pb.FileResponse_FAIL, ErrorMessage: err.Error()}
+	var ia *invalidArchiveError
+	if errors.As(err, &ia) {
+		resp.InvalidArchive = &pb.FileResponse_InvalidArchive{
+			Offset:        ia.Offset,
+			Reason:        ia.Reason,
+			QuarantineUrl: ia.quarantineURL,
+		}
+	}
+	return resp
+}
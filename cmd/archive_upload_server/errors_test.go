@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestFileUploadErrors tests the codes and details of failed uploads.
+func TestFileUploadErrors(t *testing.T) {
+	content := []byte("Foo Bar Baz")
+	tests := []struct {
+		desc       string
+		policy     string
+		req        *pb.FileRequest
+		wantCode   codes.Code
+		wantReason string
+		// wantFields are the field violations of a bad request.
+		wantFields []string
+	}{{
+		desc: "missing fields",
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		},
+		wantCode:   codes.InvalidArgument,
+		wantReason: reasonBadRequest,
+		wantFields: []string{"checksum", "content"},
+	}, {
+		desc: "checksum mismatch",
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Md5Sum:   "abcdef",
+			Content:  content,
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		},
+		wantCode:   codes.DataLoss,
+		wantReason: reasonChecksumMismatch,
+	}, {
+		desc: "unsupported project",
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+			Content:  content,
+			Project:  pb.FileRequest_RPKI_RARC,
+		},
+		wantCode:   codes.Unimplemented,
+		wantReason: reasonUnsupportedProject,
+	}, {
+		desc:   "conflict",
+		policy: conflictReject,
+		req: &pb.FileRequest{
+			Filename: "stored",
+			Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+			Content:  content,
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		},
+		wantCode:   codes.AlreadyExists,
+		wantReason: reasonConflict,
+	}, {
+		desc: "storage failure",
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+			Content:  content,
+			Project:  pb.FileRequest_ROUTEVIEWS_RIB,
+		},
+		wantCode:   codes.Unavailable,
+		wantReason: reasonStorageFailure,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer([]fakestorage.Object{{
+				ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "foo", Name: "stored"},
+				Content:     []byte("old"),
+			}})
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("baz")
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets: map[string]string{
+					pb.FileRequest_ROUTEVIEWS.String():     "foo",
+					pb.FileRequest_ROUTEVIEWS_RIB.String(): "baz",
+				},
+				ConflictPolicy: test.policy,
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+			// The bucket goes away after the server checked it.
+			if err := srv.Client().Bucket("baz").Delete(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := startTestServer(t, fs).FileUpload(ctx, test.req)
+			st := status.Convert(err)
+			if st.Code() != test.wantCode {
+				t.Fatalf("FileUpload() = %v, %v; want code %s", got, err, test.wantCode)
+			}
+			if info := errorInfo(st); info.GetReason() != test.wantReason || info.GetDomain() != errorDomain {
+				t.Errorf("got ErrorInfo %v; want reason %s in %s", info, test.wantReason, errorDomain)
+			}
+			var fields []string
+			for _, d := range st.Details() {
+				if br, ok := d.(*errdetails.BadRequest); ok {
+					for _, v := range br.GetFieldViolations() {
+						fields = append(fields, v.GetField())
+					}
+				}
+			}
+			if diff := cmp.Diff(test.wantFields, fields); diff != "" {
+				t.Errorf("field violations diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
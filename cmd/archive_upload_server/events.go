@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/events"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+)
+
+const defaultEventTimeout = 10 * time.Second
+
+// eventsConfig sets where the events of stored archives are published: an
+// HTTP endpoint, or a Pub/Sub topic.
+type eventsConfig struct {
+	// URL receives each event as a JSON POST, ie: the converter, or the
+	// pubsub_forwarder in front of it.
+	URL string `yaml:"url"`
+	// Audience, if set, is the audience of an ID token sent along with the
+	// posts, ie: the URL of a Cloud Run service.
+	Audience string `yaml:"audience"`
+	// Topic is a Pub/Sub topic, as projects/PROJECT/topics/TOPIC.
+	Topic string `yaml:"topic"`
+	// Timeout bounds each publish, defaultEventTimeout if unset.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// newPublisher checks the events config, filling in defaults, and returns
+// the publisher of events.
+func (e *eventsConfig) newPublisher(ctx context.Context) (events.Publisher, error) {
+	if e.Timeout == 0 {
+		e.Timeout = defaultEventTimeout
+	}
+	return events.NewPublisher(ctx, e.URL, e.Audience, e.Topic)
+}
+
+// publishStored publishes the ArchiveStored event of an archive stored as
+// stored, if events are configured. Failures are logged: the archive is
+// stored regardless, and may be converted again with convert_all.
+func (r rvServer) publishStored(ctx context.Context, a *converter.ArchiveAttrs, stored *objstore.Attrs) {
+	p := r.project(a.Project)
+	if r.events == nil || !p.convert() {
+		return
+	}
+	ev := &events.ArchiveStored{
+		Type:       events.ArchiveStoredType,
+		Time:       time.Now().UTC(),
+		Bucket:     strings.TrimPrefix(p.Bucket, "gs://"),
+		Object:     p.objectName(a.Project.String(), stored.Name),
+		Generation: stored.Generation,
+		Project:    a.Project.String(),
+		Collector:  a.Collector,
+		MRTType:    a.MRTType,
+	}
+	timeout := defaultEventTimeout
+	if r.conf.Events != nil {
+		timeout = r.conf.Events.Timeout
+	}
+	// The event is due even if the caller goes away once the archive is
+	// stored.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	if err := r.events.Publish(ctx, ev); err != nil {
+		metrics.EventsPublished.WithLabelValues(ev.Type, "error").Inc()
+		glog.Errorf("Failed to publish the stored event of %s: %v", ev.Object, err)
+		return
+	}
+	metrics.EventsPublished.WithLabelValues(ev.Type, "ok").Inc()
+	glog.Infof("Published the stored event of %s", ev.Object)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/routeviews/google-cloud-storage/pkg/events"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+)
+
+// TestPublishStored tests that an event is posted for each stored archive.
+func TestPublishStored(t *testing.T) {
+	content := fakeArchive(t, fakeMRT(t))
+	sum := md5.Sum(content)
+	fn := "route-views.sg/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+
+	var mu sync.Mutex
+	var got []*events.ArchiveStored
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		ev, err := events.Unmarshal(body)
+		if err != nil {
+			t.Errorf("events.Unmarshal(%s): %v", body, err)
+		}
+		mu.Lock()
+		got = append(got, ev)
+		mu.Unlock()
+	}))
+	t.Cleanup(hs.Close)
+
+	ctx := context.Background()
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	fs, err := newRVServer(ctx, createConf(t, &config{
+		Buckets: map[string]string{
+			pb.FileRequest_ROUTEVIEWS.String(): "gs://foo",
+		},
+		Events: &eventsConfig{URL: hs.URL},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	client := startTestServer(t, fs)
+	req := &pb.FileRequest{
+		Filename: fn,
+		Md5Sum:   hex.EncodeToString(sum[:]),
+		Content:  content,
+		Project:  pb.FileRequest_ROUTEVIEWS,
+	}
+	resp, err := client.FileUpload(ctx, req)
+	if err != nil {
+		t.Fatalf("FileUpload(): %v", err)
+	}
+	// Unchanged content is not stored again, so no event is posted.
+	if _, err := client.FileUpload(ctx, req); err != nil {
+		t.Fatalf("FileUpload() again: %v", err)
+	}
+
+	want := []*events.ArchiveStored{{
+		Type:       events.ArchiveStoredType,
+		Bucket:     "foo",
+		Object:     fn,
+		Generation: resp.GetGeneration(),
+		Project:    pb.FileRequest_ROUTEVIEWS.String(),
+		Collector:  "route-views.sg",
+		MRTType:    converter.MRTTypeUpdates,
+	}}
+	mu.Lock()
+	defer mu.Unlock()
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(events.ArchiveStored{}, "Time")); diff != "" {
+		t.Errorf("posted events diff (-want +got):\n%s", diff)
+	}
+}
+
+// TestPublishStoredFailure tests that uploads succeed when their event
+// cannot be published.
+func TestPublishStoredFailure(t *testing.T) {
+	ctx := context.Background()
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	fs, err := newRVServer(ctx, createConf(t, &config{
+		Buckets: map[string]string{
+			pb.FileRequest_ROUTEVIEWS.String(): "foo",
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	published := 0
+	fs.events = events.Func(func(context.Context, *events.ArchiveStored) error {
+		published++
+		return errors.New("topic not found")
+	})
+
+	resp, err := fs.FileUpload(ctx, &pb.FileRequest{
+		Filename: "bar",
+		Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+		Content:  []byte("Foo Bar Baz"),
+		Project:  pb.FileRequest_ROUTEVIEWS,
+	})
+	if err != nil || resp.GetStatus() != pb.FileResponse_SUCCESS {
+		t.Errorf("FileUpload() = %v, %v; want status %s", resp, err, pb.FileResponse_SUCCESS)
+	}
+	if published != 1 {
+		t.Errorf("published %d events; want 1", published)
+	}
+}
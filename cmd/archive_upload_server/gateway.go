@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// gateway serves the RV service over plain HTTP, for collectors which
+// cannot run a gRPC client:
+//
+//	PUT /v1/{project}/{path}  stores the body as the file path, given the
+//	                          base64 MD5 of the body in Content-MD5;
+//	GET /v1/status/{project}/{path}
+//	                          returns the FileStatus of path in project;
+//	GET /v1/status            lists FileStatus, filtered by the project,
+//	                          status, prefix and limit query parameters.
+//
+// Requests run the RV methods through the same authentication and
+// authorization as gRPC calls. Responses are the JSON form of the RV
+// messages; errors are a JSON error object, as in Google APIs.
+type gateway struct {
+	r rvServer
+}
+
+// gateway returns the handler of the HTTP gateway to r.
+func (r rvServer) gateway() http.Handler {
+	g := &gateway{r: r}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/{project}/{path...}", g.upload)
+	mux.HandleFunc("GET /v1/status/{project}/{path...}", g.getStatus)
+	mux.HandleFunc("GET /v1/status", g.listStatus)
+	return mux
+}
+
+// methodStream carries the method of a gateway call, as grpc.Method
+// reports for gRPC calls.
+type methodStream struct {
+	method string
+}
+
+func (s *methodStream) Method() string               { return s.method }
+func (s *methodStream) SetHeader(metadata.MD) error  { return nil }
+func (s *methodStream) SendHeader(metadata.MD) error { return nil }
+func (s *methodStream) SetTrailer(metadata.MD) error { return nil }
+
+// remoteAddr is the address of an HTTP client.
+type remoteAddr string
+
+func (a remoteAddr) Network() string { return "tcp" }
+func (a remoteAddr) String() string  { return string(a) }
+
+// call runs handler on in as the RV method, through the auth interceptor
+// if auth is configured. The Authorization header and TLS state of req are
+// passed on as those of a gRPC call.
+func (g *gateway) call(req *http.Request, method string, in interface{}, handler grpc.UnaryHandler) (interface{}, error) {
+	fullMethod := "/" + pb.RV_ServiceDesc.ServiceName + "/" + method
+	ctx := req.Context()
+	if a := req.Header.Get("Authorization"); a != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", a))
+	}
+	p := &peer.Peer{Addr: remoteAddr(req.RemoteAddr)}
+	if req.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *req.TLS}
+	}
+	ctx = peer.NewContext(ctx, p)
+	ctx = grpc.NewContextWithServerTransportStream(ctx, &methodStream{method: fullMethod})
+	return g.r.unaryAuth(ctx, in, &grpc.UnaryServerInfo{Server: g.r, FullMethod: fullMethod}, handler)
+}
+
+func (g *gateway) upload(w http.ResponseWriter, req *http.Request) {
+	sum, err := base64.StdEncoding.DecodeString(req.Header.Get("Content-MD5"))
+	if err != nil || len(sum) != md5.Size {
+		writeError(w, &requestError{msg: "Content-MD5 must hold the base64 MD5 of the content", fields: []string{"Content-MD5"}})
+		return
+	}
+	// The request carries no content: the body is streamed to the store
+	// once the call is authorized.
+	fr := &pb.FileRequest{
+		Filename: req.PathValue("path"),
+		Md5Sum:   hex.EncodeToString(sum),
+		Project:  pb.FileRequest_Project(pb.FileRequest_Project_value[strings.ToUpper(req.PathValue("project"))]),
+	}
+	resp, err := g.call(req, "FileUpload", fr, func(ctx context.Context, in interface{}) (interface{}, error) {
+		return g.r.bodyUpload(ctx, in.(*pb.FileRequest), req.Body, req.ContentLength)
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	code := http.StatusOK
+	if resp.(*pb.FileResponse).GetStatus() == pb.FileResponse_SUCCESS {
+		code = http.StatusCreated
+	}
+	writeMessage(w, code, resp.(*pb.FileResponse))
+}
+
+func (g *gateway) getStatus(w http.ResponseWriter, req *http.Request) {
+	fr := &pb.FileStatusRequest{
+		Filename: req.PathValue("path"),
+		Project:  pb.FileRequest_Project(pb.FileRequest_Project_value[strings.ToUpper(req.PathValue("project"))]),
+	}
+	resp, err := g.call(req, "GetFileStatus", fr, func(ctx context.Context, in interface{}) (interface{}, error) {
+		return g.r.GetFileStatus(ctx, in.(*pb.FileStatusRequest))
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeMessage(w, http.StatusOK, resp.(*pb.FileStatus))
+}
+
+func (g *gateway) listStatus(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	lr := &pb.ListFileStatusRequest{Prefix: q.Get("prefix")}
+	e := &requestError{msg: "bad query parameters"}
+	if p := q.Get("project"); p != "" {
+		lr.Project = pb.FileRequest_Project(pb.FileRequest_Project_value[strings.ToUpper(p)])
+		if lr.Project == pb.FileRequest_UNKNOWN {
+			e.fields = append(e.fields, "project")
+		}
+	}
+	if s := q.Get("status"); s != "" {
+		lr.Status = pb.FileStatus_Status(pb.FileStatus_Status_value[strings.ToUpper(s)])
+		if lr.Status == pb.FileStatus_UNKNOWN {
+			e.fields = append(e.fields, "status")
+		}
+	}
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.ParseInt(l, 10, 32)
+		if err != nil || n < 0 {
+			e.fields = append(e.fields, "limit")
+		}
+		lr.Limit = int32(n)
+	}
+	if len(e.fields) > 0 {
+		writeError(w, e)
+		return
+	}
+	resp, err := g.call(req, "ListFileStatus", lr, func(ctx context.Context, in interface{}) (interface{}, error) {
+		return g.r.ListFileStatus(ctx, in.(*pb.ListFileStatusRequest))
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeMessage(w, http.StatusOK, resp.(*pb.ListFileStatusResponse))
+}
+
+// writeMessage writes the JSON form of m as the response.
+func writeMessage(w http.ResponseWriter, code int, m proto.Message) {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "failed to encode response: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+// httpError is the JSON error object of a failed request, as in Google
+// APIs.
+type httpError struct {
+	Error struct {
+		Code    int               `json:"code"`
+		Message string            `json:"message"`
+		Status  string            `json:"status"`
+		Details []json.RawMessage `json:"details,omitempty"`
+	} `json:"error"`
+}
+
+// writeError writes err, with the HTTP status of its gRPC code.
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	writeStatus(w, httpStatus(st.Code()), st)
+}
+
+// writeStatus writes st with the HTTP status httpCode.
+func writeStatus(w http.ResponseWriter, httpCode int, st *status.Status) {
+	var e httpError
+	e.Error.Code = httpCode
+	e.Error.Message = st.Message()
+	e.Error.Status = code.Code(st.Code()).String()
+	for _, d := range st.Proto().GetDetails() {
+		b, err := protojson.Marshal(d)
+		if err != nil {
+			glog.Errorf("Failed to encode error detail %v: %v", d, err)
+			continue
+		}
+		e.Error.Details = append(e.Error.Details, b)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	json.NewEncoder(w).Encode(&e)
+}
+
+// httpStatus maps gRPC codes to HTTP statuses, as Google APIs do.
+func httpStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/routeviews/google-cloud-storage/pkg/auth/authtest"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// TestGateway tests uploads and status requests over HTTP.
+func TestGateway(t *testing.T) {
+	const aud = "https://rv-server.example.com"
+	iss := authtest.NewIssuer(t, "https://accounts.google.com")
+	content := []byte("Foo Bar Baz")
+	sum := md5.Sum(content)
+	contentMD5 := base64.StdEncoding.EncodeToString(sum[:])
+	fn := "route-views2/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+
+	ctx := context.Background()
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	srv.CreateBucket("bar")
+	fs, err := newRVServer(ctx, createConf(t, &config{
+		Projects: map[string]*projectConfig{
+			pb.FileRequest_ROUTEVIEWS.String(): {Bucket: "foo", MaxSize: 1024},
+			pb.FileRequest_RPKI_RARC.String():  {Bucket: "bar"},
+		},
+		Auth: &authConfig{
+			JWKSURL:  iss.JWKSURL,
+			Audience: aud,
+			Allow: map[string][]string{
+				"uploader@example.com": {pb.FileRequest_ROUTEVIEWS.String()},
+			},
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	gw := httptest.NewServer(fs.gateway())
+	t.Cleanup(gw.Close)
+	token := iss.Token(t, "uploader@example.com", aud)
+
+	// The tests run in order, against the same server.
+	tests := []struct {
+		desc   string
+		method string
+		path   string
+		header map[string]string
+		body   []byte
+		// chunked sends the body without a Content-Length.
+		chunked bool
+		want    int
+		// wantStatus is the status field of the response: the status of a
+		// FileResponse or FileStatus, or the code of an error.
+		wantStatus string
+	}{{
+		desc:       "upload",
+		method:     http.MethodPut,
+		path:       "/v1/routeviews/" + fn,
+		header:     map[string]string{"Authorization": "Bearer " + token, "Content-MD5": contentMD5},
+		body:       content,
+		want:       http.StatusCreated,
+		wantStatus: "SUCCESS",
+	}, {
+		desc:       "upload again",
+		method:     http.MethodPut,
+		path:       "/v1/ROUTEVIEWS/" + fn,
+		header:     map[string]string{"Authorization": "Bearer " + token, "Content-MD5": contentMD5},
+		body:       content,
+		want:       http.StatusOK,
+		wantStatus: "ALREADY_EXISTS",
+	}, {
+		desc:       "no token",
+		method:     http.MethodPut,
+		path:       "/v1/ROUTEVIEWS/" + fn,
+		header:     map[string]string{"Content-MD5": contentMD5},
+		body:       content,
+		want:       http.StatusUnauthorized,
+		wantStatus: "UNAUTHENTICATED",
+	}, {
+		desc:       "project not allowed",
+		method:     http.MethodPut,
+		path:       "/v1/RPKI_RARC/" + fn,
+		header:     map[string]string{"Authorization": "Bearer " + token, "Content-MD5": contentMD5},
+		body:       content,
+		want:       http.StatusForbidden,
+		wantStatus: "PERMISSION_DENIED",
+	}, {
+		desc:       "no Content-MD5",
+		method:     http.MethodPut,
+		path:       "/v1/ROUTEVIEWS/" + fn,
+		header:     map[string]string{"Authorization": "Bearer " + token},
+		body:       content,
+		want:       http.StatusBadRequest,
+		wantStatus: "INVALID_ARGUMENT",
+	}, {
+		// The content is checked as it is stored, so the file must not
+		// be stored already.
+		desc:       "checksum mismatch",
+		method:     http.MethodPut,
+		path:       "/v1/ROUTEVIEWS/route-views3/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+		header:     map[string]string{"Authorization": "Bearer " + token, "Content-MD5": contentMD5},
+		body:       []byte("Foo Bar"),
+		want:       http.StatusInternalServerError,
+		wantStatus: "DATA_LOSS",
+	}, {
+		desc:       "over max_size",
+		method:     http.MethodPut,
+		path:       "/v1/ROUTEVIEWS/route-views3/bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+		header:     map[string]string{"Authorization": "Bearer " + token, "Content-MD5": contentMD5},
+		body:       make([]byte, 2048),
+		want:       http.StatusBadRequest,
+		wantStatus: "INVALID_ARGUMENT",
+	}, {
+		desc:       "over max_size, streamed",
+		method:     http.MethodPut,
+		path:       "/v1/ROUTEVIEWS/route-views3/bgpdata/2021.11/UPDATES/updates.20211101.0030.bz2",
+		header:     map[string]string{"Authorization": "Bearer " + token, "Content-MD5": contentMD5},
+		body:       make([]byte, 2048),
+		chunked:    true,
+		want:       http.StatusBadRequest,
+		wantStatus: "INVALID_ARGUMENT",
+	}, {
+		desc:       "reserved filename",
+		method:     http.MethodPut,
+		path:       "/v1/ROUTEVIEWS/" + auditPrefix + "20211101T000000.000000000Z-1.json",
+		header:     map[string]string{"Authorization": "Bearer " + token, "Content-MD5": contentMD5},
+		body:       content,
+		want:       http.StatusBadRequest,
+		wantStatus: "INVALID_ARGUMENT",
+	}, {
+		desc:       "file status",
+		method:     http.MethodGet,
+		path:       "/v1/status/ROUTEVIEWS/" + fn,
+		header:     map[string]string{"Authorization": "Bearer " + token},
+		want:       http.StatusOK,
+		wantStatus: "STORED",
+	}, {
+		desc:       "unknown file status",
+		method:     http.MethodGet,
+		path:       "/v1/status/ROUTEVIEWS/nope",
+		header:     map[string]string{"Authorization": "Bearer " + token},
+		want:       http.StatusNotFound,
+		wantStatus: "NOT_FOUND",
+	}, {
+		desc:   "list status",
+		method: http.MethodGet,
+		path:   "/v1/status?project=ROUTEVIEWS&status=stored",
+		header: map[string]string{"Authorization": "Bearer " + token},
+		want:   http.StatusOK,
+	}, {
+		desc:       "list status of a bad project",
+		method:     http.MethodGet,
+		path:       "/v1/status?project=NOPE",
+		header:     map[string]string{"Authorization": "Bearer " + token},
+		want:       http.StatusBadRequest,
+		wantStatus: "INVALID_ARGUMENT",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var content io.Reader = bytes.NewReader(test.body)
+			if test.chunked {
+				// Hides the length of the body from the request.
+				content = io.MultiReader(content)
+			}
+			req, err := http.NewRequest(test.method, gw.URL+test.path, content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range test.header {
+				req.Header.Set(k, v)
+			}
+			resp, err := gw.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != test.want {
+				t.Fatalf("%s %s = %s %s; want %d", test.method, test.path, resp.Status, body, test.want)
+			}
+			var got struct {
+				Status string `json:"status"`
+				Error  struct {
+					Status string `json:"status"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("bad JSON response %s: %v", body, err)
+			}
+			if got.Error.Status != "" {
+				got.Status = got.Error.Status
+			}
+			if got.Status != test.wantStatus {
+				t.Errorf("got status %q in %s; want %q", got.Status, body, test.wantStatus)
+			}
+		})
+	}
+
+	t.Run("listed", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, gw.URL+"/v1/status?project=ROUTEVIEWS&prefix=route-views2/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := gw.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list pb.ListFileStatusResponse
+		if err := protojson.Unmarshal(body, &list); err != nil {
+			t.Fatalf("bad ListFileStatusResponse %s: %v", body, err)
+		}
+		if len(list.GetFiles()) != 1 || list.GetFiles()[0].GetFilename() != fn {
+			t.Errorf("listed %v; want %s", list.GetFiles(), fn)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthTimeout bounds each bucket reachability check.
+const healthTimeout = 10 * time.Second
+
+// checkHealth sets the health of each configured project, by its name, e.g.
+// ROUTEVIEWS. A project is serving while its bucket is reachable.
+func (r rvServer) checkHealth(ctx context.Context, hs *health.Server) {
+	for proj, store := range r.stores {
+		st := healthpb.HealthCheckResponse_SERVING
+		cctx, cancel := context.WithTimeout(ctx, healthTimeout)
+		if err := store.Check(cctx); err != nil {
+			glog.Warningf("store %s of project %s is unreachable: %v", store.URL(""), proj, err)
+			st = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		cancel()
+		hs.SetServingStatus(proj.String(), st)
+	}
+}
+
+// watchHealth checks the health of each project every interval, until ctx is
+// done.
+func (r rvServer) watchHealth(ctx context.Context, hs *health.Server, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		r.load().checkHealth(ctx, hs)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// drain stops s from accepting new calls and waits up to timeout for the
+// calls in flight to finish. Calls still running after that are cancelled,
+// which aborts their object writes: nothing partial is committed.
+func drain(s *grpc.Server, hs *health.Server, timeout time.Duration) {
+	hs.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		glog.Info("Drained all calls.")
+	case <-time.After(timeout):
+		glog.Warningf("Calls still running after %v, aborting them.", timeout)
+		s.Stop()
+		<-done
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestCheckHealth(t *testing.T) {
+	ctx := context.Background()
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	srv.CreateBucket("bar")
+	fs, err := newRVServer(ctx, createConf(t, &config{
+		Buckets: map[string]string{
+			pb.FileRequest_ROUTEVIEWS.String():     "foo",
+			pb.FileRequest_ROUTEVIEWS_RIB.String(): "bar",
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	// The bucket of ROUTEVIEWS_RIB becomes unreachable after startup.
+	if err := srv.Client().Bucket("bar").Delete(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	hs := health.NewServer()
+	fs.checkHealth(ctx, hs)
+	for _, test := range []struct {
+		service string
+		want    healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{"", healthpb.HealthCheckResponse_SERVING},
+		{pb.FileRequest_ROUTEVIEWS.String(), healthpb.HealthCheckResponse_SERVING},
+		{pb.FileRequest_ROUTEVIEWS_RIB.String(), healthpb.HealthCheckResponse_NOT_SERVING},
+	} {
+		resp, err := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: test.service})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", test.service, err)
+		}
+		if resp.GetStatus() != test.want {
+			t.Errorf("Check(%q) = %v; want %v", test.service, resp.GetStatus(), test.want)
+		}
+	}
+}
+
+func TestDrain(t *testing.T) {
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+	fn := "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+
+	tests := []struct {
+		desc string
+		// finish is true if the upload completes within the drain timeout.
+		finish     bool
+		wantStored bool
+	}{{
+		desc:       "upload in flight finishes",
+		finish:     true,
+		wantStored: true,
+	}, {
+		desc: "upload past the deadline is aborted",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets: map[string]string{
+					pb.FileRequest_ROUTEVIEWS.String(): "foo",
+				},
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+
+			lis := bufconn.Listen(1024 * 1024)
+			s := grpc.NewServer()
+			pb.RegisterRVServer(s, fs)
+			hs := health.NewServer()
+			healthpb.RegisterHealthServer(s, hs)
+			go s.Serve(lis)
+			conn, err := grpc.Dial("bufnet",
+				grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+					return lis.Dial()
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { conn.Close() })
+
+			stream, err := pb.NewRVClient(conn).FileUploadStream(ctx)
+			if err != nil {
+				t.Fatalf("FileUploadStream(): %v", err)
+			}
+			if err := stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: fn,
+				Md5Sum:   hex.EncodeToString(sum[:]),
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: content[:5]}}); err != nil {
+				t.Fatal(err)
+			}
+
+			drained := make(chan struct{})
+			go func() {
+				drain(s, hs, 500*time.Millisecond)
+				close(drained)
+			}()
+			if test.finish {
+				// Let the drain start before the upload finishes.
+				time.Sleep(50 * time.Millisecond)
+				if err := stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: content[5:]}}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := stream.CloseAndRecv(); err != nil {
+					t.Fatalf("CloseAndRecv(): %v", err)
+				}
+			}
+			<-drained
+
+			resp, err := hs.Check(ctx, &healthpb.HealthCheckRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+				t.Errorf("health after drain = %v; want NOT_SERVING", resp.GetStatus())
+			}
+			// Give an aborted handler time to return.
+			time.Sleep(50 * time.Millisecond)
+			_, err = srv.GetObject("foo", fn)
+			if stored := err == nil; stored != test.wantStored {
+				t.Errorf("archive stored = %v; want %v", stored, test.wantStored)
+			}
+		})
+	}
+}
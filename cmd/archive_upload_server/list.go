@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// archiveTimeRE matches the timestamp in archive names, ie:
+// updates.20210331.2345.bz2 or rib.20210331.2200.bz2.
+var archiveTimeRE = regexp.MustCompile(`\.(\d{8})\.(\d{4})\.`)
+
+// archiveTime returns the time of the archive attrs: the timestamp in its
+// name, or its update time if it has none.
+func archiveTime(attrs *objstore.Attrs) time.Time {
+	if m := archiveTimeRE.FindStringSubmatch(path.Base(attrs.Name)); m != nil {
+		if t, err := time.Parse("20060102.1504", m[1]+"."+m[2]); err == nil {
+			return t
+		}
+	}
+	return attrs.Updated
+}
+
+// archiveEntry converts the attributes of a stored archive to its proto form.
+func archiveEntry(attrs *objstore.Attrs, st metadata.Status) *pb.ArchiveEntry {
+	e := &pb.ArchiveEntry{
+		Filename:   attrs.Name,
+		Size:       attrs.Size,
+		Sha256:     attrs.Metadata[converter.SHA256MetadataKey],
+		Generation: attrs.Generation,
+		Status:     pb.FileStatus_Status(pb.FileStatus_Status_value[string(st)]),
+	}
+	if attrs.MD5 != nil {
+		e.Md5Sum = hex.EncodeToString(attrs.MD5)
+	}
+	if attrs.HasCRC32C {
+		crc := attrs.CRC32C
+		e.Crc32C = &crc
+	}
+	if !attrs.Updated.IsZero() {
+		e.Updated = timestamppb.New(attrs.Updated)
+	}
+	return e
+}
+
+// ListArchive streams an entry for each file stored for the project of the
+// request. Superseded generations, tombstones, audit records and the parts
+// of upload sessions are not listed.
+func (r rvServer) ListArchive(req *pb.ListArchiveRequest, stream pb.RV_ListArchiveServer) error {
+	r = r.load()
+	ctx := stream.Context()
+	st, ok := r.stores[req.GetProject()]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "no archive for project %s", req.GetProject())
+	}
+	var start, end time.Time
+	if req.GetStartTime() != nil {
+		start = req.GetStartTime().AsTime()
+	}
+	if req.GetEndTime() != nil {
+		end = req.GetEndTime().AsTime()
+	}
+
+	states, err := r.projectStates(ctx, req.GetProject(), req.GetPrefix())
+	if err != nil {
+		return err
+	}
+	err = st.List(ctx, req.GetPrefix(), func(attrs *objstore.Attrs) error {
+		if converter.Reserved(attrs.Name) {
+			return nil
+		}
+		t := archiveTime(attrs)
+		if (!start.IsZero() && t.Before(start)) || (!end.IsZero() && !t.Before(end)) {
+			return nil
+		}
+		return stream.Send(archiveEntry(attrs, states[attrs.Name]))
+	})
+	if err != nil {
+		return newStorageError(st.URL(req.GetPrefix()), err, "failed to list %s", st.URL(req.GetPrefix()))
+	}
+	return nil
+}
+
+// projectStates returns the processing state of the tracked files of proj
+// whose name starts with prefix.
+func (r rvServer) projectStates(ctx context.Context, proj pb.FileRequest_Project, prefix string) (map[string]metadata.Status, error) {
+	recs, err := r.meta.List(ctx, &metadata.Query{Project: proj.String(), Prefix: prefix})
+	if err != nil {
+		return nil, newStorageError(r.conf.MetadataDB, err, "failed to list metadata")
+	}
+	states := make(map[string]metadata.Status, len(recs))
+	for _, rec := range recs {
+		states[rec.Filename] = rec.Status
+	}
+	return states, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestListArchive(t *testing.T) {
+	ctx := context.Background()
+	srv := fakestorage.NewServer([]fakestorage.Object{{
+		// Stored without the upload server, so it is not tracked.
+		ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "foo", Name: "bgpdata/2021.11/UPDATES/updates.20211101.0030.bz2"},
+		Content:     []byte("untracked"),
+	}, {
+		ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "foo", Name: supersededPrefix + "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"},
+		Content:     []byte("old"),
+	}, {
+		// A part of an upload session in progress.
+		ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "foo", Name: uploadPartPrefix + "0123456789abcdef/00000000000000000000"},
+		Content:     []byte("part"),
+	}, {
+		// A batch of the upload audit sink.
+		ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "foo", Name: "audit/uploads/20211101T000000Z.jsonl"},
+		Content:     []byte("{}"),
+	}})
+	t.Cleanup(srv.Stop)
+	fs, err := newRVServer(ctx, createConf(t, &config{
+		Buckets: map[string]string{pb.FileRequest_ROUTEVIEWS.String(): "foo"},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	client := startTestServer(t, fs)
+
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+	for _, fn := range []string{
+		"bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+		"bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+		"bgpdata/2021.12/UPDATES/updates.20211201.0000.bz2",
+	} {
+		if _, err := client.FileUpload(ctx, &pb.FileRequest{
+			Filename: fn,
+			Md5Sum:   hex.EncodeToString(sum[:]),
+			Content:  content,
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		}); err != nil {
+			t.Fatalf("FileUpload(%s): %v", fn, err)
+		}
+	}
+
+	tests := []struct {
+		desc     string
+		req      *pb.ListArchiveRequest
+		want     []string
+		wantCode codes.Code
+	}{{
+		desc: "all files",
+		req:  &pb.ListArchiveRequest{Project: pb.FileRequest_ROUTEVIEWS},
+		want: []string{
+			"bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			"bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+			"bgpdata/2021.11/UPDATES/updates.20211101.0030.bz2",
+			"bgpdata/2021.12/UPDATES/updates.20211201.0000.bz2",
+		},
+	}, {
+		desc: "prefix",
+		req:  &pb.ListArchiveRequest{Project: pb.FileRequest_ROUTEVIEWS, Prefix: "bgpdata/2021.12/"},
+		want: []string{"bgpdata/2021.12/UPDATES/updates.20211201.0000.bz2"},
+	}, {
+		desc: "time range",
+		req: &pb.ListArchiveRequest{
+			Project:   pb.FileRequest_ROUTEVIEWS,
+			StartTime: timestamppb.New(time.Date(2021, 11, 1, 0, 15, 0, 0, time.UTC)),
+			EndTime:   timestamppb.New(time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)),
+		},
+		want: []string{
+			"bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2",
+			"bgpdata/2021.11/UPDATES/updates.20211101.0030.bz2",
+		},
+	}, {
+		desc:     "unknown project",
+		req:      &pb.ListArchiveRequest{Project: pb.FileRequest_RIPE_RIS},
+		wantCode: codes.InvalidArgument,
+	}}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			stream, err := client.ListArchive(ctx, test.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for {
+				e, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if status.Code(err) != test.wantCode {
+						t.Errorf("ListArchive() = %v; want code %v", err, test.wantCode)
+					}
+					return
+				}
+				got = append(got, e.GetFilename())
+			}
+			if test.wantCode != codes.OK {
+				t.Errorf("ListArchive() succeeded; want code %v", test.wantCode)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ListArchive() filenames diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// Entries carry the checksums and processing state of each file.
+	stream, err := client.ListArchive(ctx, &pb.ListArchiveRequest{
+		Project: pb.FileRequest_ROUTEVIEWS,
+		Prefix:  "bgpdata/2021.11/UPDATES/updates.20211101.00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracked, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracked.GetMd5Sum() != hex.EncodeToString(sum[:]) || tracked.GetSize() != int64(len(content)) ||
+		tracked.Crc32C == nil || tracked.GetGeneration() == 0 || tracked.GetStatus() != pb.FileStatus_STORED {
+		t.Errorf("ListArchive() = %v; want the md5sum, size, crc32c, generation and STORED status of the upload", tracked)
+	}
+	if tracked.GetSha256() == "" {
+		t.Errorf("ListArchive() = %v; want the sha256 recorded at upload", tracked)
+	}
+	stream.Recv()
+	untracked, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if untracked.GetStatus() != pb.FileStatus_UNKNOWN {
+		t.Errorf("ListArchive() status of untracked file = %v; want %v", untracked.GetStatus(), pb.FileStatus_UNKNOWN)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Retracted files are moved to tombstonePrefix/generation/filename, with
+	// the reason and the admin in their metadata.
+	tombstonePrefix  = converter.RetractedPrefix
+	retractReasonKey = "routingDataRetractedReason"
+	retractedByKey   = "routingDataRetractedBy"
+	retractedAtKey   = "routingDataRetractedAt"
+	// auditPrefix holds a record of each retraction, next to the archive.
+	auditPrefix = converter.AuditPrefix + "retractions/"
+)
+
+// retraction is the audit record of a retracted file.
+type retraction struct {
+	Time       time.Time `json:"time"`
+	Caller     string    `json:"caller"`
+	Project    string    `json:"project"`
+	Filename   string    `json:"filename"`
+	Reason     string    `json:"reason"`
+	Generation int64     `json:"generation"`
+	MD5        string    `json:"md5,omitempty"`
+	Tombstone  string    `json:"tombstone"`
+	Converted  string    `json:"converted,omitempty"`
+}
+
+// authorizeAdmin checks that the caller is an admin. Admin calls are denied
+// if auth is not configured, as the caller is then unknown.
+func (r rvServer) authorizeAdmin(ctx context.Context) (string, error) {
+	if r.conf.Auth == nil {
+		return "", status.Error(codes.PermissionDenied, "admin calls require auth")
+	}
+	c, ok := callerFrom(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "unknown caller")
+	}
+	for _, a := range r.conf.Auth.Admins {
+		if a == c {
+			return c, nil
+		}
+	}
+	glog.Warningf("Denied admin call to %s", c)
+	return "", status.Errorf(codes.PermissionDenied, "%s is not an admin", c)
+}
+
+// RetractFile moves a stored file under tombstonePrefix, deletes its
+// converted updates and records the retraction in the audit log.
+func (r rvServer) RetractFile(ctx context.Context, req *pb.RetractFileRequest) (*pb.RetractFileResponse, error) {
+	r = r.load()
+	caller, err := r.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetFilename() == "" || req.GetReason() == "" {
+		return nil, status.Error(codes.InvalidArgument, "base requirements for RetractFileRequest unmet")
+	}
+	if converter.Reserved(req.GetFilename()) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s is not an archive", req.GetFilename())
+	}
+	st, ok := r.stores[req.GetProject()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "no archive for project %s", req.GetProject())
+	}
+	fn := req.GetFilename()
+	attrs, err := st.Stat(ctx, fn)
+	if errors.Is(err, objstore.ErrNotExist) {
+		return nil, status.Errorf(codes.NotFound, "%s is not archived", st.URL(fn))
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get attrs of %s: %v", st.URL(fn), err)
+	}
+
+	now := time.Now().UTC()
+	rec := &retraction{
+		Time:       now,
+		Caller:     caller,
+		Project:    req.GetProject().String(),
+		Filename:   fn,
+		Reason:     req.GetReason(),
+		Generation: attrs.Generation,
+		MD5:        fmt.Sprintf("%x", attrs.MD5),
+	}
+	tomb := fmt.Sprintf("%s%d/%s", tombstonePrefix, attrs.Generation, fn)
+	if err := tombstone(ctx, st, attrs, tomb, map[string]string{
+		retractReasonKey: req.GetReason(),
+		retractedByKey:   caller,
+		retractedAtKey:   now.Format(time.RFC3339),
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to move %s to %s: %v", st.URL(fn), st.URL(tomb), err)
+	}
+	rec.Tombstone = st.URL(tomb)
+	// The converter names converted objects after the stored archive.
+	retracted := r.record(req.GetProject(), fn)
+	if r.convertedStore != nil {
+		conv := converter.ConvertedObject(retracted.Object)
+		switch err := r.convertedStore.Delete(ctx, conv); {
+		case err == nil:
+			rec.Converted = r.convertedStore.URL(conv)
+		case !errors.Is(err, objstore.ErrNotExist):
+			return nil, status.Errorf(codes.Internal, "failed to delete %s: %v", r.convertedStore.URL(conv), err)
+		}
+		for _, dir := range converter.SessionDirs {
+			obj := converter.SessionObject(retracted.Object, dir)
+			if err := r.convertedStore.Delete(ctx, obj); err != nil && !errors.Is(err, objstore.ErrNotExist) {
+				return nil, status.Errorf(codes.Internal, "failed to delete %s: %v", r.convertedStore.URL(obj), err)
+			}
+		}
+	}
+	retracted.Status = metadata.StatusRetracted
+	retracted.Error = req.GetReason()
+	metadata.Track(ctx, r.meta, retracted)
+
+	audit, err := writeAudit(ctx, st, rec)
+	if err != nil {
+		// The retraction is done, so the record is logged for the operators.
+		glog.Errorf("Failed to record retraction %+v: %v", rec, err)
+		return nil, status.Errorf(codes.Internal, "failed to record retraction: %v", err)
+	}
+	glog.Infof("%s retracted %s: %s", caller, st.URL(fn), req.GetReason())
+	return &pb.RetractFileResponse{
+		TombstoneUrl: rec.Tombstone,
+		ConvertedUrl: rec.Converted,
+		AuditUrl:     audit,
+	}, nil
+}
+
+// tombstone stores the object of attrs as tomb, with md added to its
+// metadata and without its project, then deletes it.
+func tombstone(ctx context.Context, st objstore.ObjectStore, attrs *objstore.Attrs, tomb string, md map[string]string) error {
+	if err := storeCopy(ctx, st, attrs, tomb, md); err != nil {
+		return err
+	}
+	return st.Delete(ctx, attrs.Name)
+}
+
+// writeAudit stores rec as a new object under auditPrefix, named by its time
+// so the log is ordered and records are never overwritten, and returns its
+// location.
+func writeAudit(ctx context.Context, st objstore.ObjectStore, rec *retraction) (string, error) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s%s-%d.json", auditPrefix, rec.Time.Format("20060102T150405.000000000Z"), rec.Generation)
+	if _, err := st.Put(ctx, name, bytes.NewReader(b), &objstore.Attrs{ContentType: "application/json"}); err != nil {
+		return "", err
+	}
+	return st.URL(name), nil
+}
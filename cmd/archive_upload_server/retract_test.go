@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/routeviews/google-cloud-storage/pkg/auth/authtest"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetractFile(t *testing.T) {
+	const (
+		aud = "https://rv-server.example.com"
+		fn  = "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+		// The file is stored, and converted, under the prefix of its collector.
+		obj  = "route-views2/" + fn
+		conv = "route-views2/bgpdata/2021.11/UPDATES/updates.20211101.0000.gz"
+	)
+	iss := authtest.NewIssuer(t, "https://accounts.google.com")
+	srv := fakestorage.NewServer([]fakestorage.Object{{
+		ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "converted", Name: conv},
+		Content:     []byte("converted"),
+	}})
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Projects: map[string]*projectConfig{
+			pb.FileRequest_ROUTEVIEWS.String(): {Bucket: "foo", PathPrefix: "{{.Collector}}/"},
+		},
+		ConvertedBucket: "converted",
+		Auth: &authConfig{
+			JWKSURL:  iss.JWKSURL,
+			Audience: aud,
+			Allow: map[string][]string{
+				"uploader@example.com": {pb.FileRequest_ROUTEVIEWS.String()},
+			},
+			Admins: []string{"admin@example.com"},
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	client := startTestServer(t, fs, fs.interceptors()...)
+	as := func(email string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+iss.Token(t, email, aud))
+	}
+
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+	if _, err := client.FileUpload(as("uploader@example.com"), &pb.FileRequest{
+		Filename: fn,
+		Md5Sum:   hex.EncodeToString(sum[:]),
+		Content:  content,
+		Project:  pb.FileRequest_ROUTEVIEWS,
+	}); err != nil {
+		t.Fatalf("FileUpload(): %v", err)
+	}
+	stored, err := srv.GetObject("foo", obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pb.RetractFileRequest{Project: pb.FileRequest_ROUTEVIEWS, Filename: fn, Reason: "corrupt peer table"}
+	for _, test := range []struct {
+		desc  string
+		email string
+		req   *pb.RetractFileRequest
+		want  codes.Code
+	}{
+		{"not an admin", "uploader@example.com", req, codes.PermissionDenied},
+		{"no reason", "admin@example.com", &pb.RetractFileRequest{Project: pb.FileRequest_ROUTEVIEWS, Filename: fn}, codes.InvalidArgument},
+		{"missing file", "admin@example.com", &pb.RetractFileRequest{Project: pb.FileRequest_ROUTEVIEWS, Filename: "missing", Reason: "bad"}, codes.NotFound},
+		{"tombstone", "admin@example.com", &pb.RetractFileRequest{Project: pb.FileRequest_ROUTEVIEWS, Filename: tombstonePrefix + "1/" + fn, Reason: "bad"}, codes.InvalidArgument},
+	} {
+		if _, err := client.RetractFile(as(test.email), test.req); status.Code(err) != test.want {
+			t.Errorf("%s: RetractFile() = %v; want code %v", test.desc, err, test.want)
+		}
+	}
+
+	resp, err := client.RetractFile(as("admin@example.com"), req)
+	if err != nil {
+		t.Fatalf("RetractFile(): %v", err)
+	}
+	if _, err := srv.GetObject("foo", obj); err == nil {
+		t.Errorf("%s still stored after retraction", obj)
+	}
+	if _, err := srv.GetObject("converted", conv); err == nil {
+		t.Error("converted updates still stored after retraction")
+	}
+	if resp.GetConvertedUrl() != "gs://converted/"+conv {
+		t.Errorf("RetractFile() converted url = %q", resp.GetConvertedUrl())
+	}
+
+	objs, _, err := srv.ListObjectsWithOptions("foo", fakestorage.ListOptions{Prefix: tombstonePrefix})
+	if err != nil || len(objs) != 1 {
+		t.Fatalf("tombstones = %v, %v; want a single tombstone", objs, err)
+	}
+	tomb, err := srv.GetObject("foo", objs[0].Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(tomb.Content) != string(content) || tomb.Metadata[retractReasonKey] != req.GetReason() ||
+		tomb.Metadata[retractedByKey] != "admin@example.com" || "gs://foo/"+tomb.Name != resp.GetTombstoneUrl() {
+		t.Errorf("tombstone %s with metadata %v; want content %q, reason and admin, at %s", tomb.Name, tomb.Metadata, content, resp.GetTombstoneUrl())
+	}
+	// The tombstone is not converted again.
+	if proj, ok := tomb.Metadata[converter.ProjectMetadataKey]; ok {
+		t.Errorf("tombstone has metadata %s=%s; want none", converter.ProjectMetadataKey, proj)
+	}
+
+	objs, _, err = srv.ListObjectsWithOptions("foo", fakestorage.ListOptions{Prefix: auditPrefix})
+	if err != nil || len(objs) != 1 {
+		t.Fatalf("audit records = %v, %v; want a single record", objs, err)
+	}
+	rec, err := srv.GetObject("foo", objs[0].Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got retraction
+	if err := json.Unmarshal(rec.Content, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := retraction{
+		Caller:     "admin@example.com",
+		Project:    "ROUTEVIEWS",
+		Filename:   fn,
+		Reason:     req.GetReason(),
+		Generation: stored.Generation,
+		MD5:        hex.EncodeToString(sum[:]),
+		Tombstone:  resp.GetTombstoneUrl(),
+		Converted:  resp.GetConvertedUrl(),
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(retraction{}, "Time")); diff != "" {
+		t.Errorf("audit record diff (-want +got):\n%s", diff)
+	}
+
+	st, err := client.GetFileStatus(as("uploader@example.com"), &pb.FileStatusRequest{Filename: fn, Project: pb.FileRequest_ROUTEVIEWS})
+	if err != nil || st.GetStatus() != pb.FileStatus_RETRACTED || st.GetErrorMessage() != req.GetReason() {
+		t.Errorf("GetFileStatus() = %v, %v; want %v with the reason", st, err, pb.FileStatus_RETRACTED)
+	}
+}
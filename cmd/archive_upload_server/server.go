@@ -4,13 +4,14 @@
 // BigQuery tables as well, depending upon the request.
 //
 // NOTE: Currently (12/2021) all files are stored to GCS, the conversion process
-//       subscribes to a pubsub feed of buckets which are to be converted for BigQuery.
+// subscribes to a pubsub feed of buckets which are to be converted for BigQuery.
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
-	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"flag"
@@ -18,15 +19,29 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/golang/glog"
 	log "github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/audit"
+	"github.com/routeviews/google-cloud-storage/pkg/events"
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
 	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"gopkg.in/yaml.v2"
 )
 
@@ -38,6 +53,20 @@ const (
 
 	// Set a max receive message size: 50mb
 	maxMsgSize = 512 * 1024 * 1024
+
+	// Policies for an upload whose content differs from the stored object.
+	// conflictOverwrite replaces the stored object, conflictReject fails the
+	// upload, conflictSupersede moves the stored generation under
+	// supersededPrefix before replacing it.
+	conflictOverwrite = "overwrite"
+	conflictReject    = "reject"
+	conflictSupersede = "supersede"
+
+	supersededPrefix = converter.SupersededPrefix
+
+	// quarantineReasonKey maps to the validation failure in the metadata of
+	// a quarantined object.
+	quarantineReasonKey = "routingDataInvalidReason"
 )
 
 var (
@@ -45,42 +74,315 @@ var (
 
 	configFile = flag.String("config_file", "",
 		"YAML config file for the upload server.")
-
-	// TODO(morrowc): find a method to define the TLS certificate to be used, if this will
-	//                not be done through GCLB's inbound https path.
+	metricsAddr = flag.String("metrics_addr", ":9090",
+		"Address to serve Prometheus metrics on, disabled if empty.")
+	httpAddr = flag.String("http_addr", "",
+		"Address to serve the HTTP upload gateway on, ie: :8080, disabled if empty.")
+	drainTimeout = flag.Duration("drain_timeout", 8*time.Second,
+		"Time to let uploads in flight finish on SIGTERM, before they are aborted.")
+	healthInterval = flag.Duration("health_interval", time.Minute,
+		"Interval between checks of the reachability of project buckets.")
+	expireInterval = flag.Duration("expire_interval", time.Hour,
+		"Interval between deletions of expired upload sessions and their parts.")
 )
 
 type rvServer struct {
-	conf *config
-	sc   *storage.Client
+	// serving is the state set by the config, as of the start of a call;
+	// see load.
+	*serving
+	// live holds the current serving state.
+	live     *liveConfig
+	sessions *uploadSessions
+	meta     metadata.Store
+	// audit records each upload, if set.
+	audit audit.Sink
+	// events receives the events of stored archives, if set.
+	events events.Publisher
+	// certs serve TLS, if set.
+	certs *certReloader
 	pb.UnimplementedRVServer
 }
 
-// setProjectMeta set project source in the metadata of a GCS object. The
-// object must've existed when we set metadata.
-func (r rvServer) setProjectMeta(ctx context.Context, bkt, obj string, proj pb.FileRequest_Project) error {
-	// Set metadata once the object is created.
-	if _, err := r.sc.Bucket(bkt).Object(obj).Update(ctx, storage.ObjectAttrsToUpdate{
-		Metadata: map[string]string{
-			converter.ProjectMetadataKey: proj.String(),
-		},
-	}); err != nil {
-		return fmt.Errorf("failed to set metadata '%s:%s': %v", converter.ProjectMetadataKey, proj.String(), err)
+// track records the processing state of a file in the metadata store, a
+// non-nil err marks the file as failed.
+func (r rvServer) track(ctx context.Context, fn string, proj pb.FileRequest_Project, st metadata.Status, err error) {
+	rec := r.record(proj, fn)
+	rec.Status = st
+	if err != nil {
+		rec.Status = metadata.StatusFailed
+		rec.Error = err.Error()
 	}
-	glog.Infof("Set metadata for object: %s", obj)
-	return nil
+	metadata.Track(ctx, r.meta, rec)
 }
 
-// fileStore stores a file ([]byte) to a designated bucket location (string).
-func (r rvServer) fileStore(ctx context.Context, bkt, fn string, b []byte) error {
-	// Store the file content to the destination bucket.
-	wc := r.sc.Bucket(bkt).Object(fn).NewWriter(ctx)
-	defer wc.Close()
-	if _, err := io.Copy(wc, bytes.NewReader(b)); err != nil {
-		return fmt.Errorf("failed copying content to destination: %s/%s: %v", bkt, fn, err)
+// countUpload records the outcome of an upload in the metrics.
+func countUpload(proj pb.FileRequest_Project, resp *pb.FileResponse, err error) {
+	st := pb.FileResponse_FAIL
+	if err == nil && resp != nil {
+		st = resp.GetStatus()
 	}
-	glog.Infof("Stored object to GCS: %s/%s", bkt, fn)
-	return nil
+	metrics.Uploads.WithLabelValues(proj.String(), st.String()).Inc()
+}
+
+// trackStored records a stored file along with its archive attributes, and
+// publishes its event.
+func (r rvServer) trackStored(ctx context.Context, a *converter.ArchiveAttrs, stored *objstore.Attrs) {
+	rec := r.record(a.Project, a.Filename)
+	rec.Status = metadata.StatusStored
+	rec.Collector = a.Collector
+	rec.MRTType = a.MRTType
+	rec.ContentType = a.ContentType
+	rec.ContentEncoding = a.ContentEncoding
+	metadata.Track(ctx, r.meta, rec)
+	r.publishStored(ctx, a, stored)
+}
+
+// checkExisting compares an upload against the object already stored with
+// the same name. It returns true if the stored object has the same content,
+// in which case the upload should be skipped. If the content differs the
+// configured conflict policy is applied: with conflictSupersede the attrs
+// of the stored object are returned, for fileStore to supersede it once the
+// new content is found good.
+func (r rvServer) checkExisting(ctx context.Context, st objstore.ObjectStore, fn string, sums checksums) (bool, *objstore.Attrs, error) {
+	attrs, err := st.Stat(ctx, fn)
+	if err == objstore.ErrNotExist {
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, newStorageError(st.URL(fn), err, "failed to get attrs of %s", st.URL(fn))
+	}
+	if sums.matches(attrs) {
+		return true, nil, nil
+	}
+
+	switch r.conf.ConflictPolicy {
+	case conflictReject:
+		return false, nil, &conflictError{url: st.URL(fn), md5: attrs.MD5}
+	case conflictSupersede:
+		return false, attrs, nil
+	}
+	return false, nil, nil
+}
+
+// supersede copies the stored object of attrs under supersededPrefix, and
+// returns the name of the copy.
+func supersede(ctx context.Context, st objstore.ObjectStore, attrs *objstore.Attrs) (string, error) {
+	dst := fmt.Sprintf("%s%d/%s", supersededPrefix, attrs.Generation, attrs.Name)
+	if err := storeCopy(ctx, st, attrs, dst, nil); err != nil {
+		return "", newStorageError(st.URL(attrs.Name), err, "failed to supersede %s", st.URL(attrs.Name))
+	}
+	glog.Infof("Superseded object %s to %s", st.URL(attrs.Name), st.URL(dst))
+	return dst, nil
+}
+
+// storeCopy stores a copy of the object of attrs as dst, with md added to
+// its metadata. The project is left out of the metadata, so the copy is not
+// taken for an archive to convert.
+func storeCopy(ctx context.Context, st objstore.ObjectStore, attrs *objstore.Attrs, dst string, md map[string]string) error {
+	rd, err := st.Read(ctx, attrs.Name)
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+	ca := *attrs
+	ca.Metadata = make(map[string]string, len(attrs.Metadata)+len(md))
+	for k, v := range attrs.Metadata {
+		if k != converter.ProjectMetadataKey {
+			ca.Metadata[k] = v
+		}
+	}
+	for k, v := range md {
+		ca.Metadata[k] = v
+	}
+	_, err = st.Put(ctx, dst, rd, &ca)
+	return err
+}
+
+// fileStore stores the content read from rd to a designated bucket location.
+// The archive attributes, derived from the filename and the leading content,
+// are written along with the object. The object is only committed if the
+// content matches each of sums. The SHA-256 of the content is stored in the
+// object's metadata. It is part of the same write if it is one of sums,
+// see checksums.withSHA256. Otherwise, for streamed content, it is added
+// right after. If prev is set, the stored object of prev is superseded
+// before it is replaced. The attributes of the stored object are returned
+// as well.
+func (r rvServer) fileStore(ctx context.Context, st objstore.ObjectStore, fn string, proj pb.FileRequest_Project, sums checksums, prev *objstore.Attrs, rd io.Reader) (*converter.ArchiveAttrs, *objstore.Attrs, error) {
+	br := bufio.NewReaderSize(rd, converter.SniffLen)
+	head, err := br.Peek(converter.SniffLen)
+	if err != nil && err != io.EOF {
+		return nil, nil, &streamError{msg: fmt.Sprintf("failed to read content of %s", fn), err: err}
+	}
+	if len(head) < 1 {
+		return nil, nil, &requestError{msg: fmt.Sprintf("no content to store for %s", fn), fields: []string{"content"}}
+	}
+	attrs := converter.DescribeArchive(fn, proj, head)
+	attrs.SHA256 = sums.SHA256
+	p := r.project(proj)
+	oa := &objstore.Attrs{
+		ContentType:     attrs.ContentType,
+		ContentEncoding: attrs.ContentEncoding,
+		Metadata:        attrs.Metadata(),
+		StorageClass:    p.StorageClass,
+	}
+	var content io.Reader = br
+	var mr *maxReader
+	if p.MaxSize > 0 {
+		mr = &maxReader{r: br, proj: proj, max: p.MaxSize}
+		content = mr
+	}
+
+	h := newHasher()
+	ws := []io.Writer{h}
+	var (
+		pw, qw      *io.PipeWriter
+		valid       = make(chan error, 1)
+		quarantined chan error
+	)
+	if p.Validate {
+		var pr *io.PipeReader
+		pr, pw = io.Pipe()
+		defer pw.Close()
+		go func() {
+			err := converter.ValidateArchive(pr)
+			// Drain the remaining content, so writes to the pipe never block.
+			io.Copy(ioutil.Discard, pr)
+			valid <- err
+		}()
+		ws = append(ws, pw)
+		if r.quarantineStore != nil {
+			// The content is written to quarantine as well, that write is
+			// aborted unless the content turns out to be invalid.
+			var qr *io.PipeReader
+			qr, qw = io.Pipe()
+			defer qw.CloseWithError(errNotQuarantined)
+			quarantined = make(chan error, 1)
+			// Quarantined content is kept in the bucket's storage class.
+			qa := *oa
+			qa.StorageClass = ""
+			go func() {
+				_, err := r.quarantineStore.Put(ctx, fn, qr, &qa)
+				io.Copy(ioutil.Discard, qr)
+				quarantined <- err
+			}()
+			ws = append(ws, qw)
+		}
+	} else {
+		valid <- nil
+	}
+
+	// The object is only committed once the whole content is read, its
+	// checksum matches the requested checksum and it is valid; the object it
+	// replaces is only superseded then.
+	var sumErr, validErr, supersedeErr error
+	var superseded string
+	cr := &checkedReader{r: io.TeeReader(content, io.MultiWriter(ws...)), check: func() error {
+		if pw != nil {
+			pw.Close()
+		}
+		if sumErr = h.verify(sums); sumErr != nil {
+			metrics.ChecksumFailures.WithLabelValues(proj.String()).Inc()
+			return sumErr
+		}
+		if validErr = <-valid; validErr != nil {
+			if _, ok := validErr.(*converter.ValidationError); ok && qw != nil {
+				qw.Close()
+			}
+			return validErr
+		}
+		if prev != nil {
+			superseded, supersedeErr = supersede(ctx, st, prev)
+			return supersedeErr
+		}
+		return nil
+	}}
+
+	stored, err := st.Put(ctx, fn, cr, oa)
+	switch {
+	case mr != nil && mr.err != nil:
+		return nil, nil, mr.err
+	case sumErr != nil:
+		return nil, nil, sumErr
+	case validErr != nil:
+		return nil, nil, r.quarantine(ctx, quarantined, attrs, validErr)
+	case supersedeErr != nil:
+		return nil, nil, supersedeErr
+	case err != nil:
+		// The stored object is still in place, drop its superseded copy.
+		if superseded != "" {
+			if err := st.Delete(ctx, superseded); err != nil {
+				glog.Errorf("failed to delete superseded copy %s: %v", st.URL(superseded), err)
+			}
+		}
+		return nil, nil, newStorageError(st.URL(fn), err, "failed to store %s", st.URL(fn))
+	}
+	glog.Infof("Stored object %s (%d bytes)", st.URL(fn), stored.Size)
+	if attrs.SHA256 == "" {
+		attrs.SHA256 = h.sha256Sum()
+		if _, err := st.UpdateMetadata(ctx, fn, map[string]string{converter.SHA256MetadataKey: attrs.SHA256}); err != nil {
+			glog.Errorf("failed to set the sha256 of %s: %v", st.URL(fn), err)
+		}
+	}
+	return attrs, stored, nil
+}
+
+// errNotQuarantined aborts the quarantine of valid content.
+var errNotQuarantined = errors.New("content is not quarantined")
+
+// checkedReader calls check once r is read to the end, and returns its
+// error instead of io.EOF.
+type checkedReader struct {
+	r     io.Reader
+	check func() error
+	err   error
+}
+
+func (c *checkedReader) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.r.Read(p)
+	if err == io.EOF {
+		if cerr := c.check(); cerr != nil {
+			err = cerr
+		}
+		c.err = err
+	}
+	return n, err
+}
+
+// invalidArchiveError is returned by fileStore for content which failed
+// archive validation.
+type invalidArchiveError struct {
+	*converter.ValidationError
+	// quarantineURL is where the content was quarantined, if it was.
+	quarantineURL string
+}
+
+// quarantine commits the quarantined copy of invalid content, if there is
+// one, and records the validation failure in its metadata. Quarantine
+// failures are only logged, the upload fails either way.
+func (r rvServer) quarantine(ctx context.Context, quarantined <-chan error, attrs *converter.ArchiveAttrs, err error) error {
+	verr, ok := err.(*converter.ValidationError)
+	if !ok {
+		return &internalError{msg: fmt.Sprintf("failed to validate %s", attrs.Filename), err: err}
+	}
+	ia := &invalidArchiveError{ValidationError: verr}
+	if quarantined == nil {
+		return ia
+	}
+	if err := <-quarantined; err != nil {
+		glog.Errorf("failed to quarantine %s: %v", attrs.Filename, err)
+		return ia
+	}
+	md := attrs.Metadata()
+	md[quarantineReasonKey] = verr.Error()
+	if _, err := r.quarantineStore.UpdateMetadata(ctx, attrs.Filename, md); err != nil {
+		glog.Errorf("failed to set quarantine reason of %s: %v", attrs.Filename, err)
+	}
+	ia.quarantineURL = r.quarantineStore.URL(attrs.Filename)
+	glog.Warningf("Quarantined invalid archive to %s: %v", ia.quarantineURL, verr)
+	return ia
 }
 
 // newRVServer creates and returns a proper RV object.
@@ -89,75 +391,299 @@ func newRVServer(ctx context.Context, cf string, client *storage.Client) (*rvSer
 	if err != nil {
 		return nil, err
 	}
-	// Check if each bucket exists.
-	for proj, bkt := range c.Buckets {
-		_, err := client.Bucket(bkt).Attrs(ctx)
-		if pb.FileRequest_Project_value[proj] == int32(pb.FileRequest_UNKNOWN) {
-			return nil, fmt.Errorf("bad project %s: %v", proj, err)
+	// Defaults are filled into the config below, keep the keys as given.
+	restart, err := encodeRestartKeys(c)
+	if err != nil {
+		return nil, err
+	}
+	clients := &objstore.Clients{GCS: client}
+	if c.S3 != nil {
+		if clients.S3, err = objstore.NewS3Client(c.S3.Endpoint, c.S3.Region, c.S3.Insecure); err != nil {
+			return nil, fmt.Errorf("failed to create S3 client: %v", err)
 		}
+	}
+	// Keep upload sessions in memory, unless a bucket is configured for them.
+	var store sessionStore = newMemSessionStore()
+	if c.SessionBucket != "" {
+		st, err := openStore(ctx, c.SessionBucket, clients)
 		if err != nil {
-			return nil, fmt.Errorf("bad bucket %s: %v", bkt, err)
+			return nil, fmt.Errorf("bad session bucket %s: %v", c.SessionBucket, err)
+		}
+		store = &objSessionStore{st: st}
+	}
+	var certs *certReloader
+	if c.TLS != nil {
+		if certs, err = c.TLS.newReloader(); err != nil {
+			return nil, err
+		}
+	}
+	live := &liveConfig{
+		path:        cf,
+		clients:     clients,
+		clientCerts: c.TLS != nil && c.TLS.ClientCAFile != "",
+		restart:     restart,
+	}
+	s, err := newServing(ctx, c, clients, live.clientCerts)
+	if err != nil {
+		return nil, err
+	}
+	live.cur.Store(s)
+	var sink audit.Sink
+	if c.Audit != nil {
+		if sink, err = c.Audit.newSink(ctx, clients); err != nil {
+			return nil, err
 		}
 	}
+	var pub events.Publisher
+	if c.Events != nil {
+		if pub, err = c.Events.newPublisher(ctx); err != nil {
+			return nil, err
+		}
+	}
+	meta, err := metadata.Open(c.MetadataDB, clients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metadata store: %v", err)
+	}
 	return &rvServer{
-		conf: c,
-		sc:   client,
+		serving:  s,
+		live:     live,
+		sessions: &uploadSessions{store: store},
+		meta:     meta,
+		audit:    sink,
+		events:   pub,
+		certs:    certs,
 	}, nil
 }
 
 // Store a RARC RPKI or Routeviews file to cloud storage.
-func (r rvServer) handleDataFile(ctx context.Context, req *pb.FileRequest, resp *pb.FileResponse) (*pb.FileResponse, error) {
-	if _, ok := r.conf.Buckets[req.GetProject().String()]; !ok {
-		resp.Status = pb.FileResponse_FAIL
-		return resp, fmt.Errorf("%s is not supported", req.GetProject())
+func (r rvServer) handleDataFile(ctx context.Context, req *pb.FileRequest) (*pb.FileResponse, error) {
+	st, ok := r.stores[req.GetProject()]
+	if !ok {
+		return nil, &unsupportedProjectError{proj: req.GetProject()}
+	}
+	if err := r.checkFile(req.GetProject(), req.GetFilename(), int64(len(req.GetContent()))); err != nil {
+		return nil, err
 	}
-	bkt := r.conf.Buckets[req.GetProject().String()]
 
-	if err := r.fileStore(ctx, bkt, req.GetFilename(), req.GetContent()); err != nil {
-		resp.Status = pb.FileResponse_FAIL
-		return resp, err
+	sums := newChecksums(req.GetMd5Sum(), req.GetChecksum())
+	exists, prev, err := r.checkExisting(ctx, st, req.GetFilename(), sums)
+	if err != nil {
+		return nil, err
 	}
-	if err := r.setProjectMeta(ctx, bkt, req.GetFilename(), req.GetProject()); err != nil {
-		resp.Status = pb.FileResponse_FAIL
-		return resp, err
+	if exists {
+		glog.Infof("Skipped unchanged datafile: %s", req.GetFilename())
+		return &pb.FileResponse{Status: pb.FileResponse_ALREADY_EXISTS}, nil
 	}
-	resp.Status = pb.FileResponse_SUCCESS
+
+	// Hashing the content in memory cannot fail.
+	sums, _ = sums.withSHA256(bytes.NewReader(req.GetContent()))
+	r.track(ctx, req.GetFilename(), req.GetProject(), metadata.StatusReceiving, nil)
+	attrs, stored, err := r.fileStore(ctx, st, req.GetFilename(), req.GetProject(), sums, prev, bytes.NewReader(req.GetContent()))
+	if err != nil {
+		r.track(ctx, req.GetFilename(), req.GetProject(), metadata.StatusFailed, err)
+		return nil, err
+	}
+	r.trackStored(ctx, attrs, stored)
 
 	glog.Infof("Finished processing datafile: %s", req.GetFilename())
-	return resp, nil
+	return storedResponse(st, stored), nil
 }
 
 // FileUpload collects a file and handles it according to the appropriate rules.
-//  FileRequeasts must have:
-//    filename
-//    checksum
-//    content
-//    project
+// FileRequeasts must have:
+//
+//	filename
+//	checksum
+//	content
+//	project
 //
 // If any of these is missing the requset is invalid.
 //
-func (r rvServer) FileUpload(ctx context.Context, req *pb.FileRequest) (*pb.FileResponse, error) {
-	resp := &pb.FileResponse{}
+// Failures are returned with their gRPC code and details, see errors.go.
+// On success, the response tells where and what was stored.
+func (r rvServer) FileUpload(ctx context.Context, req *pb.FileRequest) (resp *pb.FileResponse, err error) {
+	r = r.load()
+	defer func() {
+		countUpload(req.GetProject(), resp, err)
+		r.auditRequest(ctx, req, resp, err)
+	}()
 
 	fn := req.GetFilename()
 	content := req.GetContent()
 	proj := req.GetProject()
-	sum := req.GetMd5Sum()
-	if len(content) < 1 || proj == pb.FileRequest_UNKNOWN || len(fn) < 1 {
-		resp.Status = pb.FileResponse_FAIL
-		return nil, errors.New("base requirements for FileRequest unmet")
+	sums := newChecksums(req.GetMd5Sum(), req.GetChecksum())
+	metrics.BytesReceived.WithLabelValues(proj.String()).Add(float64(len(content)))
+	if err := checkRequired("base requirements for FileRequest unmet",
+		field{"filename", len(fn) > 0},
+		field{"checksum", !sums.empty()},
+		field{"content", len(content) > 0},
+		field{"project", proj != pb.FileRequest_UNKNOWN},
+	); err != nil {
+		return nil, err
 	}
 
 	// validate that content checksum matches the requseted checksum.
-	ts := md5.Sum(content)
-	tsString := hex.EncodeToString(ts[:])
-	if tsString != sum {
-		resp.Status = pb.FileResponse_FAIL
-		return nil, fmt.Errorf("checksum failure req(%q) != calc(%q)", sum, tsString)
+	h := newHasher()
+	h.Write(content)
+	if err := h.verify(sums); err != nil {
+		metrics.ChecksumFailures.WithLabelValues(proj.String()).Inc()
+		return nil, err
 	}
 
 	// Process the content based upon project requirements.
-	return r.handleDataFile(ctx, req, resp)
+	return r.handleDataFile(ctx, req)
+}
+
+// FileUploadStream collects a file sent in chunks and pipes the content
+// straight to cloud storage. The first message of the stream must be a
+// header which carries the same information as a FileRequest minus the
+// content; the checksum is calculated as content arrives and the object
+// write is aborted if the final checksum does not match.
+func (r rvServer) FileUploadStream(stream pb.RV_FileUploadStreamServer) (err error) {
+	r = r.load()
+	var (
+		proj pb.FileRequest_Project
+		fn   string
+		sums checksums
+		resp *pb.FileResponse
+		cr   = &chunkReader{stream: stream}
+	)
+	defer func() {
+		countUpload(proj, resp, err)
+		r.auditUpload(stream.Context(), proj, fn, cr.n, sums, resp, err)
+	}()
+
+	first, err := stream.Recv()
+	if status.Code(err) == codes.PermissionDenied {
+		return err
+	}
+	if err != nil {
+		return &streamError{msg: "failed to receive stream header", err: err}
+	}
+	hdr := first.GetHeader()
+	if hdr == nil {
+		return &requestError{msg: "first message of the stream must be a header", fields: []string{"header"}}
+	}
+	fn = hdr.GetFilename()
+	proj = hdr.GetProject()
+	cr.proj = proj
+	sums = newChecksums(hdr.GetMd5Sum(), hdr.GetChecksum())
+	if err := checkRequired("base requirements for FileChunk header unmet",
+		field{"header.filename", len(fn) > 0},
+		field{"header.checksum", !sums.empty()},
+		field{"header.project", proj != pb.FileRequest_UNKNOWN},
+	); err != nil {
+		return err
+	}
+	st, ok := r.stores[proj]
+	if !ok {
+		return &unsupportedProjectError{proj: proj}
+	}
+	if err := r.checkFile(proj, fn, -1); err != nil {
+		return err
+	}
+	exists, prev, err := r.checkExisting(stream.Context(), st, fn, sums)
+	if err != nil {
+		return err
+	}
+	if exists {
+		glog.Infof("Skipped unchanged streamed datafile: %s", fn)
+		resp = &pb.FileResponse{Status: pb.FileResponse_ALREADY_EXISTS}
+		return stream.SendAndClose(resp)
+	}
+
+	r.track(stream.Context(), fn, proj, metadata.StatusReceiving, nil)
+	attrs, stored, err := r.fileStore(stream.Context(), st, fn, proj, sums, prev, cr)
+	if err != nil {
+		r.track(stream.Context(), fn, proj, metadata.StatusFailed, err)
+		return err
+	}
+	r.trackStored(stream.Context(), attrs, stored)
+	glog.Infof("Finished processing streamed datafile: %s", fn)
+	resp = storedResponse(st, stored)
+	return stream.SendAndClose(resp)
+}
+
+// bodyUpload stores the content of body as the file of req, as
+// FileUploadStream does: the content is piped straight to the store and
+// checked as it arrives. The size of body is -1 if unknown; bodies are cut
+// at maxMsgSize, the limit of FileUpload.
+func (r rvServer) bodyUpload(ctx context.Context, req *pb.FileRequest, body io.Reader, size int64) (resp *pb.FileResponse, err error) {
+	r = r.load()
+	fn := req.GetFilename()
+	proj := req.GetProject()
+	sums := newChecksums(req.GetMd5Sum(), req.GetChecksum())
+	mr := &maxReader{r: body, proj: proj, max: maxMsgSize}
+	defer func() {
+		metrics.BytesReceived.WithLabelValues(proj.String()).Add(float64(mr.n))
+		countUpload(proj, resp, err)
+		r.auditUpload(ctx, proj, fn, mr.n, sums, resp, err)
+	}()
+
+	if err := checkRequired("base requirements for FileRequest unmet",
+		field{"filename", len(fn) > 0},
+		field{"checksum", !sums.empty()},
+		field{"content", size != 0},
+		field{"project", proj != pb.FileRequest_UNKNOWN},
+	); err != nil {
+		return nil, err
+	}
+	st, ok := r.stores[proj]
+	if !ok {
+		return nil, &unsupportedProjectError{proj: proj}
+	}
+	if err := r.checkFile(proj, fn, size); err != nil {
+		return nil, err
+	}
+	exists, prev, err := r.checkExisting(ctx, st, fn, sums)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		glog.Infof("Skipped unchanged datafile: %s", fn)
+		return &pb.FileResponse{Status: pb.FileResponse_ALREADY_EXISTS}, nil
+	}
+
+	r.track(ctx, fn, proj, metadata.StatusReceiving, nil)
+	attrs, stored, err := r.fileStore(ctx, st, fn, proj, sums, prev, mr)
+	if err != nil {
+		r.track(ctx, fn, proj, metadata.StatusFailed, err)
+		return nil, err
+	}
+	r.trackStored(ctx, attrs, stored)
+	glog.Infof("Finished processing datafile: %s", fn)
+	return storedResponse(st, stored), nil
+}
+
+// chunkReader reads the file content of a FileUploadStream which follows
+// the header.
+type chunkReader struct {
+	stream pb.RV_FileUploadStreamServer
+	proj   pb.FileRequest_Project
+	buf    []byte
+	// n is the number of content bytes received.
+	n int64
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		chunk, err := c.stream.Recv()
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, &streamError{msg: "failed to receive content", err: err}
+		}
+		if chunk.GetHeader() != nil {
+			return 0, &requestError{msg: "unexpected header in the middle of the stream", fields: []string{"header"}, desc: "only allowed in the first message"}
+		}
+		c.buf = chunk.GetContent()
+		c.n += int64(len(c.buf))
+		metrics.BytesReceived.WithLabelValues(c.proj.String()).Add(float64(len(c.buf)))
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
 }
 
 func readConfigFile(path string) (*config, error) {
@@ -171,15 +697,139 @@ func readConfigFile(path string) (*config, error) {
 	}
 	glog.Info(string(bktConf))
 	c := &config{}
-	err = yaml.Unmarshal(bktConf, &c)
+	// Unknown keys are errors, rather than settings silently ignored.
+	err = yaml.UnmarshalStrict(bktConf, &c)
 	if err != nil {
 		return nil, fmt.Errorf("yaml: %v", err)
 	}
+	sum := sha256.Sum256(bktConf)
+	c.sha256 = hex.EncodeToString(sum[:])
+	if err := c.mergeProjects(); err != nil {
+		return nil, err
+	}
+	switch c.ConflictPolicy {
+	case "":
+		c.ConflictPolicy = conflictOverwrite
+	case conflictOverwrite, conflictReject, conflictSupersede:
+	default:
+		return nil, fmt.Errorf("unknown conflict_policy %q", c.ConflictPolicy)
+	}
+	switch {
+	case c.SessionTTL == 0:
+		c.SessionTTL = defaultSessionTTL
+	case c.SessionTTL < 0:
+		return nil, fmt.Errorf("bad session_ttl %v", c.SessionTTL)
+	}
+	switch {
+	case c.BatchConcurrency == 0:
+		c.BatchConcurrency = defaultBatchConcurrency
+	case c.BatchConcurrency < 0:
+		return nil, fmt.Errorf("bad batch_concurrency %d", c.BatchConcurrency)
+	}
 	return c, nil
 }
 
+// mergeProjects merges the project settings of Buckets and Validate into
+// Projects, and checks them.
+func (c *config) mergeProjects() error {
+	if c.Projects == nil {
+		c.Projects = make(map[string]*projectConfig)
+	}
+	for proj, bkt := range c.Buckets {
+		p, ok := c.Projects[proj]
+		if !ok {
+			p = &projectConfig{}
+			c.Projects[proj] = p
+		}
+		if p.Bucket != "" {
+			return fmt.Errorf("project %s has a bucket in both buckets and projects", proj)
+		}
+		p.Bucket = bkt
+	}
+	for proj, v := range c.Validate {
+		p, ok := c.Projects[proj]
+		if !ok {
+			return fmt.Errorf("bad project %s to validate, it has no bucket", proj)
+		}
+		p.Validate = p.Validate || v
+	}
+	for proj, p := range c.Projects {
+		if pb.FileRequest_Project_value[proj] == int32(pb.FileRequest_UNKNOWN) {
+			return fmt.Errorf("bad project %s", proj)
+		}
+		if p == nil {
+			return fmt.Errorf("project %s has no settings", proj)
+		}
+		if err := p.compile(proj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type config struct {
+	// Buckets maps project names to where their archives are stored: a GCS
+	// bucket name, gs://bucket, s3://bucket or file:///directory. Other
+	// buckets in the config are given the same way. It is a shorthand for
+	// the buckets of Projects.
 	Buckets map[string]string
+	// Projects maps project names to the settings of their archives.
+	Projects map[string]*projectConfig `yaml:"projects"`
+	// S3 is the endpoint of s3:// buckets.
+	S3 *s3Config `yaml:"s3"`
+	// SessionBucket stores the state of resumable upload sessions. If empty,
+	// sessions are kept in memory and lost when the server restarts.
+	SessionBucket string `yaml:"session_bucket"`
+	// SessionTTL is how long upload sessions last, defaultSessionTTL if
+	// unset. Expired sessions and their parts are deleted.
+	SessionTTL time.Duration `yaml:"session_ttl"`
+	// ConflictPolicy applies when a file is uploaded with content that
+	// differs from the stored object: overwrite (default), reject or
+	// supersede.
+	ConflictPolicy string `yaml:"conflict_policy"`
+	// MetadataDB is the store which tracks the processing state of files, see
+	// metadata.Open. If empty, metadata is kept in memory.
+	MetadataDB string `yaml:"metadata_db"`
+	// Validate lists, by project name, whether archives are fully
+	// decompressed and their MRT records walked before they are stored. It
+	// is a shorthand for the validate settings of Projects.
+	Validate map[string]bool `yaml:"validate"`
+	// QuarantineBucket receives archives which fail validation, with the
+	// reason in their metadata. If empty, invalid archives are dropped.
+	QuarantineBucket string `yaml:"quarantine_bucket"`
+	// ConvertedBucket is the destination of the converter. The converted
+	// updates of retracted files are deleted from it, if set.
+	ConvertedBucket string `yaml:"converted_bucket"`
+	// TLS sets the certificate of the server, and of its clients for mTLS.
+	// If nil, the server runs in plaintext behind a load balancer which
+	// terminates TLS.
+	TLS *tlsConfig `yaml:"tls"`
+	// Auth enables the verification of callers' ID tokens, and limits each
+	// caller to the projects it may upload to. If nil, any caller may upload
+	// to any project.
+	Auth *authConfig `yaml:"auth"`
+	// Audit sets where the audit records of uploads go. If nil, uploads are
+	// not audited.
+	Audit *auditConfig `yaml:"audit"`
+	// Events sets where the events of stored archives are published, to
+	// trigger their conversion. If nil, stored archives are not converted.
+	Events *eventsConfig `yaml:"events"`
+	// BatchConcurrency is the number of files of a FileUploadBatch call
+	// processed at once, defaultBatchConcurrency if unset.
+	BatchConcurrency int `yaml:"batch_concurrency"`
+
+	// sha256 is the hex encoded SHA-256 of the config file.
+	sha256 string
+}
+
+type s3Config struct {
+	// Endpoint is the host[:port] of the S3 compatible service, e.g.
+	// s3.amazonaws.com. Credentials are read from the AWS_* environment
+	// variables.
+	Endpoint string `yaml:"endpoint"`
+	Region   string `yaml:"region"`
+	// Insecure disables TLS.
+	Insecure bool `yaml:"insecure"`
 }
 
 func main() {
@@ -209,17 +859,95 @@ func main() {
 		log.Fatalf("failed to create new rvServer: %v", err)
 	}
 
-	s := grpc.NewServer(
+	if *metricsAddr != "" {
+		go func() {
+			if err := metrics.ListenAndServe(*metricsAddr); err != nil {
+				log.Errorf("failed to serve metrics: %v", err)
+			}
+		}()
+	}
+
+	opts := append([]grpc.ServerOption{
 		grpc.MaxMsgSize(maxMsgSize),
 		grpc.MaxRecvMsgSize(maxMsgSize),
 		grpc.MaxSendMsgSize(maxMsgSize),
-	)
+	}, r.interceptors()...)
+	if r.certs != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(r.certs.config("h2"))))
+	}
+	s := grpc.NewServer(opts...)
 	pb.RegisterRVServer(s, r)
 
+	// Report the health of each project, and of the whole server as "".
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+	hctx, stopHealth := context.WithCancel(ctx)
+	go r.watchHealth(hctx, hs, *healthInterval)
+	go r.reloadOnHangup(hctx)
+	go r.expireUploadsEvery(hctx, *expireInterval)
+
+	var gw *http.Server
+	if *httpAddr != "" {
+		gw = &http.Server{Addr: *httpAddr, Handler: r.gateway()}
+		go func() {
+			log.Infof("HTTP gateway will listen on %s", *httpAddr)
+			var err error
+			if r.certs != nil {
+				gw.TLSConfig = r.certs.config("h2", "http/1.1")
+				err = gw.ListenAndServeTLS("", "")
+			} else {
+				err = gw.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				log.Errorf("failed to serve the HTTP gateway: %v", err)
+			}
+		}()
+	}
+
+	// On SIGTERM, drain the uploads in flight before exiting.
+	drained := make(chan struct{})
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+		sig := <-sigs
+		log.Infof("Received %v, draining for up to %v", sig, *drainTimeout)
+		stopHealth()
+		gwDone := make(chan struct{})
+		go func() {
+			defer close(gwDone)
+			if gw == nil {
+				return
+			}
+			sctx, cancel := context.WithTimeout(ctx, *drainTimeout)
+			defer cancel()
+			if err := gw.Shutdown(sctx); err != nil {
+				log.Warningf("HTTP requests still running after %v, aborting them.", *drainTimeout)
+				gw.Close()
+			}
+		}()
+		drain(s, hs, *drainTimeout)
+		<-gwDone
+		close(drained)
+	}()
+
 	// Register the reflection service on gRPC server.
 	reflection.Register(s)
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to listen&&serve: %v", err)
 	}
-
+	<-drained
+	if err := r.meta.Close(); err != nil {
+		log.Errorf("failed to close metadata store: %v", err)
+	}
+	if r.audit != nil {
+		if err := r.audit.Close(); err != nil {
+			log.Errorf("failed to close audit log: %v", err)
+		}
+	}
+	if r.events != nil {
+		if err := r.events.Close(); err != nil {
+			log.Errorf("failed to close event publisher: %v", err)
+		}
+	}
+	log.Flush()
 }
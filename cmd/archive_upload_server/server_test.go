@@ -1,14 +1,35 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
 	"io/ioutil"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/dsnet/compress/bzip2"
 	"github.com/fsouza/fake-gcs-server/fakestorage"
 	"github.com/google/go-cmp/cmp"
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
 	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
 	"gopkg.in/yaml.v2"
 )
@@ -74,8 +95,10 @@ func TestFileUpload(t *testing.T) {
 			Project:  pb.FileRequest_RPKI_RARC,
 		},
 		want: &pb.FileResponse{
-			Status:       pb.FileResponse_SUCCESS,
-			ErrorMessage: "",
+			Status: pb.FileResponse_SUCCESS,
+			Url:    "gs://foo/bar",
+			Size:   11,
+			Crc32C: proto.Uint32(crc32.Checksum([]byte("Foo Bar Baz"), crc32.MakeTable(crc32.Castagnoli))),
 		},
 	}, {
 		desc: "Routeviews: Success",
@@ -92,8 +115,10 @@ func TestFileUpload(t *testing.T) {
 			Project:  pb.FileRequest_ROUTEVIEWS,
 		},
 		want: &pb.FileResponse{
-			Status:       pb.FileResponse_SUCCESS,
-			ErrorMessage: "",
+			Status: pb.FileResponse_SUCCESS,
+			Url:    "gs://foo/bar",
+			Size:   11,
+			Crc32C: proto.Uint32(crc32.Checksum([]byte("Foo Bar Baz"), crc32.MakeTable(crc32.Castagnoli))),
 		},
 	}, {
 		desc: "Routeviews: sent to RV RIB bucket",
@@ -110,8 +135,10 @@ func TestFileUpload(t *testing.T) {
 			Project:  pb.FileRequest_ROUTEVIEWS_RIB,
 		},
 		want: &pb.FileResponse{
-			Status:       pb.FileResponse_SUCCESS,
-			ErrorMessage: "",
+			Status: pb.FileResponse_SUCCESS,
+			Url:    "gs://baz/bar",
+			Size:   11,
+			Crc32C: proto.Uint32(crc32.Checksum([]byte("Foo Bar Baz"), crc32.MakeTable(crc32.Castagnoli))),
 		},
 	}}
 
@@ -133,14 +160,14 @@ func TestFileUpload(t *testing.T) {
 		case err == nil && test.wantErr:
 			t.Errorf("[%v]: did not get error when expoecting one", test.desc)
 		case err == nil:
-			if diff := cmp.Diff(got, test.want, protocmp.Transform()); diff != "" {
+			if diff := cmp.Diff(got, test.want, protocmp.Transform(), protocmp.IgnoreFields(&pb.FileResponse{}, "generation")); diff != "" {
 				t.Errorf("[%v] got/want mismatch:\n%v\n", test.desc, diff)
 			}
 		}
 
 		// Check validity of uploaded files.
 		if test.wantErr {
-			return
+			continue
 		}
 		wantBkt := test.conf.Buckets[test.req.GetProject().String()]
 		obj, err := srv.GetObject(wantBkt, test.req.Filename)
@@ -153,6 +180,50 @@ func TestFileUpload(t *testing.T) {
 	}
 }
 
+func TestFileUploadLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	conf := &config{
+		Buckets: map[string]string{
+			pb.FileRequest_RPKI_RARC.String(): "file://" + dir,
+		},
+	}
+	fs, err := newRVServer(ctx, createConf(t, conf), fakestorage.NewServer(nil).Client())
+	if err != nil {
+		t.Fatalf("failed initializing server: %v", err)
+	}
+	req := &pb.FileRequest{
+		Filename: "rarc/bar",
+		Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+		Content:  []byte("Foo Bar Baz"),
+		Project:  pb.FileRequest_RPKI_RARC,
+	}
+	if _, err := fs.FileUpload(ctx, req); err != nil {
+		t.Fatalf("FileUpload(): %v", err)
+	}
+
+	st, err := objstore.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := st.Stat(ctx, req.Filename)
+	if err != nil {
+		t.Fatalf("Stat(%s): %v", req.Filename, err)
+	}
+	if got := hex.EncodeToString(attrs.MD5); got != req.Md5Sum {
+		t.Errorf("stored md5 = %s; want %s", got, req.Md5Sum)
+	}
+	if gotProj := attrs.Metadata[converter.ProjectMetadataKey]; gotProj != req.Project.String() {
+		t.Errorf("got metadata %s=%s; want %s", converter.ProjectMetadataKey, gotProj, req.Project.String())
+	}
+
+	// The same upload again is skipped.
+	resp, err := fs.FileUpload(ctx, req)
+	if err != nil || resp.GetStatus() != pb.FileResponse_ALREADY_EXISTS {
+		t.Errorf("FileUpload() = %v, %v; want status %s", resp, err, pb.FileResponse_ALREADY_EXISTS)
+	}
+}
+
 func TestBadConfig(t *testing.T) {
 	tests := []struct {
 		desc string
@@ -169,6 +240,86 @@ func TestBadConfig(t *testing.T) {
 				return raw
 			}(),
 		},
+		{
+			desc: "Failure - S3 bucket without S3 endpoint",
+			data: []byte(`buckets: {RPKI_RARC: "s3://foo"}`),
+		},
+		{
+			desc: "Failure - unsupported bucket location",
+			data: []byte(`buckets: {RPKI_RARC: "ftp://foo"}`),
+		},
+		{
+			desc: "Failure - negative batch concurrency",
+			data: []byte(`batch_concurrency: -1`),
+		},
+		{
+			desc: "Failure - auth without audience",
+			data: []byte(`auth: {allow: {"uploader@example.com": [ROUTEVIEWS]}}`),
+		},
+		{
+			desc: "Failure - auth allows unknown project",
+			data: []byte(`auth: {audience: "https://rv", allow: {"uploader@example.com": [NOPE]}}`),
+		},
+		{
+			desc: "Failure - audit to both a file and a bucket",
+			data: []byte(`audit: {file: "/tmp/audit.jsonl", bucket: "audit"}`),
+		},
+		{
+			desc: "Failure - audit without a sink",
+			data: []byte(`audit: {roll_interval: 1m}`),
+		},
+		{
+			desc: "Failure - events to both a url and a topic",
+			data: []byte(`events: {url: "https://rv-converter", topic: "projects/p/topics/t"}`),
+		},
+		{
+			desc: "Failure - events without a publisher",
+			data: []byte(`events: {timeout: 5s}`),
+		},
+		{
+			desc: "Failure - tls without a key",
+			data: []byte(`tls: {cert_file: "/tmp/cert.pem"}`),
+		},
+		{
+			desc: "Failure - tls requires client certificates without a CA",
+			data: []byte(`tls: {cert_file: "/tmp/cert.pem", key_file: "/tmp/key.pem", require_client_cert: true}`),
+		},
+		{
+			desc: "Failure - unknown key",
+			data: []byte(`bukkits: {RPKI_RARC: "foo"}`),
+		},
+		{
+			desc: "Failure - unknown project",
+			data: []byte(`projects: {NOPE: {bucket: "foo"}}`),
+		},
+		{
+			desc: "Failure - project without a bucket",
+			data: []byte(`projects: {RPKI_RARC: {max_size: 1024}}`),
+		},
+		{
+			desc: "Failure - project bucket given twice",
+			data: []byte(`{buckets: {RPKI_RARC: "foo"}, projects: {RPKI_RARC: {bucket: "bar"}}}`),
+		},
+		{
+			desc: "Failure - validate a project without a bucket",
+			data: []byte(`validate: {RPKI_RARC: true}`),
+		},
+		{
+			desc: "Failure - bad path prefix",
+			data: []byte(`projects: {RPKI_RARC: {bucket: "foo", path_prefix: "{{.Nope}}/"}}`),
+		},
+		{
+			desc: "Failure - bad filename pattern",
+			data: []byte(`projects: {RPKI_RARC: {bucket: "foo", filename_pattern: "(["}}`),
+		},
+		{
+			desc: "Failure - negative max size",
+			data: []byte(`projects: {RPKI_RARC: {bucket: "foo", max_size: -1}}`),
+		},
+		{
+			desc: "Failure - unknown storage class",
+			data: []byte(`projects: {RPKI_RARC: {bucket: "foo", storage_class: "GLACIER"}}`),
+		},
 		{
 			desc: "Failure - bad yaml config",
 			data: []byte(`b:a
@@ -187,3 +338,725 @@ func TestBadConfig(t *testing.T) {
 		})
 	}
 }
+
+// startTestServer serves srv over an in-memory listener and returns a
+// client connected to it.
+func startTestServer(t *testing.T, srv pb.RVServer, opts ...grpc.ServerOption) pb.RVClient {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(opts...)
+	pb.RegisterRVServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewRVClient(conn)
+}
+
+// ignoreGeneration ignores the generation of FileResponses, which is set by
+// the store.
+var ignoreGeneration = protocmp.IgnoreFields(&pb.FileResponse{}, "generation")
+
+// wantStored returns the SUCCESS response of content stored at url, less
+// its generation.
+func wantStored(url string, content []byte) *pb.FileResponse {
+	crc := crc32.Checksum(content, crc32cTable)
+	return &pb.FileResponse{
+		Status: pb.FileResponse_SUCCESS,
+		Url:    url,
+		Size:   int64(len(content)),
+		Crc32C: &crc,
+	}
+}
+
+// errorInfo returns the ErrorInfo details of st, or nil.
+func errorInfo(st *status.Status) *errdetails.ErrorInfo {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+// TestFileUploadStream tests a chunked file-upload process request.
+func TestFileUploadStream(t *testing.T) {
+	content := []byte("Foo Bar Baz")
+	tests := []struct {
+		desc     string
+		chunks   []*pb.FileChunk
+		wantCode codes.Code
+	}{{
+		desc: "Success",
+		chunks: []*pb.FileChunk{
+			{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: "bar",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}}},
+			{Chunk: &pb.FileChunk_Content{Content: content[:4]}},
+			{Chunk: &pb.FileChunk_Content{Content: content[4:]}},
+		},
+	}, {
+		desc: "Failure - bad checksum",
+		chunks: []*pb.FileChunk{
+			{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: "bar",
+				Md5Sum:   "abcdefg123456",
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}}},
+			{Chunk: &pb.FileChunk_Content{Content: content}},
+		},
+		wantCode: codes.DataLoss,
+	}, {
+		desc: "Failure - missing header",
+		chunks: []*pb.FileChunk{
+			{Chunk: &pb.FileChunk_Content{Content: content}},
+		},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc: "Failure - unsupported project",
+		chunks: []*pb.FileChunk{
+			{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: "bar",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Project:  pb.FileRequest_RPKI_RARC,
+			}}},
+			{Chunk: &pb.FileChunk_Content{Content: content}},
+		},
+		wantCode: codes.Unimplemented,
+	}, {
+		desc: "Failure - header in the middle",
+		chunks: []*pb.FileChunk{
+			{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: "bar",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}}},
+			{Chunk: &pb.FileChunk_Content{Content: content[:4]}},
+			{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: "bar",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}}},
+			{Chunk: &pb.FileChunk_Content{Content: content[4:]}},
+		},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc: "Failure - no content",
+		chunks: []*pb.FileChunk{
+			{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: "bar",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}}},
+		},
+		wantCode: codes.InvalidArgument,
+	}}
+
+	conf := &config{
+		Buckets: map[string]string{
+			pb.FileRequest_ROUTEVIEWS.String(): "foo",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			fs, err := newRVServer(ctx, createConf(t, conf), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+			client := startTestServer(t, fs)
+
+			stream, err := client.FileUploadStream(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range test.chunks {
+				if err := stream.Send(c); err != nil {
+					break
+				}
+			}
+			got, err := stream.CloseAndRecv()
+			if status.Code(err) != test.wantCode {
+				t.Fatalf("FileUploadStream() = %v, err %v; want code %v", got, err, test.wantCode)
+			}
+
+			obj, err := srv.GetObject("foo", "bar")
+			if test.wantCode != codes.OK {
+				if err == nil {
+					t.Errorf("object stored for a failed upload: %s", obj.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(obj.Content) != string(content) {
+				t.Errorf("stored content = %q; want %q", obj.Content, content)
+			}
+			if gotProj := obj.ObjectAttrs.Metadata[converter.ProjectMetadataKey]; gotProj != pb.FileRequest_ROUTEVIEWS.String() {
+				t.Errorf("got metadata %s=%s; want %s", converter.ProjectMetadataKey, gotProj, pb.FileRequest_ROUTEVIEWS.String())
+			}
+		})
+	}
+}
+
+// TestFileUploadConflicts tests uploading a file which is already stored.
+func TestFileUploadConflicts(t *testing.T) {
+	stored := []byte("Foo Bar Baz")
+	tests := []struct {
+		desc   string
+		policy string
+		req    *pb.FileRequest
+		want   *pb.FileResponse
+		// wantContent is the content stored under the filename afterwards.
+		wantContent string
+		// wantSuperseded is true if the old content is kept under superseded/.
+		wantSuperseded bool
+		wantErr        bool
+		wantCode       codes.Code
+	}{{
+		desc: "unchanged content is skipped",
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+			Content:  stored,
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		},
+		want:        &pb.FileResponse{Status: pb.FileResponse_ALREADY_EXISTS},
+		wantContent: string(stored),
+	}, {
+		desc:   "changed content overwrites",
+		policy: conflictOverwrite,
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Md5Sum:   "22af645d1859cb5ca6da0c484f1f37ea",
+			Content:  []byte("new"),
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		},
+		want:        wantStored("gs://foo/bar", []byte("new")),
+		wantContent: "new",
+	}, {
+		desc:   "changed content is rejected",
+		policy: conflictReject,
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Md5Sum:   "22af645d1859cb5ca6da0c484f1f37ea",
+			Content:  []byte("new"),
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		},
+		wantErr:     true,
+		wantCode:    codes.AlreadyExists,
+		wantContent: string(stored),
+	}, {
+		desc:   "changed content supersedes",
+		policy: conflictSupersede,
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Md5Sum:   "22af645d1859cb5ca6da0c484f1f37ea",
+			Content:  []byte("new"),
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		},
+		want:           wantStored("gs://foo/bar", []byte("new")),
+		wantContent:    "new",
+		wantSuperseded: true,
+	}, {
+		desc:   "failed replacement does not supersede",
+		policy: conflictSupersede,
+		req: &pb.FileRequest{
+			Filename: "bar",
+			Md5Sum:   "abcdefg123456",
+			Content:  []byte("new"),
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		},
+		wantErr:     true,
+		wantCode:    codes.DataLoss,
+		wantContent: string(stored),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets: map[string]string{
+					pb.FileRequest_ROUTEVIEWS.String(): "foo",
+				},
+				ConflictPolicy: test.policy,
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+			if _, err := fs.FileUpload(ctx, &pb.FileRequest{
+				Filename: "bar",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Content:  stored,
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}); err != nil {
+				t.Fatalf("initial FileUpload: %v", err)
+			}
+
+			got, err := fs.FileUpload(ctx, test.req)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("FileUpload() = %v, err %v; wantErr %v", got, err, test.wantErr)
+			}
+			if test.wantErr {
+				if got := status.Code(err); got != test.wantCode {
+					t.Errorf("FileUpload() code = %s; want %s", got, test.wantCode)
+				}
+			} else {
+				if diff := cmp.Diff(got, test.want, protocmp.Transform(), ignoreGeneration); diff != "" {
+					t.Errorf("got/want mismatch:\n%v\n", diff)
+				}
+			}
+
+			obj, err := srv.GetObject("foo", "bar")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(obj.Content) != test.wantContent {
+				t.Errorf("stored content = %q; want %q", obj.Content, test.wantContent)
+			}
+			objs, _, err := srv.ListObjectsWithOptions("foo", fakestorage.ListOptions{Prefix: supersededPrefix})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotSuperseded := len(objs) == 1; gotSuperseded != test.wantSuperseded {
+				t.Fatalf("got %d superseded objects; want superseded %v", len(objs), test.wantSuperseded)
+			}
+			if test.wantSuperseded {
+				old, err := srv.GetObject("foo", objs[0].Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(old.Content) != string(stored) {
+					t.Errorf("superseded content = %q; want %q", old.Content, stored)
+				}
+				if proj, ok := old.Metadata[converter.ProjectMetadataKey]; ok {
+					t.Errorf("superseded object has metadata %s=%s; want none", converter.ProjectMetadataKey, proj)
+				}
+			}
+		})
+	}
+}
+
+// fakeMRT returns an MRT record of a BGP update.
+func fakeMRT(t *testing.T) []byte {
+	t.Helper()
+	m, err := mrt.NewMRTMessage(uint32(time.Now().Unix()), mrt.BGP4MP, mrt.MESSAGE_AS4,
+		mrt.NewBGP4MPMessage(100000, 6447, 0, "1.0.0.0", "2.0.0.0", true, bgp.NewBGPUpdateMessage(nil, nil, []*bgp.IPAddrPrefix{
+			bgp.NewIPAddrPrefix(24, "10.0.0.0"),
+		})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := m.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// fakeArchive returns content compressed with bzip2.
+func fakeArchive(t *testing.T, content []byte) []byte {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	bw, err := bzip2.NewWriter(buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// metadataCounter counts the metadata updates of a store.
+type metadataCounter struct {
+	objstore.ObjectStore
+	n int
+}
+
+func (m *metadataCounter) UpdateMetadata(ctx context.Context, name string, md map[string]string) (*objstore.Attrs, error) {
+	m.n++
+	return m.ObjectStore.UpdateMetadata(ctx, name, md)
+}
+
+// TestFileUploadArchiveAttrs tests that archive attributes are stored along
+// with an uploaded MRT archive.
+func TestFileUploadArchiveAttrs(t *testing.T) {
+	content := fakeArchive(t, fakeMRT(t))
+	sum := md5.Sum(content)
+	sha := sha256.Sum256(content)
+	fn := "route-views.sg/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+
+	uploads := map[string]func(ctx context.Context, client pb.RVClient) error{
+		"FileUpload": func(ctx context.Context, client pb.RVClient) error {
+			_, err := client.FileUpload(ctx, &pb.FileRequest{
+				Filename: fn,
+				Md5Sum:   hex.EncodeToString(sum[:]),
+				Content:  content,
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			})
+			return err
+		},
+		"FileUploadStream": func(ctx context.Context, client pb.RVClient) error {
+			stream, err := client.FileUploadStream(ctx)
+			if err != nil {
+				return err
+			}
+			stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Header_{Header: &pb.FileChunk_Header{
+				Filename: fn,
+				Checksum: &pb.FileChunk_Header_Sha256{Sha256: hex.EncodeToString(sha[:])},
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}}})
+			stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: content[:10]}})
+			stream.Send(&pb.FileChunk{Chunk: &pb.FileChunk_Content{Content: content[10:]}})
+			_, err = stream.CloseAndRecv()
+			return err
+		},
+		"FinishUpload": func(ctx context.Context, client pb.RVClient) error {
+			s, err := client.StartUpload(ctx, &pb.StartUploadRequest{
+				Filename: fn,
+				Md5Sum:   hex.EncodeToString(sum[:]),
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := client.AppendUpload(ctx, &pb.AppendUploadRequest{SessionId: s.GetSessionId(), Content: content[:10]}); err != nil {
+				return err
+			}
+			if _, err := client.AppendUpload(ctx, &pb.AppendUploadRequest{SessionId: s.GetSessionId(), Offset: 10, Content: content[10:]}); err != nil {
+				return err
+			}
+			_, err = client.FinishUpload(ctx, &pb.FinishUploadRequest{SessionId: s.GetSessionId()})
+			return err
+		},
+	}
+	for name, upload := range uploads {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets: map[string]string{
+					pb.FileRequest_ROUTEVIEWS.String(): "foo",
+				},
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+			// The attributes are stored in the same write as the content,
+			// metadata updates would trigger the conversion again.
+			mc := &metadataCounter{ObjectStore: fs.stores[pb.FileRequest_ROUTEVIEWS]}
+			fs.stores[pb.FileRequest_ROUTEVIEWS] = mc
+			if err := upload(ctx, startTestServer(t, fs)); err != nil {
+				t.Fatal(err)
+			}
+			if mc.n != 0 {
+				t.Errorf("got %d metadata updates; want none", mc.n)
+			}
+
+			obj, err := srv.GetObject("foo", fn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if obj.ContentType != "application/octet-stream" || obj.ContentEncoding != "bzip2" {
+				t.Errorf("got content type %q, encoding %q; want application/octet-stream, bzip2", obj.ContentType, obj.ContentEncoding)
+			}
+			want := map[string]string{
+				converter.ProjectMetadataKey:   pb.FileRequest_ROUTEVIEWS.String(),
+				converter.FilenameMetadataKey:  fn,
+				converter.CollectorMetadataKey: "route-views.sg",
+				converter.MRTTypeMetadataKey:   converter.MRTTypeUpdates,
+				converter.SHA256MetadataKey:    hex.EncodeToString(sha[:]),
+			}
+			if diff := cmp.Diff(want, obj.Metadata); diff != "" {
+				t.Errorf("metadata diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileUploadChecksums(t *testing.T) {
+	content := []byte("Foo Bar Baz")
+	sha := sha256.Sum256(content)
+	crc := crc32.Checksum(content, crc32cTable)
+	tests := []struct {
+		desc    string
+		req     *pb.FileRequest
+		wantErr bool
+	}{{
+		desc: "sha256 only",
+		req:  &pb.FileRequest{Checksum: &pb.FileRequest_Sha256{Sha256: hex.EncodeToString(sha[:])}},
+	}, {
+		desc: "crc32c only",
+		req:  &pb.FileRequest{Checksum: &pb.FileRequest_Crc32C{Crc32C: crc}},
+	}, {
+		desc: "md5sum and sha256",
+		req: &pb.FileRequest{
+			Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+			Checksum: &pb.FileRequest_Sha256{Sha256: hex.EncodeToString(sha[:])},
+		},
+	}, {
+		desc:    "no checksum",
+		req:     &pb.FileRequest{},
+		wantErr: true,
+	}, {
+		desc:    "bad sha256",
+		req:     &pb.FileRequest{Checksum: &pb.FileRequest_Sha256{Sha256: "abcdef"}},
+		wantErr: true,
+	}, {
+		desc:    "bad crc32c",
+		req:     &pb.FileRequest{Checksum: &pb.FileRequest_Crc32C{Crc32C: crc + 1}},
+		wantErr: true,
+	}, {
+		desc: "good md5sum, bad sha256",
+		req: &pb.FileRequest{
+			Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+			Checksum: &pb.FileRequest_Sha256{Sha256: "abcdef"},
+		},
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets: map[string]string{pb.FileRequest_RPKI_RARC.String(): "foo"},
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initializing server: %v", err)
+			}
+			test.req.Filename = "bar"
+			test.req.Content = content
+			test.req.Project = pb.FileRequest_RPKI_RARC
+
+			_, err = fs.FileUpload(ctx, test.req)
+			if test.wantErr {
+				if err == nil {
+					t.Error("FileUpload() = nil err; want non-nil err")
+				}
+				if _, err := srv.GetObject("foo", "bar"); err == nil {
+					t.Error("object stored for a failed upload")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FileUpload(): %v", err)
+			}
+			obj, err := srv.GetObject("foo", "bar")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := obj.Metadata[converter.SHA256MetadataKey]; got != hex.EncodeToString(sha[:]) {
+				t.Errorf("got metadata %s=%s; want %x", converter.SHA256MetadataKey, got, sha)
+			}
+
+			// The stored object is compared on the checksum sent.
+			resp, err := fs.FileUpload(ctx, test.req)
+			if err != nil || resp.GetStatus() != pb.FileResponse_ALREADY_EXISTS {
+				t.Errorf("FileUpload() again = %v, %v; want status %s", resp, err, pb.FileResponse_ALREADY_EXISTS)
+			}
+		})
+	}
+}
+
+// TestFileUploadValidation tests that invalid archives are rejected and
+// quarantined.
+func TestFileUploadValidation(t *testing.T) {
+	rec := fakeMRT(t)
+	valid := fakeArchive(t, rec)
+	truncated := fakeArchive(t, rec[:len(rec)-3])
+	fn := "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+
+	tests := []struct {
+		desc       string
+		content    []byte
+		validate   bool
+		quarantine string
+		want       *pb.FileResponse
+		// wantErr is the message of the INVALID_ARGUMENT error of a rejected
+		// archive, and wantInfo the metadata of its ErrorInfo.
+		wantErr  string
+		wantInfo map[string]string
+		// wantQuarantined is true if the content is stored in quarantine
+		// instead of the archive bucket.
+		wantQuarantined bool
+	}{{
+		desc:       "valid archive",
+		content:    valid,
+		validate:   true,
+		quarantine: "quarantine",
+		want:       wantStored("gs://foo/"+fn, valid),
+	}, {
+		desc:       "truncated archive is quarantined",
+		content:    truncated,
+		validate:   true,
+		quarantine: "quarantine",
+		wantErr:    fmt.Sprintf("invalid archive at byte offset %d: truncated MRT record: %d of %d bytes", len(rec)-3, len(rec)-15, len(rec)-12),
+		wantInfo: map[string]string{
+			"offset":         fmt.Sprint(len(rec) - 3),
+			"reason":         fmt.Sprintf("truncated MRT record: %d of %d bytes", len(rec)-15, len(rec)-12),
+			"quarantine_url": "gs://quarantine/" + fn,
+		},
+		wantQuarantined: true,
+	}, {
+		desc:     "truncated archive without quarantine",
+		content:  truncated,
+		validate: true,
+		wantErr:  fmt.Sprintf("invalid archive at byte offset %d: truncated MRT record: %d of %d bytes", len(rec)-3, len(rec)-15, len(rec)-12),
+		wantInfo: map[string]string{
+			"offset": fmt.Sprint(len(rec) - 3),
+			"reason": fmt.Sprintf("truncated MRT record: %d of %d bytes", len(rec)-15, len(rec)-12),
+		},
+	}, {
+		desc:    "validation disabled",
+		content: truncated,
+		want:    wantStored("gs://foo/"+fn, truncated),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			srv.CreateBucket("quarantine")
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets: map[string]string{
+					pb.FileRequest_ROUTEVIEWS.String(): "foo",
+				},
+				Validate: map[string]bool{
+					pb.FileRequest_ROUTEVIEWS.String(): test.validate,
+				},
+				QuarantineBucket: test.quarantine,
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+			sum := md5.Sum(test.content)
+			got, err := startTestServer(t, fs).FileUpload(ctx, &pb.FileRequest{
+				Filename: fn,
+				Md5Sum:   hex.EncodeToString(sum[:]),
+				Content:  test.content,
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			})
+			if test.wantErr != "" {
+				st := status.Convert(err)
+				if st.Code() != codes.InvalidArgument || st.Message() != test.wantErr {
+					t.Fatalf("FileUpload() = %v, %v; want %s %q", got, err, codes.InvalidArgument, test.wantErr)
+				}
+				info := errorInfo(st)
+				if info.GetReason() != reasonInvalidArchive {
+					t.Errorf("got ErrorInfo %v; want reason %s", info, reasonInvalidArchive)
+				}
+				if diff := cmp.Diff(test.wantInfo, info.GetMetadata()); diff != "" {
+					t.Errorf("ErrorInfo metadata diff (-want +got):\n%s", diff)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("FileUpload(): %v", err)
+				}
+				if diff := cmp.Diff(test.want, got, protocmp.Transform(), ignoreGeneration); diff != "" {
+					t.Errorf("FileUpload() diff (-want +got):\n%s", diff)
+				}
+			}
+
+			_, err = srv.GetObject("foo", fn)
+			if stored := err == nil; stored != (test.wantErr == "") {
+				t.Errorf("archive stored = %v; want %v", stored, !stored)
+			}
+			obj, err := srv.GetObject("quarantine", fn)
+			if quarantined := err == nil; quarantined != test.wantQuarantined {
+				t.Fatalf("archive quarantined = %v; want %v", quarantined, test.wantQuarantined)
+			}
+			if test.wantQuarantined {
+				if reason := obj.Metadata[quarantineReasonKey]; reason != test.wantErr {
+					t.Errorf("got metadata %s=%q; want %q", quarantineReasonKey, reason, test.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestFileUploadMetrics(t *testing.T) {
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+	tests := []struct {
+		desc         string
+		sum          string
+		wantStatus   pb.FileResponse_Status
+		wantChecksum float64
+	}{{
+		desc:       "success",
+		sum:        hex.EncodeToString(sum[:]),
+		wantStatus: pb.FileResponse_SUCCESS,
+	}, {
+		desc:         "checksum failure",
+		sum:          "bad",
+		wantStatus:   pb.FileResponse_FAIL,
+		wantChecksum: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets: map[string]string{
+					pb.FileRequest_ROUTEVIEWS.String(): "foo",
+				},
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+
+			proj := pb.FileRequest_ROUTEVIEWS.String()
+			uploads := metrics.Uploads.WithLabelValues(proj, test.wantStatus.String())
+			before := testutil.ToFloat64(uploads)
+			beforeBytes := testutil.ToFloat64(metrics.BytesReceived.WithLabelValues(proj))
+			beforeChecksum := testutil.ToFloat64(metrics.ChecksumFailures.WithLabelValues(proj))
+
+			startTestServer(t, fs).FileUpload(ctx, &pb.FileRequest{
+				Filename: fmt.Sprintf("bgpdata/2021.11/UPDATES/metrics.%d", time.Now().UnixNano()),
+				Md5Sum:   test.sum,
+				Content:  content,
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			})
+
+			if got := testutil.ToFloat64(uploads) - before; got != 1 {
+				t.Errorf("uploads{%s, %s} increased by %v; want 1", proj, test.wantStatus, got)
+			}
+			if got := testutil.ToFloat64(metrics.BytesReceived.WithLabelValues(proj)) - beforeBytes; got != float64(len(content)) {
+				t.Errorf("bytes received increased by %v; want %d", got, len(content))
+			}
+			if got := testutil.ToFloat64(metrics.ChecksumFailures.WithLabelValues(proj)) - beforeChecksum; got != test.wantChecksum {
+				t.Errorf("checksum failures increased by %v; want %v", got, test.wantChecksum)
+			}
+		})
+	}
+}
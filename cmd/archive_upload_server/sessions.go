@@ -0,0 +1,517 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// uploadPartPrefix is where the parts of unfinished uploads are kept in
+	// the destination bucket. Parts are removed once the upload is finished,
+	// or once its session expires.
+	uploadPartPrefix = converter.UploadPartPrefix
+
+	// defaultSessionTTL is how long upload sessions last, unless the config
+	// sets session_ttl.
+	defaultSessionTTL = 7 * 24 * time.Hour
+
+	// sessionPrefix is where the session records are kept in the session
+	// bucket, apart from any other objects of the bucket.
+	sessionPrefix = "sessions/"
+)
+
+// errSessionNotFound is returned for unknown, closed or expired sessions.
+var errSessionNotFound = status.Error(codes.NotFound, "upload session not found")
+
+// uploadSession is the server side state of a resumable upload.
+type uploadSession struct {
+	ID       string
+	Filename string
+	Md5Sum   string
+	SHA256   string  `json:",omitempty"`
+	CRC32C   *uint32 `json:",omitempty"`
+	Project  pb.FileRequest_Project
+	// Caller is the identity of the caller who started the session, empty
+	// if auth is not configured.
+	Caller string `json:",omitempty"`
+	// Offset is the number of bytes committed to the session.
+	Offset int64
+	// Parts holds the starting offset of each stored part, in order.
+	Parts   []int64
+	Created time.Time
+}
+
+// partName is the object name of a part starting at offset.
+func (s *uploadSession) partName(offset int64) string {
+	return fmt.Sprintf("%s%s/%020d", uploadPartPrefix, s.ID, offset)
+}
+
+// sessionStore persists upload sessions, so an upload may be resumed
+// on any server instance.
+type sessionStore interface {
+	Get(ctx context.Context, id string) (*uploadSession, error)
+	Put(ctx context.Context, s *uploadSession) error
+	Delete(ctx context.Context, id string) error
+	// Expire deletes the sessions last changed before t, at least those
+	// started before t.
+	Expire(ctx context.Context, t time.Time) error
+}
+
+// memSessionStore keeps upload sessions in memory; sessions do not survive
+// a server restart.
+type memSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]uploadSession
+}
+
+func newMemSessionStore() *memSessionStore {
+	return &memSessionStore{sessions: map[string]uploadSession{}}
+}
+
+func (m *memSessionStore) Get(_ context.Context, id string) (*uploadSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, errSessionNotFound
+	}
+	s.Parts = append([]int64(nil), s.Parts...)
+	return &s, nil
+}
+
+func (m *memSessionStore) Put(_ context.Context, s *uploadSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := *s
+	c.Parts = append([]int64(nil), s.Parts...)
+	m.sessions[s.ID] = c
+	return nil
+}
+
+func (m *memSessionStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memSessionStore) Expire(_ context.Context, t time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, s := range m.sessions {
+		if s.Created.Before(t) {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+// objSessionStore keeps upload sessions as JSON objects under
+// sessionPrefix in a store.
+type objSessionStore struct {
+	st objstore.ObjectStore
+}
+
+// name is the object name of the record of session id.
+func (o *objSessionStore) name(id string) string {
+	return sessionPrefix + id + ".json"
+}
+
+func (o *objSessionStore) Get(ctx context.Context, id string) (*uploadSession, error) {
+	rd, err := o.st.Read(ctx, o.name(id))
+	if err == objstore.ErrNotExist {
+		return nil, errSessionNotFound
+	}
+	if err != nil {
+		return nil, newStorageError(o.st.URL(o.name(id)), err, "failed to read session %s", id)
+	}
+	defer rd.Close()
+	s := &uploadSession{}
+	if err := json.NewDecoder(rd).Decode(s); err != nil {
+		return nil, fmt.Errorf("failed to decode session %s: %v", id, err)
+	}
+	return s, nil
+}
+
+func (o *objSessionStore) Put(ctx context.Context, s *uploadSession) error {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	attrs := &objstore.Attrs{ContentType: "application/json"}
+	if _, err := o.st.Put(ctx, o.name(s.ID), bytes.NewReader(raw), attrs); err != nil {
+		return newStorageError(o.st.URL(o.name(s.ID)), err, "failed to write session %s", s.ID)
+	}
+	return nil
+}
+
+func (o *objSessionStore) Delete(ctx context.Context, id string) error {
+	if err := o.st.Delete(ctx, o.name(id)); err != nil && err != objstore.ErrNotExist {
+		return newStorageError(o.st.URL(o.name(id)), err, "failed to delete session %s", id)
+	}
+	return nil
+}
+
+// Expire deletes the sessions whose object was last written before t.
+// Sessions are written when they start, so the others are younger. Only
+// the objects under sessionPrefix are considered.
+func (o *objSessionStore) Expire(ctx context.Context, t time.Time) error {
+	return o.st.List(ctx, sessionPrefix, func(a *objstore.Attrs) error {
+		id := strings.TrimPrefix(a.Name, sessionPrefix)
+		if !strings.HasSuffix(id, ".json") || !a.Updated.Before(t) {
+			return nil
+		}
+		return o.Delete(ctx, strings.TrimSuffix(id, ".json"))
+	})
+}
+
+// uploadSessions holds the upload sessions of one server. Changes to a
+// session are serialized by a lock of its own, so uploads to different
+// sessions do not wait for each other. The locks are held in memory: a
+// session may be resumed on another instance sharing the session store,
+// but concurrent calls on the same session must go to a single instance.
+type uploadSessions struct {
+	store sessionStore
+
+	// mu guards locks, which holds the lock of each session in use.
+	mu    sync.Mutex
+	locks map[string]*sessionLock
+}
+
+type sessionLock struct {
+	sync.Mutex
+	// refs counts the calls holding, or waiting for, the lock.
+	refs int
+}
+
+// lock locks the session id, and returns the func which unlocks it.
+func (u *uploadSessions) lock(id string) func() {
+	u.mu.Lock()
+	if u.locks == nil {
+		u.locks = make(map[string]*sessionLock)
+	}
+	l, ok := u.locks[id]
+	if !ok {
+		l = &sessionLock{}
+		u.locks[id] = l
+	}
+	l.refs++
+	u.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(u.locks, id)
+		}
+	}
+}
+
+// session returns the upload session id, unless it is older than the
+// session TTL.
+func (r rvServer) session(ctx context.Context, id string) (*uploadSession, error) {
+	s, err := r.sessions.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(s.Created) > r.conf.SessionTTL {
+		return nil, errSessionNotFound
+	}
+	return s, nil
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// StartUpload opens a new resumable upload session.
+func (r rvServer) StartUpload(ctx context.Context, req *pb.StartUploadRequest) (*pb.UploadSession, error) {
+	r = r.load()
+	fn := req.GetFilename()
+	proj := req.GetProject()
+	sums := newChecksums(req.GetMd5Sum(), req.GetChecksum())
+	if err := checkRequired("base requirements for StartUploadRequest unmet",
+		field{"filename", len(fn) > 0},
+		field{"checksum", !sums.empty()},
+		field{"project", proj != pb.FileRequest_UNKNOWN},
+	); err != nil {
+		return nil, err
+	}
+	st, ok := r.stores[proj]
+	if !ok {
+		return nil, &unsupportedProjectError{proj: proj}
+	}
+	if err := r.checkFile(proj, fn, -1); err != nil {
+		return nil, err
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session id: %v", err)
+	}
+	s := &uploadSession{
+		ID:       id,
+		Filename: fn,
+		Md5Sum:   sums.MD5,
+		SHA256:   sums.SHA256,
+		CRC32C:   sums.CRC32C,
+		Project:  proj,
+		Created:  time.Now(),
+	}
+	s.Caller, _ = callerFrom(ctx)
+	if err := r.sessions.store.Put(ctx, s); err != nil {
+		return nil, err
+	}
+	glog.Infof("Started upload session %s for %s", id, st.URL(fn))
+	return &pb.UploadSession{SessionId: id}, nil
+}
+
+// AppendUpload stores a piece of content as a part of the upload session.
+// Content which was already committed is skipped, so clients may safely
+// resend a piece whose acknowledgement was lost.
+func (r rvServer) AppendUpload(ctx context.Context, req *pb.AppendUploadRequest) (*pb.UploadSession, error) {
+	r = r.load()
+	defer r.sessions.lock(req.GetSessionId())()
+
+	s, err := r.session(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	if err := r.authorizeSession(ctx, s); err != nil {
+		return nil, err
+	}
+	content := req.GetContent()
+	off := req.GetOffset()
+	switch {
+	case off < 0, off > s.Offset:
+		return nil, &offsetError{offset: off, committed: s.Offset}
+	case off+int64(len(content)) <= s.Offset:
+		// Everything has been committed already.
+		return &pb.UploadSession{SessionId: s.ID, CommittedOffset: s.Offset}, nil
+	}
+	metrics.BytesReceived.WithLabelValues(s.Project.String()).Add(float64(len(content)))
+	if err := r.checkFile(s.Project, s.Filename, off+int64(len(content))); err != nil {
+		return nil, err
+	}
+	content = content[s.Offset-off:]
+
+	st, ok := r.stores[s.Project]
+	if !ok {
+		return nil, &unsupportedProjectError{proj: s.Project}
+	}
+	if _, err := st.Put(ctx, s.partName(s.Offset), bytes.NewReader(content), nil); err != nil {
+		return nil, newStorageError(st.URL(s.partName(s.Offset)), err, "failed to store part of session %s", s.ID)
+	}
+	s.Parts = append(s.Parts, s.Offset)
+	s.Offset += int64(len(content))
+	if err := r.sessions.store.Put(ctx, s); err != nil {
+		return nil, err
+	}
+	return &pb.UploadSession{SessionId: s.ID, CommittedOffset: s.Offset}, nil
+}
+
+// QueryUpload reports the committed offset of an upload session.
+func (r rvServer) QueryUpload(ctx context.Context, req *pb.QueryUploadRequest) (*pb.UploadSession, error) {
+	r = r.load()
+	s, err := r.session(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	if err := r.authorizeSession(ctx, s); err != nil {
+		return nil, err
+	}
+	return &pb.UploadSession{SessionId: s.ID, CommittedOffset: s.Offset}, nil
+}
+
+// FinishUpload assembles the parts of an upload session into the final
+// object. The object is only committed if the checksum of all parts matches
+// the one given when the session started. The session is closed either way.
+func (r rvServer) FinishUpload(ctx context.Context, req *pb.FinishUploadRequest) (resp *pb.FileResponse, err error) {
+	r = r.load()
+	var (
+		proj pb.FileRequest_Project
+		s    *uploadSession
+	)
+	defer func() {
+		countUpload(proj, resp, err)
+		if s != nil {
+			r.auditUpload(ctx, s.Project, s.Filename, s.Offset, checksums{MD5: s.Md5Sum, SHA256: s.SHA256, CRC32C: s.CRC32C}, resp, err)
+		}
+	}()
+
+	defer r.sessions.lock(req.GetSessionId())()
+
+	s, err = r.session(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	if err := r.authorizeSession(ctx, s); err != nil {
+		return nil, err
+	}
+	proj = s.Project
+	if s.Offset < 1 {
+		return nil, &requestError{msg: "base requirements for FinishUploadRequest unmet: no content", fields: []string{"content"}}
+	}
+	st, ok := r.stores[s.Project]
+	if !ok {
+		return nil, &unsupportedProjectError{proj: s.Project}
+	}
+	defer r.closeSession(ctx, st, s)
+
+	sums := checksums{MD5: s.Md5Sum, SHA256: s.SHA256, CRC32C: s.CRC32C}
+	exists, prev, err := r.checkExisting(ctx, st, s.Filename, sums)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		glog.Infof("Skipped unchanged datafile of upload session %s: %s", s.ID, s.Filename)
+		return &pb.FileResponse{Status: pb.FileResponse_ALREADY_EXISTS}, nil
+	}
+
+	// The parts are read once more to hash them, rather than setting the
+	// SHA-256 after the object is stored.
+	hr := &partsReader{ctx: ctx, st: st, s: s}
+	sums, err = sums.withSHA256(hr)
+	hr.Close()
+	if err != nil {
+		return nil, newStorageError(st.URL(uploadPartPrefix+s.ID), err, "failed to hash the content of session %s", s.ID)
+	}
+	r.track(ctx, s.Filename, s.Project, metadata.StatusReceiving, nil)
+	pr := &partsReader{ctx: ctx, st: st, s: s}
+	defer pr.Close()
+	attrs, stored, err := r.fileStore(ctx, st, s.Filename, s.Project, sums, prev, pr)
+	if err != nil {
+		r.track(ctx, s.Filename, s.Project, metadata.StatusFailed, err)
+		return nil, err
+	}
+	r.trackStored(ctx, attrs, stored)
+	glog.Infof("Finished processing upload session %s: %s", s.ID, s.Filename)
+	return storedResponse(st, stored), nil
+}
+
+// partsReader reads the stored parts of an upload session in order.
+type partsReader struct {
+	ctx  context.Context
+	st   objstore.ObjectStore
+	s    *uploadSession
+	cur  io.ReadCloser
+	next int
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.cur == nil {
+			if p.next == len(p.s.Parts) {
+				return 0, io.EOF
+			}
+			rd, err := p.st.Read(p.ctx, p.s.partName(p.s.Parts[p.next]))
+			if err != nil {
+				return 0, fmt.Errorf("failed to read part of session %s: %v", p.s.ID, err)
+			}
+			p.cur = rd
+			p.next++
+		}
+		n, err := p.cur.Read(b)
+		if err == io.EOF {
+			p.cur.Close()
+			p.cur = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Close closes the part being read, if any.
+func (p *partsReader) Close() error {
+	if p.cur == nil {
+		return nil
+	}
+	err := p.cur.Close()
+	p.cur = nil
+	return err
+}
+
+// closeSession removes the stored parts and state of an upload session.
+// Failures are only logged, leftover parts do not affect the archive.
+func (r rvServer) closeSession(ctx context.Context, st objstore.ObjectStore, s *uploadSession) {
+	for _, p := range s.Parts {
+		if err := st.Delete(ctx, s.partName(p)); err != nil {
+			glog.Warningf("failed to delete part %s: %v", st.URL(s.partName(p)), err)
+		}
+	}
+	if err := r.sessions.store.Delete(ctx, s.ID); err != nil {
+		glog.Warningf("failed to delete session %s: %v", s.ID, err)
+	}
+}
+
+// expireUploads deletes the upload sessions older than the session TTL,
+// and the parts they left behind in the store of each project. Parts of
+// live sessions are younger than the TTL, as they are written after their
+// session starts. Failures are only logged, and retried on the next call.
+func (r rvServer) expireUploads(ctx context.Context) {
+	r = r.load()
+	before := time.Now().Add(-r.conf.SessionTTL)
+	if err := r.sessions.store.Expire(ctx, before); err != nil {
+		glog.Warningf("failed to expire upload sessions: %v", err)
+	}
+	for proj, st := range r.stores {
+		n := 0
+		err := st.List(ctx, uploadPartPrefix, func(a *objstore.Attrs) error {
+			if !a.Updated.Before(before) {
+				return nil
+			}
+			if err := st.Delete(ctx, a.Name); err != nil && !errors.Is(err, objstore.ErrNotExist) {
+				glog.Warningf("failed to delete expired part %s: %v", st.URL(a.Name), err)
+				return nil
+			}
+			n++
+			return nil
+		})
+		if err != nil {
+			glog.Warningf("failed to list the upload parts of %s: %v", proj, err)
+		}
+		if n > 0 {
+			glog.Infof("Deleted %d expired upload parts of %s", n, proj)
+		}
+	}
+}
+
+// expireUploadsEvery calls expireUploads every interval, until ctx is done.
+func (r rvServer) expireUploadsEvery(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			r.expireUploads(ctx)
+		}
+	}
+}
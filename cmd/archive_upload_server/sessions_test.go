@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestResumableUpload tests an upload split over several requests, with
+// a resent piece of content, against both session stores.
+func TestResumableUpload(t *testing.T) {
+	content := []byte("Foo Bar Baz")
+	tests := []struct {
+		desc          string
+		sessionBucket string
+		md5sum        string
+		wantErr       bool
+	}{{
+		desc:   "memory store: Success",
+		md5sum: "50e3903156f5d2dac6c9f89626d48c75",
+	}, {
+		desc:          "GCS store: Success",
+		sessionBucket: "sessions",
+		md5sum:        "50e3903156f5d2dac6c9f89626d48c75",
+	}, {
+		desc:          "GCS store: Failure - bad checksum",
+		sessionBucket: "sessions",
+		md5sum:        "abcdefg123456",
+		wantErr:       true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			srv.CreateBucket("sessions")
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets: map[string]string{
+					pb.FileRequest_ROUTEVIEWS.String(): "foo",
+				},
+				SessionBucket: test.sessionBucket,
+			}), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+
+			sess, err := fs.StartUpload(ctx, &pb.StartUploadRequest{
+				Filename: "bar",
+				Md5Sum:   test.md5sum,
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			})
+			if err != nil {
+				t.Fatalf("StartUpload: %v", err)
+			}
+			id := sess.GetSessionId()
+
+			appends := []struct {
+				offset int64
+				data   []byte
+				want   int64
+			}{
+				{0, content[:4], 4},
+				// Resent content is skipped.
+				{0, content[:4], 4},
+				// Overlapping content is trimmed.
+				{2, content[2:8], 8},
+				{8, content[8:], int64(len(content))},
+			}
+			for _, a := range appends {
+				got, err := fs.AppendUpload(ctx, &pb.AppendUploadRequest{SessionId: id, Offset: a.offset, Content: a.data})
+				if err != nil {
+					t.Fatalf("AppendUpload(%d): %v", a.offset, err)
+				}
+				if got.GetCommittedOffset() != a.want {
+					t.Errorf("AppendUpload(%d) committed %d; want %d", a.offset, got.GetCommittedOffset(), a.want)
+				}
+			}
+			if _, err := fs.AppendUpload(ctx, &pb.AppendUploadRequest{SessionId: id, Offset: 100, Content: content}); status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("AppendUpload past the committed offset: err %v; want code %v", err, codes.FailedPrecondition)
+			}
+			if _, err := fs.AppendUpload(ctx, &pb.AppendUploadRequest{SessionId: id, Offset: -1, Content: content}); status.Code(err) != codes.OutOfRange {
+				t.Errorf("AppendUpload at a negative offset: err %v; want code %v", err, codes.OutOfRange)
+			}
+			q, err := fs.QueryUpload(ctx, &pb.QueryUploadRequest{SessionId: id})
+			if err != nil || q.GetCommittedOffset() != int64(len(content)) {
+				t.Errorf("QueryUpload() = %v, %v; want offset %d", q, err, len(content))
+			}
+
+			_, err = fs.FinishUpload(ctx, &pb.FinishUploadRequest{SessionId: id})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("FinishUpload() err %v; wantErr %v", err, test.wantErr)
+			}
+
+			// The session is closed and its parts are removed either way.
+			if _, err := fs.QueryUpload(ctx, &pb.QueryUploadRequest{SessionId: id}); status.Code(err) != codes.NotFound {
+				t.Errorf("QueryUpload() after finish: err %v; want code %v", err, codes.NotFound)
+			}
+			objs, _, err := srv.ListObjectsWithOptions("foo", fakestorage.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range objs {
+				if strings.HasPrefix(o.Name, uploadPartPrefix) {
+					t.Errorf("part %s left behind", o.Name)
+				}
+			}
+
+			obj, err := srv.GetObject("foo", "bar")
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("object stored for a failed upload: %s", obj.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(obj.Content) != string(content) {
+				t.Errorf("stored content = %q; want %q", obj.Content, content)
+			}
+			if gotProj := obj.ObjectAttrs.Metadata[converter.ProjectMetadataKey]; gotProj != pb.FileRequest_ROUTEVIEWS.String() {
+				t.Errorf("got metadata %s=%s; want %s", converter.ProjectMetadataKey, gotProj, pb.FileRequest_ROUTEVIEWS.String())
+			}
+		})
+	}
+}
+
+func TestStartUploadErrors(t *testing.T) {
+	tests := []struct {
+		desc string
+		req  *pb.StartUploadRequest
+	}{
+		{
+			desc: "missing checksum",
+			req:  &pb.StartUploadRequest{Filename: "bar", Project: pb.FileRequest_ROUTEVIEWS},
+		},
+		{
+			desc: "unsupported project",
+			req:  &pb.StartUploadRequest{Filename: "bar", Md5Sum: "abc", Project: pb.FileRequest_RPKI_RARC},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			fs, err := newRVServer(context.Background(), createConf(t, &config{
+				Buckets: map[string]string{pb.FileRequest_ROUTEVIEWS.String(): "foo"},
+			}), srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := fs.StartUpload(context.Background(), test.req); err == nil {
+				t.Error("StartUpload: nil err; want non-nil err")
+			}
+		})
+	}
+}
+
+// TestExpireUploads tests that sessions older than the session TTL are
+// gone, along with their parts, against both session stores.
+func TestExpireUploads(t *testing.T) {
+	for _, sessionBucket := range []string{"", "sessions"} {
+		t.Run("session bucket "+sessionBucket, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			srv.CreateBucket("sessions")
+			// Other records of a shared bucket are not sessions.
+			srv.CreateObject(fakestorage.Object{
+				ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "sessions", Name: "records/foo.json"},
+				Content:     []byte("{}"),
+			})
+			fs, err := newRVServer(ctx, createConf(t, &config{
+				Buckets:       map[string]string{pb.FileRequest_ROUTEVIEWS.String(): "foo"},
+				SessionBucket: sessionBucket,
+				SessionTTL:    time.Second,
+			}), srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			sess, err := fs.StartUpload(ctx, &pb.StartUploadRequest{
+				Filename: "bar",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := sess.GetSessionId()
+			if _, err := fs.AppendUpload(ctx, &pb.AppendUploadRequest{SessionId: id, Content: []byte("Foo")}); err != nil {
+				t.Fatal(err)
+			}
+
+			time.Sleep(2 * time.Second)
+			if _, err := fs.QueryUpload(ctx, &pb.QueryUploadRequest{SessionId: id}); status.Code(err) != codes.NotFound {
+				t.Errorf("QueryUpload() of an expired session: err %v; want code %v", err, codes.NotFound)
+			}
+			fs.expireUploads(ctx)
+			if _, err := srv.GetObject("sessions", "records/foo.json"); err != nil {
+				t.Errorf("expireUploads() deleted an object which is not a session: %v", err)
+			}
+			for _, bucket := range []string{"foo", "sessions"} {
+				objs, _, err := srv.ListObjectsWithOptions(bucket, fakestorage.ListOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, o := range objs {
+					if bucket == "sessions" && o.Name == "records/foo.json" {
+						continue
+					}
+					t.Errorf("object %s/%s left behind", bucket, o.Name)
+				}
+			}
+		})
+	}
+}
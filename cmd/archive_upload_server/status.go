@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fileStatus converts a metadata record to its proto form.
+func fileStatus(rec *metadata.Record) *pb.FileStatus {
+	fs := &pb.FileStatus{
+		Status:          pb.FileStatus_Status(pb.FileStatus_Status_value[string(rec.Status)]),
+		Filename:        rec.Filename,
+		Project:         pb.FileRequest_Project(pb.FileRequest_Project_value[rec.Project]),
+		Collector:       rec.Collector,
+		MrtType:         rec.MRTType,
+		ContentType:     rec.ContentType,
+		ContentEncoding: rec.ContentEncoding,
+		ErrorMessage:    rec.Error,
+	}
+	if !rec.Updated.IsZero() {
+		fs.Updated = timestamppb.New(rec.Updated)
+	}
+	return fs
+}
+
+// GetFileStatus returns the processing state and metadata of a file.
+func (r rvServer) GetFileStatus(ctx context.Context, req *pb.FileStatusRequest) (*pb.FileStatus, error) {
+	r = r.load()
+	if err := checkRequired("base requirements for FileStatusRequest unmet",
+		field{"filename", len(req.GetFilename()) > 0},
+		field{"project", req.GetProject() != pb.FileRequest_UNKNOWN},
+	); err != nil {
+		return nil, err
+	}
+	key := r.record(req.GetProject(), req.GetFilename())
+	rec, err := r.meta.Get(ctx, key.Project, key.Object)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no metadata for %s of %s", req.GetFilename(), req.GetProject())
+	}
+	if err != nil {
+		return nil, newStorageError(r.conf.MetadataDB, err, "failed to get metadata of %s", req.GetFilename())
+	}
+	return fileStatus(rec), nil
+}
+
+// ListFileStatus returns the processing state and metadata of all files
+// matching the request.
+func (r rvServer) ListFileStatus(ctx context.Context, req *pb.ListFileStatusRequest) (*pb.ListFileStatusResponse, error) {
+	q := &metadata.Query{
+		Prefix: req.GetPrefix(),
+		Limit:  int(req.GetLimit()),
+	}
+	if req.GetProject() != pb.FileRequest_UNKNOWN {
+		q.Project = req.GetProject().String()
+	}
+	if req.GetStatus() != pb.FileStatus_UNKNOWN {
+		q.Status = metadata.Status(req.GetStatus().String())
+	}
+	recs, err := r.meta.List(ctx, q)
+	if err != nil {
+		return nil, newStorageError(r.conf.MetadataDB, err, "failed to list metadata")
+	}
+	resp := &pb.ListFileStatusResponse{}
+	for _, rec := range recs {
+		resp.Files = append(resp.Files, fileStatus(rec))
+	}
+	return resp, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// TestFileStatus tests that uploads are tracked and reported by the status RPCs.
+func TestFileStatus(t *testing.T) {
+	for _, db := range []string{"", "metadata.db"} {
+		t.Run("metadata_db="+db, func(t *testing.T) {
+			ctx := context.Background()
+			srv := fakestorage.NewServer(nil)
+			t.Cleanup(srv.Stop)
+			srv.CreateBucket("foo")
+			srv.CreateBucket("baz")
+			conf := &config{
+				Projects: map[string]*projectConfig{
+					pb.FileRequest_ROUTEVIEWS.String(): {Bucket: "foo"},
+					// Records are keyed by the stored name of files.
+					pb.FileRequest_ROUTEVIEWS_RIB.String(): {Bucket: "baz", PathPrefix: "{{.Collector}}/"},
+				},
+			}
+			if db != "" {
+				conf.MetadataDB = filepath.Join(t.TempDir(), db)
+			}
+			fs, err := newRVServer(ctx, createConf(t, conf), srv.Client())
+			if err != nil {
+				t.Fatalf("failed initialzing server: %v", err)
+			}
+			t.Cleanup(func() { fs.meta.Close() })
+			client := startTestServer(t, fs)
+
+			for _, req := range []*pb.FileRequest{{
+				Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Content:  []byte("Foo Bar Baz"),
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			}, {
+				Filename: "bgpdata/2021.11/RIBS/rib.20211101.0000.bz2",
+				Md5Sum:   "50e3903156f5d2dac6c9f89626d48c75",
+				Content:  []byte("Foo Bar Baz"),
+				Project:  pb.FileRequest_ROUTEVIEWS_RIB,
+			}} {
+				if _, err := client.FileUpload(ctx, req); err != nil {
+					t.Fatalf("FileUpload(%s): %v", req.GetFilename(), err)
+				}
+			}
+
+			got, err := client.GetFileStatus(ctx, &pb.FileStatusRequest{
+				Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+				Project:  pb.FileRequest_ROUTEVIEWS,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &pb.FileStatus{
+				Status:      pb.FileStatus_STORED,
+				Filename:    "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+				Project:     pb.FileRequest_ROUTEVIEWS,
+				Collector:   "route-views2",
+				ContentType: "text/plain; charset=utf-8",
+			}
+			if diff := cmp.Diff(want, got, protocmp.Transform(), protocmp.IgnoreFields(&pb.FileStatus{}, "updated")); diff != "" {
+				t.Errorf("GetFileStatus() diff (-want +got):\n%s", diff)
+			}
+			if got.GetUpdated() == nil {
+				t.Error("GetFileStatus() has no updated time")
+			}
+
+			if _, err := client.GetFileStatus(ctx, &pb.FileStatusRequest{Filename: "missing", Project: pb.FileRequest_ROUTEVIEWS}); status.Code(err) != codes.NotFound {
+				t.Errorf("GetFileStatus(missing) = %v; want NotFound", err)
+			}
+			if _, err := client.GetFileStatus(ctx, &pb.FileStatusRequest{Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", Project: pb.FileRequest_ROUTEVIEWS_RIB}); status.Code(err) != codes.NotFound {
+				t.Errorf("GetFileStatus() of another project = %v; want NotFound", err)
+			}
+			if _, err := client.GetFileStatus(ctx, &pb.FileStatusRequest{Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"}); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("GetFileStatus() without a project = %v; want InvalidArgument", err)
+			}
+			rib, err := client.GetFileStatus(ctx, &pb.FileStatusRequest{Filename: "bgpdata/2021.11/RIBS/rib.20211101.0000.bz2", Project: pb.FileRequest_ROUTEVIEWS_RIB})
+			if err != nil || rib.GetStatus() != pb.FileStatus_STORED {
+				t.Errorf("GetFileStatus() of a prefixed file = %v, %v; want %v", rib, err, pb.FileStatus_STORED)
+			}
+
+			list, err := client.ListFileStatus(ctx, &pb.ListFileStatusRequest{
+				Project: pb.FileRequest_ROUTEVIEWS_RIB,
+				Status:  pb.FileStatus_STORED,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list.GetFiles()) != 1 || list.GetFiles()[0].GetFilename() != "bgpdata/2021.11/RIBS/rib.20211101.0000.bz2" {
+				t.Errorf("ListFileStatus() = %v; want only the RIB file", list.GetFiles())
+			}
+		})
+	}
+}
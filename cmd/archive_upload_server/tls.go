@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// tlsConfig sets the certificate the server presents, and the CAs of client
+// certificates (mTLS), so the server can run without a load balancer in
+// front of it.
+type tlsConfig struct {
+	// CertFile and KeyFile are the PEM certificate and key of the server.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCAFile is a PEM bundle of the CAs signing client certificates.
+	// If set, clients presenting a certificate are verified, and identified
+	// by it.
+	ClientCAFile string `yaml:"client_ca_file"`
+	// RequireClientCert rejects clients without a certificate signed by one
+	// of ClientCAFile.
+	RequireClientCert bool `yaml:"require_client_cert"`
+}
+
+// newReloader checks the TLS config, and returns the reloader of its files.
+func (c *tlsConfig) newReloader() (*certReloader, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, fmt.Errorf("tls requires a cert_file and a key_file")
+	}
+	if c.RequireClientCert && c.ClientCAFile == "" {
+		return nil, fmt.Errorf("tls requires a client_ca_file to require client certificates")
+	}
+	l := &certReloader{conf: c}
+	if err := l.reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// certReloader holds the server certificate and client CAs of a tlsConfig,
+// and loads them again when their files change, so certificates can be
+// renewed without a restart.
+type certReloader struct {
+	conf *tlsConfig
+
+	mu sync.Mutex
+	// mod are the modification times of the files loaded.
+	mod  []time.Time
+	cert *tls.Certificate
+	cas  *x509.CertPool
+}
+
+func (l *certReloader) files() []string {
+	fs := []string{l.conf.CertFile, l.conf.KeyFile}
+	if l.conf.ClientCAFile != "" {
+		fs = append(fs, l.conf.ClientCAFile)
+	}
+	return fs
+}
+
+// modTimes returns the modification times of the files, or nil if one
+// cannot be read.
+func (l *certReloader) modTimes() []time.Time {
+	var ts []time.Time
+	for _, f := range l.files() {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return nil
+		}
+		ts = append(ts, fi.ModTime())
+	}
+	return ts
+}
+
+// reload loads the files. It must be called with mu held, or before the
+// reloader is shared.
+func (l *certReloader) reload() error {
+	mod := l.modTimes()
+	cert, err := tls.LoadX509KeyPair(l.conf.CertFile, l.conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load server certificate: %v", err)
+	}
+	var cas *x509.CertPool
+	if l.conf.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(l.conf.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CAs: %v", err)
+		}
+		cas = x509.NewCertPool()
+		if !cas.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in client CAs %s", l.conf.ClientCAFile)
+		}
+	}
+	l.mod, l.cert, l.cas = mod, &cert, cas
+	return nil
+}
+
+// current returns the certificate and client CAs, loaded again if their
+// files changed. The previous ones are kept if loading fails, ie: while
+// the files are being replaced.
+func (l *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	mod := l.modTimes()
+	changed := len(mod) != len(l.mod)
+	for i := 0; !changed && i < len(mod); i++ {
+		changed = !mod[i].Equal(l.mod[i])
+	}
+	if changed && mod != nil {
+		if err := l.reload(); err != nil {
+			glog.Errorf("Failed to reload TLS files, keeping the previous ones: %v", err)
+		} else {
+			glog.Infof("Reloaded TLS files %v", l.files())
+		}
+	}
+	return l.cert, l.cas
+}
+
+// config returns a TLS config negotiating nextProtos, whose certificate and
+// client CAs are current as of each handshake.
+func (l *certReloader) config(nextProtos ...string) *tls.Config {
+	clientAuth := tls.NoClientCert
+	switch {
+	case l.conf.RequireClientCert:
+		clientAuth = tls.RequireAndVerifyClientCert
+	case l.conf.ClientCAFile != "":
+		clientAuth = tls.VerifyClientCertIfGiven
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := l.current()
+			return cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, cas := l.current()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    cas,
+				ClientAuth:   clientAuth,
+			}, nil
+		},
+	}
+}
+
+// certIdentity returns the identity of a caller's verified client
+// certificate: its first email address, or else its common name.
+func certIdentity(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	ti, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(ti.State.VerifiedChains) == 0 || len(ti.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	cert := ti.State.VerifiedChains[0][0]
+	if len(cert.EmailAddresses) > 0 {
+		return cert.EmailAddresses[0], true
+	}
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName, true
+	}
+	return "", false
+}
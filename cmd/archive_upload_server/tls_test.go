@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/routeviews/google-cloud-storage/pkg/auth"
+	"github.com/routeviews/google-cloud-storage/pkg/auth/authtest"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startTLSServer serves fs over TLS on a local port, and returns its
+// address, named localhost to match the server certificate.
+func startTLSServer(t *testing.T, fs *rvServer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := append(fs.interceptors(), grpc.Creds(credentials.NewTLS(fs.certs.config("h2"))))
+	s := grpc.NewServer(opts...)
+	pb.RegisterRVServer(s, fs)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	return net.JoinHostPort("localhost", port)
+}
+
+// dialTLS returns a client of addr over TLS set by o.
+func dialTLS(t *testing.T, addr string, o *auth.TLSOptions) pb.RVClient {
+	t.Helper()
+	conn, err := auth.NewTLSConn(addr, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewRVClient(conn)
+}
+
+func TestMTLS(t *testing.T) {
+	const aud = "https://rv-server.example.com"
+	iss := authtest.NewIssuer(t, "https://accounts.google.com")
+	ca := authtest.NewCA(t, "RV clients")
+	other := authtest.NewCA(t, "Elsewhere")
+	certFile, keyFile := ca.Issue(t, "localhost")
+	content := []byte("Hello, RouteViews!")
+	sum := md5.Sum(content)
+
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	srv.CreateBucket("bar")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Buckets: map[string]string{
+			pb.FileRequest_ROUTEVIEWS.String(): "foo",
+			pb.FileRequest_RPKI_RARC.String():  "bar",
+		},
+		TLS: &tlsConfig{
+			CertFile:     certFile,
+			KeyFile:      keyFile,
+			ClientCAFile: ca.CertFile,
+		},
+		Auth: &authConfig{
+			JWKSURL:  iss.JWKSURL,
+			Audience: aud,
+			Allow: map[string][]string{
+				"collector@example.com": {pb.FileRequest_ROUTEVIEWS.String()},
+				"uploader@example.com":  {pb.FileRequest_ROUTEVIEWS.String()},
+			},
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	addr := startTLSServer(t, fs)
+
+	tests := []struct {
+		desc string
+		// cert is the name of the client certificate, issued by ca, or by
+		// other if untrusted.
+		cert      string
+		untrusted bool
+		token     string
+		proj      pb.FileRequest_Project
+		want      codes.Code
+	}{{
+		desc: "allowed certificate",
+		cert: "collector@example.com",
+		proj: pb.FileRequest_ROUTEVIEWS,
+		want: codes.OK,
+	}, {
+		desc: "certificate not allowed the project",
+		cert: "collector@example.com",
+		proj: pb.FileRequest_RPKI_RARC,
+		want: codes.PermissionDenied,
+	}, {
+		desc: "unknown certificate",
+		cert: "stranger@example.com",
+		proj: pb.FileRequest_ROUTEVIEWS,
+		want: codes.PermissionDenied,
+	}, {
+		// Clients only present certificates of the CAs the server accepts.
+		desc:      "untrusted certificate",
+		cert:      "collector@example.com",
+		untrusted: true,
+		proj:      pb.FileRequest_ROUTEVIEWS,
+		want:      codes.Unauthenticated,
+	}, {
+		desc:  "token without certificate",
+		token: iss.Token(t, "uploader@example.com", aud),
+		proj:  pb.FileRequest_ROUTEVIEWS,
+		want:  codes.OK,
+	}, {
+		desc: "no certificate or token",
+		proj: pb.FileRequest_ROUTEVIEWS,
+		want: codes.Unauthenticated,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			o := &auth.TLSOptions{CAFile: ca.CertFile}
+			if test.cert != "" {
+				issuer := ca
+				if test.untrusted {
+					issuer = other
+				}
+				o.CertFile, o.KeyFile = issuer.Issue(t, test.cert)
+			}
+			client := dialTLS(t, addr, o)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if test.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+test.token)
+			}
+			_, err := client.FileUpload(ctx, &pb.FileRequest{
+				Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+				Md5Sum:   hex.EncodeToString(sum[:]),
+				Content:  content,
+				Project:  test.proj,
+			})
+			if got := status.Code(err); got != test.want {
+				t.Errorf("FileUpload() code = %v; want %v (%v)", got, test.want, err)
+			}
+		})
+	}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	ca := authtest.NewCA(t, "RV clients")
+	certFile, keyFile := ca.Issue(t, "localhost")
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Buckets: map[string]string{pb.FileRequest_ROUTEVIEWS.String(): "foo"},
+		TLS: &tlsConfig{
+			CertFile:          certFile,
+			KeyFile:           keyFile,
+			ClientCAFile:      ca.CertFile,
+			RequireClientCert: true,
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	addr := startTLSServer(t, fs)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req := &pb.FileStatusRequest{Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", Project: pb.FileRequest_ROUTEVIEWS}
+
+	client := dialTLS(t, addr, &auth.TLSOptions{CAFile: ca.CertFile})
+	if _, err := client.GetFileStatus(ctx, req); status.Code(err) != codes.Unavailable {
+		t.Errorf("GetFileStatus() without a client certificate = %v; want code %v", err, codes.Unavailable)
+	}
+
+	o := &auth.TLSOptions{CAFile: ca.CertFile}
+	o.CertFile, o.KeyFile = ca.Issue(t, "collector@example.com")
+	client = dialTLS(t, addr, o)
+	if _, err := client.GetFileStatus(ctx, req); status.Code(err) != codes.NotFound {
+		t.Errorf("GetFileStatus() with a client certificate = %v; want code %v", err, codes.NotFound)
+	}
+}
+
+func TestCertReload(t *testing.T) {
+	ca := authtest.NewCA(t, "RV servers")
+	certFile, keyFile := ca.Issue(t, "localhost")
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Buckets: map[string]string{pb.FileRequest_ROUTEVIEWS.String(): "foo"},
+		TLS:     &tlsConfig{CertFile: certFile, KeyFile: keyFile},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	addr := startTLSServer(t, fs)
+	tc, err := (&auth.TLSOptions{CAFile: ca.CertFile}).Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.NextProtos = []string{"h2"}
+	serial := func() int64 {
+		t.Helper()
+		conn, err := tls.Dial("tcp", addr, tc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	first := serial()
+	newCert, newKey := ca.Issue(t, "localhost")
+	// Replace the files in place, as certificate renewals do, dated later
+	// than the files loaded.
+	later := time.Now().Add(time.Minute)
+	for src, dst := range map[string]string{newCert: certFile, newKey: keyFile} {
+		b, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dst, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(dst, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := serial(); got == first {
+		t.Errorf("certificate serial %d after renewal; want a new certificate", got)
+	}
+
+	// A bad certificate is not loaded; the last good one is kept.
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(certFile, later.Add(time.Minute), later.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got := serial(); got == first {
+		t.Errorf("certificate serial %d after a bad renewal; want the renewed certificate", got)
+	}
+}
+
+// TestGatewayMTLS tests uploads over HTTP identified by client certificates.
+func TestGatewayMTLS(t *testing.T) {
+	ca := authtest.NewCA(t, "RV clients")
+	certFile, keyFile := ca.Issue(t, "localhost")
+	content := []byte("Foo Bar Baz")
+	sum := md5.Sum(content)
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	fs, err := newRVServer(context.Background(), createConf(t, &config{
+		Buckets: map[string]string{pb.FileRequest_ROUTEVIEWS.String(): "foo"},
+		TLS: &tlsConfig{
+			CertFile:     certFile,
+			KeyFile:      keyFile,
+			ClientCAFile: ca.CertFile,
+		},
+		Auth: &authConfig{
+			Allow: map[string][]string{
+				"collector@example.com": {pb.FileRequest_ROUTEVIEWS.String()},
+			},
+		},
+	}), srv.Client())
+	if err != nil {
+		t.Fatalf("failed initialzing server: %v", err)
+	}
+	gw := httptest.NewUnstartedServer(fs.gateway())
+	gw.TLS = fs.certs.config("h2", "http/1.1")
+	gw.StartTLS()
+	t.Cleanup(gw.Close)
+	_, port, _ := net.SplitHostPort(gw.Listener.Addr().String())
+	url := "https://" + net.JoinHostPort("localhost", port) + "/v1/ROUTEVIEWS/route-views2/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+
+	tests := []struct {
+		desc string
+		cert string
+		want int
+	}{{
+		desc: "allowed certificate",
+		cert: "collector@example.com",
+		want: http.StatusCreated,
+	}, {
+		desc: "unknown certificate",
+		cert: "stranger@example.com",
+		want: http.StatusForbidden,
+	}, {
+		desc: "no certificate",
+		want: http.StatusUnauthorized,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			o := &auth.TLSOptions{CAFile: ca.CertFile}
+			if test.cert != "" {
+				o.CertFile, o.KeyFile = ca.Issue(t, test.cert)
+			}
+			tc, err := o.Config()
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tc}}
+			req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.want {
+				t.Errorf("PUT %s = %s; want %d", url, resp.Status, test.want)
+			}
+		})
+	}
+}
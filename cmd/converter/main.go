@@ -2,26 +2,38 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 
+	"github.com/routeviews/google-cloud-storage/pkg/events"
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
 	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
 	log "github.com/sirupsen/logrus"
 
 	"cloud.google.com/go/storage"
 )
 
-var isDebug = flag.Bool("debug", false, "Debug mode - more verbose logging.")
+var (
+	isDebug = flag.Bool("debug", false, "Debug mode - more verbose logging.")
+	metaDB  = flag.String("metadata_db", "", "Metadata store which tracks the state of archives, none if empty: a gs://, s3:// or file:// bucket shared with the upload server, or a local database file.")
+)
 
 type server struct {
-	gcsCli    *storage.Client
-	dstBucket string
+	gcsCli *storage.Client
+	// dst receives converted archives.
+	dst  objstore.ObjectStore
+	meta metadata.Store
+	// format is the format of converted updates and RIB entries.
+	format converter.Format
 }
 
+// newServer returns a server converting archives to dstBucket, which is a GCS
+// bucket name or any location supported by objstore.Open.
 func newServer(ctx context.Context, cli *storage.Client, dstBucket string) (*server, error) {
 	if dstBucket == "" {
 		return nil, fmt.Errorf("destination bucket is not specified")
@@ -29,71 +41,70 @@ func newServer(ctx context.Context, cli *storage.Client, dstBucket string) (*ser
 	if cli == nil {
 		return nil, fmt.Errorf("nil GCS client")
 	}
+	dst, err := objstore.Open(dstBucket, &objstore.Clients{GCS: cli})
+	if err != nil {
+		return nil, fmt.Errorf("bad destination bucket %s: %v", dstBucket, err)
+	}
 	return &server{
-		gcsCli:    cli,
-		dstBucket: dstBucket,
+		gcsCli: cli,
+		dst:    dst,
 	}, nil
 }
 
-type gcsPubSubEvent struct {
-	Message struct {
-		Attributes struct {
-			Bucket    string `json:"bucketId"`
-			Object    string `json:"objectId"`
-			EventType string `json:"eventType"`
-		} `json:"attributes,omitempty"`
-		MessageID string `json:"messageId"`
-	} `json:"message"`
-	Subscription string `json:"subscription"`
-}
-
-// archiveUploadHandler handles any new object changes from the archive bucket.
-// It will not return an HTTP error because all errrors are fatal and should
-// not be retried.
+// archiveUploadHandler handles the archive stored events of the upload
+// server, posted directly or through Pub/Sub. Other events, ie: the
+// notifications of the archive bucket, are skipped so each archive is
+// converted once. It will not return an HTTP error because all errrors are
+// fatal and should not be retried.
 func (s *server) archiveUploadHandler(w http.ResponseWriter, r *http.Request) {
-	var msg gcsPubSubEvent
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		log.Errorf("ioutil.ReadAll: %v", err)
 		return
 	}
 
-	if err := json.Unmarshal(body, &msg); err != nil {
-		log.Infof("json.Unmarshal: %v", err)
+	ev, err := events.Unmarshal(body)
+	if err != nil {
+		log.Infof("events.Unmarshal: %v", err)
 		return
 	}
-
-	// The archive server will set metadata of project source after the object
-	// is created, so we will look for metadata update messages instead of
-	// object creations.
-	if msg.Message.Attributes.EventType != "OBJECT_METADATA_UPDATE" {
-		log.Infof("Skipped non-'OBJECT_METADATA_UPDATE' msg: id %s, type %s", msg.Message.MessageID, msg.Message.Attributes.EventType)
+	if ev == nil {
+		log.Infof("Skipped event which is not %s", events.ArchiveStoredType)
+		return
+	}
+	bucket, object := ev.Bucket, ev.Object
+	src, err := objstore.Open(bucket, &objstore.Clients{GCS: s.gcsCli})
+	if err != nil {
+		log.Errorf("bad source bucket %s: %v", bucket, err)
 		return
 	}
 
 	log.WithFields(log.Fields{
-		"bucket":    msg.Message.Attributes.Bucket,
-		"object":    msg.Message.Attributes.Object,
-		"messageID": msg.Message.MessageID,
+		"bucket":     bucket,
+		"object":     object,
+		"generation": ev.Generation,
 	}).Info("Converting archive")
-	err = converter.ProcessMRTArchive(r.Context(), s.gcsCli, &converter.Config{
-		SrcBucket: msg.Message.Attributes.Bucket,
-		SrcObject: msg.Message.Attributes.Object,
-		DstBucket: s.dstBucket,
+	err = converter.ProcessMRTArchive(r.Context(), &converter.Config{
+		Src:       src,
+		SrcObject: object,
+		Project:   ev.Project,
+		Dst:       s.dst,
+		Metadata:  s.meta,
+		Format:    s.format,
 	})
 	if err != nil {
 		log.WithFields(log.Fields{
-			"dstBucket": s.dstBucket,
-			"object":    msg.Message.Attributes.Object,
+			"dst":    s.dst.URL(""),
+			"object": object,
 		}).Errorf("converter.ProcessMRTArchive: %v", err)
 		w.Write([]byte(fmt.Sprintf("converter.ProcessMRTArchive: %v", err)))
 		return
 	}
 	log.WithFields(log.Fields{
-		"bucket":    msg.Message.Attributes.Bucket,
-		"dstBucket": s.dstBucket,
-		"object":    msg.Message.Attributes.Object,
-		"messageID": msg.Message.MessageID,
+		"bucket":     bucket,
+		"dst":        s.dst.URL(""),
+		"object":     object,
+		"generation": ev.Generation,
 	}).Info("Archive converted")
 }
 
@@ -119,8 +130,18 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if srvr.format, err = converter.ParseFormat(os.Getenv("OUTPUT_FORMAT")); err != nil {
+		log.Fatal(err)
+	}
+	if *metaDB != "" {
+		if srvr.meta, err = metadata.Open(*metaDB, &objstore.Clients{GCS: cli}); err != nil {
+			log.Fatal(err)
+		}
+		defer srvr.meta.Close()
+	}
 
 	http.HandleFunc("/", srvr.archiveUploadHandler)
+	http.Handle(metrics.Path, metrics.Handler())
 	log.Printf("Listening on port %s", port)
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		log.Fatal(err)
@@ -18,7 +18,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/osrg/gobgp/pkg/packet/bgp"
 	"github.com/osrg/gobgp/pkg/packet/mrt"
+	"github.com/routeviews/google-cloud-storage/pkg/events"
 	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
 )
 
@@ -45,6 +47,32 @@ func makeFakeMsgFormat(reason, object, bucket string) string {
 	return fmt.Sprintf(pubsubMsgFormat, reason, bucket, object, bucket)
 }
 
+// makeFakeEvent returns the JSON of the stored event of object, and the
+// same event wrapped in a Pub/Sub push message.
+func makeFakeEvent(t *testing.T, object, bucket string) (string, string) {
+	ev, err := json.Marshal(&events.ArchiveStored{
+		Type:    events.ArchiveStoredType,
+		Bucket:  bucket,
+		Object:  object,
+		Project: pb.FileRequest_ROUTEVIEWS.String(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"attributes": map[string]string{"eventType": events.ArchiveStoredType, "bucketId": bucket, "objectId": object},
+			"data":       ev,
+			"messageId":  "3510957425154222",
+		},
+		"subscription": "projects/fake-project/subscriptions/archive-stored",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(ev), string(msg)
+}
+
 func TestNewServer(t *testing.T) {
 	ctx := context.Background()
 	t.Run("dest bucket not specified", func(t *testing.T) {
@@ -94,6 +122,26 @@ func makeFakeCompressedMRT(t *testing.T, body mrt.Body) []byte {
 }
 
 func TestArchiveUploadHandle(t *testing.T) {
+	storedEvent, storedMsg := makeFakeEvent(t, "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2", "src-bucket")
+	stored := []fakestorage.Object{
+		{
+			ObjectAttrs: fakestorage.ObjectAttrs{
+				BucketName: "src-bucket",
+				Name:       "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2",
+				Metadata: map[string]string{
+					converter.ProjectMetadataKey: pb.FileRequest_ROUTEVIEWS.String(),
+				},
+			},
+			Content: makeFakeCompressedMRT(t, mrt.NewBGP4MPMessage(100000, 6447, 0, "1.0.0.0", "2.0.0.0", true, bgp.NewBGPUpdateMessage(nil, nil, []*bgp.IPAddrPrefix{
+				bgp.NewIPAddrPrefix(24, "10.0.0.0"),
+			}))),
+		},
+	}
+	archive := "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2"
+	unparsable := []fakestorage.Object{stored[0]}
+	unparsable[0].Content = []byte{1, 2, 3, 4}
+	noProject := []fakestorage.Object{stored[0]}
+	noProject[0].Metadata = map[string]string{}
 	tests := []struct {
 		desc        string
 		pubsubMsg   string
@@ -105,68 +153,46 @@ func TestArchiveUploadHandle(t *testing.T) {
 			pubsubMsg: "bad JSON pubsub message",
 		},
 		{
-			desc:      "skipped because it's not a OBJECT_METADATA_UPDATE",
-			pubsubMsg: makeFakeMsgFormat("OBJECT_DELETE", "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2", "src-bucket"),
+			// Archives are only converted once, on their stored event.
+			desc:        "skipped object created",
+			pubsubMsg:   makeFakeMsgFormat("OBJECT_FINALIZE", archive, "src-bucket"),
+			fakeobjects: stored,
+		},
+		{
+			desc:        "skipped metadata update",
+			pubsubMsg:   makeFakeMsgFormat("OBJECT_METADATA_UPDATE", archive, "src-bucket"),
+			fakeobjects: stored,
 		},
 		{
 			desc:      "object doesn't exist",
-			pubsubMsg: makeFakeMsgFormat("OBJECT_METADATA_UPDATE", "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2", "src-bucket"),
+			pubsubMsg: storedEvent,
 		},
 		{
-			desc:      "object can't be parsed",
-			pubsubMsg: makeFakeMsgFormat("OBJECT_METADATA_UPDATE", "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2", "src-bucket"),
-			fakeobjects: []fakestorage.Object{
-				{
-					ObjectAttrs: fakestorage.ObjectAttrs{
-						BucketName: "src-bucket",
-						Name:       "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2",
-						Metadata: map[string]string{
-							converter.ProjectMetadataKey: pb.FileRequest_ROUTEVIEWS.String(),
-						},
-					},
-					Content: []byte{1, 2, 3, 4},
-				},
-			},
+			desc:        "object can't be parsed",
+			pubsubMsg:   storedEvent,
+			fakeobjects: unparsable,
 			// This file will be created but empty.
 			dstObjects: []string{
 				"gs://dst-bucket/route-views4/bgpdata/updates/2021.12/updates.20211212.0015.gz",
 			},
 		},
 		{
-			desc:      "missing metadata",
-			pubsubMsg: makeFakeMsgFormat("OBJECT_METADATA_UPDATE", "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2", "src-bucket"),
-			fakeobjects: []fakestorage.Object{
-				{
-					ObjectAttrs: fakestorage.ObjectAttrs{
-						BucketName: "src-bucket",
-						Name:       "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2",
-						Metadata:   map[string]string{},
-					},
-					Content: makeFakeCompressedMRT(t, mrt.NewBGP4MPMessage(100000, 6447, 0, "1.0.0.0", "2.0.0.0", true, bgp.NewBGPUpdateMessage(nil, nil, []*bgp.IPAddrPrefix{
-						bgp.NewIPAddrPrefix(24, "10.0.0.0"),
-						bgp.NewIPAddrPrefix(24, "20.0.0.0"),
-					}))),
-				},
-			},
+			desc:        "missing metadata",
+			pubsubMsg:   storedEvent,
+			fakeobjects: noProject,
 		},
 		{
-			desc:      "success",
-			pubsubMsg: makeFakeMsgFormat("OBJECT_METADATA_UPDATE", "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2", "src-bucket"),
-			fakeobjects: []fakestorage.Object{
-				{
-					ObjectAttrs: fakestorage.ObjectAttrs{
-						BucketName: "src-bucket",
-						Name:       "route-views4/bgpdata/updates/2021.12/updates.20211212.0015.bz2",
-						Metadata: map[string]string{
-							converter.ProjectMetadataKey: pb.FileRequest_ROUTEVIEWS.String(),
-						},
-					},
-					Content: makeFakeCompressedMRT(t, mrt.NewBGP4MPMessage(100000, 6447, 0, "1.0.0.0", "2.0.0.0", true, bgp.NewBGPUpdateMessage(nil, nil, []*bgp.IPAddrPrefix{
-						bgp.NewIPAddrPrefix(24, "10.0.0.0"),
-						bgp.NewIPAddrPrefix(24, "20.0.0.0"),
-					}))),
-				},
+			desc:        "success - stored event",
+			pubsubMsg:   storedEvent,
+			fakeobjects: stored,
+			dstObjects: []string{
+				"gs://dst-bucket/route-views4/bgpdata/updates/2021.12/updates.20211212.0015.gz",
 			},
+		},
+		{
+			desc:        "success - stored event through Pub/Sub",
+			pubsubMsg:   storedMsg,
+			fakeobjects: stored,
 			dstObjects: []string{
 				"gs://dst-bucket/route-views4/bgpdata/updates/2021.12/updates.20211212.0015.gz",
 			},
@@ -179,8 +205,8 @@ func TestArchiveUploadHandle(t *testing.T) {
 			fakegcs.CreateBucketWithOpts(fakestorage.CreateBucketOpts{Name: "dst-bucket"})
 			t.Cleanup(fakegcs.Stop)
 			server := &server{
-				gcsCli:    fakegcs.Client(),
-				dstBucket: "dst-bucket",
+				gcsCli: fakegcs.Client(),
+				dst:    objstore.NewGCS(fakegcs.Client(), "dst-bucket"),
 			}
 
 			// Setup fake HTTP request.
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/audit"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+)
+
+var (
+	logLoc   = flag.String("log", "", "The audit log: a local JSONL file, or the gs:// or file:// bucket records are rolled into.")
+	filename = flag.String("filename", "", "Only records of this filename are printed, if set.")
+	since    = flag.String("since", "", "Only records from this RFC 3339 time on are printed, if set.")
+	until    = flag.String("until", "", "Only records before this RFC 3339 time are printed, if set.")
+)
+
+func parseTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		glog.Exitf("bad time %q: %v", s, err)
+	}
+	return t
+}
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+	if *logLoc == "" {
+		glog.Exit("-log is required")
+	}
+	q := &audit.Query{
+		Filename: *filename,
+		Since:    parseTime(*since),
+		Until:    parseTime(*until),
+	}
+	enc := json.NewEncoder(os.Stdout)
+	show := func(rec *audit.Record) error { return enc.Encode(rec) }
+
+	if !strings.Contains(*logLoc, "://") {
+		f, err := os.Open(*logLoc)
+		if err != nil {
+			glog.Exit(err)
+		}
+		defer f.Close()
+		if err := audit.Read(f, q, show); err != nil {
+			glog.Exit(err)
+		}
+		return
+	}
+	clients := &objstore.Clients{}
+	if strings.HasPrefix(*logLoc, "gs://") {
+		sc, err := storage.NewClient(ctx)
+		if err != nil {
+			glog.Exit(err)
+		}
+		clients.GCS = sc
+	}
+	st, err := objstore.Open(*logLoc, clients)
+	if err != nil {
+		glog.Exit(err)
+	}
+	if err := audit.ReadStore(ctx, st, q, show); err != nil {
+		glog.Exit(err)
+	}
+}
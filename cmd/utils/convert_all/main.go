@@ -5,20 +5,26 @@ import (
 	"flag"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/golang/glog"
-	"google.golang.org/api/iterator"
 
+	"github.com/routeviews/google-cloud-storage/pkg/events"
 	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
 )
 
 var (
 	srcBucket  = flag.String("src_bucket", "routeviews-archives", "GCS bucket that saves all raws MRT archives.")
-	dstBucket  = flag.String("dst_bucket", "routeviews-bigquery", "GCS bucket that saves all converted MRT archives.")
+	dstBucket  = flag.String("dst_bucket", "routeviews-bigquery", "Bucket that saves all converted MRT archives: a GCS bucket name, gs://, s3:// or file:// location.")
 	rootDir    = flag.String("root_dir", "", "The directory that the converter should traverse from the source bucket. Empty means the root of the bucket.")
 	numWorkers = flag.Int("num_workers", 4, "Number of concurrent workers to perform conversions.")
+
+	eventURL      = flag.String("event_url", "", "URL the archive stored events are posted to, ie: the converter. One of this and -event_topic is required.")
+	eventAudience = flag.String("event_audience", "", "Audience of the ID token sent along with events posted to -event_url, none if empty.")
+	eventTopic    = flag.String("event_topic", "", "Pub/Sub topic the archive stored events are published to, as projects/PROJECT/topics/TOPIC.")
 )
 
 const defaultDataSource = pb.FileRequest_ROUTEVIEWS
@@ -27,7 +33,9 @@ type conMgr struct {
 	ConJobs chan string
 }
 
-func newConMgr(ctx context.Context, sc *storage.Client, srcBkt, dstBkt string, w int) *conMgr {
+// newConMgr returns a manager of w workers requesting the conversion of
+// archives of src by publishing their events to pub.
+func newConMgr(ctx context.Context, src, dst objstore.ObjectStore, pub events.Publisher, w int) *conMgr {
 	m := &conMgr{
 		ConJobs: make(chan string),
 	}
@@ -41,34 +49,39 @@ func newConMgr(ctx context.Context, sc *storage.Client, srcBkt, dstBkt string, w
 				}
 
 				dstObject := strings.Replace(obj, filepath.Ext(obj), ".gz", 1)
-				if found, err := converter.ObjExists(ctx, sc, dstObject, dstBkt); err != nil {
+				if found, err := converter.ObjExists(ctx, dst, dstObject); err != nil {
 					// Will start conversion if we can't fetch the object.
 					glog.Errorf("ObjExists: %v", err)
 				} else if found {
-					glog.Infof("Skipped: converted archive gs://%s/%s already exists.", srcBkt, dstObject)
+					glog.Infof("Skipped: converted archive %s already exists.", dst.URL(dstObject))
 					continue
 				}
-				gcsObj := sc.Bucket(srcBkt).Object(obj)
-				attrs, err := gcsObj.Attrs(ctx)
+				attrs, err := src.Stat(ctx, obj)
 				if err != nil {
-					glog.Errorf("failed to get metadata of gs://%s/%s: %v", srcBkt, obj, err)
+					glog.Errorf("failed to get metadata of %s: %v", src.URL(obj), err)
 					continue
 				}
 				dataSource := attrs.Metadata[converter.ProjectMetadataKey]
 				if dataSource == "" {
-					glog.Warningf("gs://%s/%s doesn't have project metadata; set to %s", srcBkt, obj, defaultDataSource.String())
+					glog.Warningf("%s doesn't have project metadata; set to %s", src.URL(obj), defaultDataSource.String())
 					dataSource = defaultDataSource.String()
 				}
 
-				// Reset metadata to trigger conversion.
-				_, err = gcsObj.Update(ctx, storage.ObjectAttrsToUpdate{Metadata: map[string]string{
-					converter.ProjectMetadataKey: dataSource,
-				}})
+				err = pub.Publish(ctx, &events.ArchiveStored{
+					Type:       events.ArchiveStoredType,
+					Time:       time.Now().UTC(),
+					Bucket:     *srcBucket,
+					Object:     obj,
+					Generation: attrs.Generation,
+					Project:    dataSource,
+					Collector:  attrs.Metadata[converter.CollectorMetadataKey],
+					MRTType:    attrs.Metadata[converter.MRTTypeMetadataKey],
+				})
 				if err != nil {
-					glog.Errorf("failed to update metadata of gs://%s/%s: %v", srcBkt, obj, err)
+					glog.Errorf("failed to publish event of %s: %v", src.URL(obj), err)
 					continue
 				}
-				glog.Infof("Conversion request sent: gs://%s/%s", srcBkt, obj)
+				glog.Infof("Conversion event published: %s", src.URL(obj))
 			}
 		}()
 	}
@@ -84,17 +97,26 @@ func main() {
 		glog.Exit(err)
 	}
 
-	mgr := newConMgr(ctx, sc, *srcBucket, *dstBucket, *numWorkers)
-	query := &storage.Query{Prefix: *rootDir}
-	it := sc.Bucket(*srcBucket).Objects(ctx, query)
-	for {
-		attrs, err := it.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			glog.Fatal(err)
+	src := objstore.NewGCS(sc, *srcBucket)
+	dst, err := objstore.Open(*dstBucket, &objstore.Clients{GCS: sc})
+	if err != nil {
+		glog.Exit(err)
+	}
+	// The converter only converts archives on their stored events.
+	pub, err := events.NewPublisher(ctx, *eventURL, *eventAudience, *eventTopic)
+	if err != nil {
+		glog.Exit(err)
+	}
+	defer pub.Close()
+	mgr := newConMgr(ctx, src, dst, pub, *numWorkers)
+	err = src.List(ctx, *rootDir, func(attrs *objstore.Attrs) error {
+		if converter.Reserved(attrs.Name) {
+			return nil
 		}
 		mgr.ConJobs <- attrs.Name
+		return nil
+	})
+	if err != nil {
+		glog.Fatal(err)
 	}
 }
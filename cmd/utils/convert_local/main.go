@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"io"
 	"os"
 
 	"github.com/golang/glog"
@@ -12,10 +13,18 @@ var (
 	collector = flag.String("collector", "", "Collector name of this archive.")
 	archive   = flag.String("archive", "", "Path to the bz2 MRT archive.")
 	output    = flag.String("output", "", "Output path of the converted archive.")
+	format    = flag.String("format", "", "Format of the converted updates, v1 or v2; v1 if empty.")
+	stateOut  = flag.String("state_output", "", "Output path of the converted session state changes, dropped if empty.")
+	openOut   = flag.String("open_output", "", "Output path of the converted BGP OPENs, dropped if empty.")
+	notifOut  = flag.String("notification_output", "", "Output path of the converted BGP NOTIFICATIONs, dropped if empty.")
 )
 
 func main() {
 	flag.Parse()
+	outFormat, err := converter.ParseFormat(*format)
+	if err != nil {
+		glog.Exit(err)
+	}
 	src, err := os.Open(*archive)
 	if err != nil {
 		glog.Exit(err)
@@ -26,6 +35,22 @@ func main() {
 		glog.Exit(err)
 	}
 	defer dst.Close()
+	sessionDst := make(map[string]io.Writer)
+	for dir, out := range map[string]string{
+		converter.StateChangesDir:  *stateOut,
+		converter.OpensDir:         *openOut,
+		converter.NotificationsDir: *notifOut,
+	} {
+		if out == "" {
+			continue
+		}
+		f, err := os.Create(out)
+		if err != nil {
+			glog.Exit(err)
+		}
+		defer f.Close()
+		sessionDst[dir] = f
+	}
 
-	converter.Convert(*collector, src, dst)
+	converter.Convert(*collector, outFormat, src, dst, sessionDst)
 }
@@ -11,6 +11,8 @@ import (
 
 	datatransfer "cloud.google.com/go/bigquery/datatransfer/apiv1"
 	bqtransfer "github.com/routeviews/google-cloud-storage/pkg/bq_transfer"
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
 )
 
 var (
@@ -19,6 +21,8 @@ var (
 	dataset  = flag.String("dataset", "historical_routing_data", "Dataset that stores all routing updates.")
 	table    = flag.String("table", "updates", "Table that stores all routing updates.")
 	bucket   = flag.String("bucket", "routeviews-bigquery", "GCS bucket that saves all MRT archives.")
+	dir      = flag.String("dir", bqtransfer.DefaultDir, "Directory of each month to transfer, e.g. UPDATES or STATE_CHANGES, which should match -table.")
+	metaDB   = flag.String("metadata_db", "", "Metadata store which tracks the state of archives, none if empty: a gs://, s3:// or file:// bucket shared with the upload server, or a local database file.")
 )
 
 func main() {
@@ -38,6 +42,14 @@ func main() {
 	}
 	defer dc.Close()
 
+	var meta metadata.Store
+	if *metaDB != "" {
+		if meta, err = metadata.Open(*metaDB, &objstore.Clients{GCS: sc}); err != nil {
+			glog.Exit(err)
+		}
+		defer meta.Close()
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -51,6 +63,8 @@ func main() {
 			Dataset:  *dataset,
 			Table:    *table,
 			Bucket:   *bucket,
+			Dir:      *dir,
+			Metadata: meta,
 		}); err != nil {
 			glog.Error(err)
 			w.WriteHeader(http.StatusInternalServerError)
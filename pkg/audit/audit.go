@@ -0,0 +1,85 @@
+// Package audit records every upload to the archive: who sent which file,
+// from where and when, and how it ended.
+//
+// Records are written as JSON lines to a Sink. FileSink appends them to a
+// local file, StoreSink rolls them into objects of an audit bucket. Read
+// and ReadStore query the records back.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Record is the audit record of a single upload.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Method is the RPC of the upload, ie: FileUpload.
+	Method string `json:"method"`
+	// Caller is the email of the caller's ID token, empty if auth is not
+	// configured.
+	Caller string `json:"caller,omitempty"`
+	// Peer is the network address of the caller.
+	Peer     string `json:"peer,omitempty"`
+	Project  string `json:"project"`
+	Filename string `json:"filename"`
+	// Size is the number of content bytes received.
+	Size int64 `json:"size"`
+	// The checksums claimed by the upload, hex encoded.
+	MD5    string  `json:"md5,omitempty"`
+	SHA256 string  `json:"sha256,omitempty"`
+	CRC32C *uint32 `json:"crc32c,omitempty"`
+	// Result is the status of the FileResponse, ie: SUCCESS, or FAIL if the
+	// call failed.
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Sink receives audit records.
+type Sink interface {
+	// Write records rec. Records may be buffered until Close.
+	Write(ctx context.Context, rec *Record) error
+	// Close writes any buffered records, and releases the sink.
+	Close() error
+}
+
+// Query selects records to read. Empty fields match every record.
+type Query struct {
+	Filename string
+	// Records from Since (inclusive) to Until (exclusive).
+	Since time.Time
+	Until time.Time
+}
+
+func (q *Query) matches(rec *Record) bool {
+	return (q.Filename == "" || rec.Filename == q.Filename) &&
+		(q.Since.IsZero() || !rec.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || rec.Time.Before(q.Until))
+}
+
+// Read calls fn with each record of the JSON lines of r matching q, until
+// fn returns an error.
+func Read(r io.Reader, q *Query, fn func(*Record) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		rec := &Record{}
+		if err := json.Unmarshal(sc.Bytes(), rec); err != nil {
+			return fmt.Errorf("bad record on line %d: %v", line, err)
+		}
+		if !q.matches(rec) {
+			continue
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+)
+
+var (
+	t0      = time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	records = []*Record{
+		{Time: t0, Method: "FileUpload", Caller: "uploader@example.com", Peer: "192.0.2.1:4242", Project: "ROUTEVIEWS", Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", Size: 18, MD5: "50e3903156f5d2dac6c9f89626d48c75", Result: "SUCCESS"},
+		{Time: t0.Add(time.Minute), Method: "FileUpload", Project: "ROUTEVIEWS", Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0015.bz2", Size: 18, Result: "FAIL", Error: "checksum failure"},
+		{Time: t0.Add(2 * time.Minute), Method: "FileUploadStream", Project: "ROUTEVIEWS", Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", Size: 0, Result: "ALREADY_EXISTS"},
+	}
+)
+
+func TestSinks(t *testing.T) {
+	queries := []struct {
+		desc string
+		q    *Query
+		want []*Record
+	}{
+		{"all", &Query{}, records},
+		{"filename", &Query{Filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"}, []*Record{records[0], records[2]}},
+		{"since", &Query{Since: t0.Add(time.Minute)}, records[1:]},
+		{"until", &Query{Until: t0.Add(time.Minute)}, records[:1]},
+	}
+	// Each sink is opened with a function reading back its records.
+	type reader func(*Query, func(*Record) error) error
+	sinks := map[string]func(t *testing.T) (Sink, reader){
+		"file": func(t *testing.T) (Sink, reader) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			s, err := NewFileSink(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s, func(q *Query, fn func(*Record) error) error {
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				return Read(f, q, fn)
+			}
+		},
+		"store": func(t *testing.T) (Sink, reader) {
+			st, err := objstore.NewLocal(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Records are rolled every two records, and the rest on Close.
+			s, err := NewStoreSink(st, time.Hour, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s, func(q *Query, fn func(*Record) error) error {
+				return ReadStore(context.Background(), st, q, fn)
+			}
+		},
+	}
+	for name, open := range sinks {
+		t.Run(name, func(t *testing.T) {
+			s, read := open(t)
+			for _, rec := range records {
+				if err := s.Write(context.Background(), rec); err != nil {
+					t.Fatalf("Write(): %v", err)
+				}
+			}
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close(): %v", err)
+			}
+			for _, test := range queries {
+				var got []*Record
+				if err := read(test.q, func(rec *Record) error {
+					got = append(got, rec)
+					return nil
+				}); err != nil {
+					t.Fatalf("%s: read: %v", test.desc, err)
+				}
+				if diff := cmp.Diff(test.want, got); diff != "" {
+					t.Errorf("%s: records diff (-want +got):\n%s", test.desc, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestFileSinkAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for _, rec := range records {
+		s, err := NewFileSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Write(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+		s.Close()
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var n int
+	if err := Read(f, &Query{}, func(*Record) error { n++; return nil }); err != nil || n != len(records) {
+		t.Errorf("Read() = %d records, %v; want %d records", n, err, len(records))
+	}
+}
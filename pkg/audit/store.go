@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+)
+
+// StorePrefix holds the objects of a StoreSink, named by the date and time
+// of their first record: uploads/2006/01/02/150405.000000000-id.jsonl.
+const StorePrefix = "uploads/"
+
+const objectTimeLayout = "2006/01/02/150405.000000000"
+
+// StoreSink buffers records, and rolls them into a new JSONL object of a
+// store every interval, or once maxRecords are buffered. Objects are never
+// rewritten, so the log is append-only. Buffered records are lost if the
+// process dies before they are rolled.
+type StoreSink struct {
+	st         objstore.ObjectStore
+	maxRecords int
+	// id tells apart the objects of servers sharing the store.
+	id string
+
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	first time.Time
+	n     int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewStoreSink returns a sink rolling records into st.
+func NewStoreSink(st objstore.ObjectStore, interval time.Duration, maxRecords int) (*StoreSink, error) {
+	if interval <= 0 || maxRecords <= 0 {
+		return nil, fmt.Errorf("bad roll interval %v or max records %d", interval, maxRecords)
+	}
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	s := &StoreSink{
+		st:         st,
+		maxRecords: maxRecords,
+		id:         hex.EncodeToString(id),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go s.roll(interval)
+	return s, nil
+}
+
+func (s *StoreSink) roll(interval time.Duration) {
+	defer close(s.done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+			if err := s.Flush(context.Background()); err != nil {
+				glog.Errorf("Failed to roll audit records: %v", err)
+			}
+		}
+	}
+}
+
+// Write buffers rec, and rolls the buffer if it holds maxRecords.
+func (s *StoreSink) Write(ctx context.Context, rec *Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if s.n == 0 {
+		s.first = rec.Time.UTC()
+	}
+	s.buf.Write(append(b, '\n'))
+	s.n++
+	full := s.n >= s.maxRecords
+	s.mu.Unlock()
+	if full {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush writes the buffered records to a new object. The records stay
+// buffered if the write fails.
+func (s *StoreSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.n == 0 {
+		return nil
+	}
+	name := fmt.Sprintf("%s%s-%s.jsonl", StorePrefix, s.first.Format(objectTimeLayout), s.id)
+	if _, err := s.st.Put(ctx, name, bytes.NewReader(s.buf.Bytes()), &objstore.Attrs{ContentType: "application/x-ndjson"}); err != nil {
+		return fmt.Errorf("failed to write %s: %v", s.st.URL(name), err)
+	}
+	s.buf.Reset()
+	s.n = 0
+	return nil
+}
+
+// Close stops rolling, and writes the buffered records.
+func (s *StoreSink) Close() error {
+	close(s.stop)
+	<-s.done
+	return s.Flush(context.Background())
+}
+
+// ReadStore calls fn with each record of the objects of a StoreSink in st
+// matching q, in the order they were rolled, until fn returns an error.
+func ReadStore(ctx context.Context, st objstore.ObjectStore, q *Query, fn func(*Record) error) error {
+	return st.List(ctx, StorePrefix, func(attrs *objstore.Attrs) error {
+		// Objects are named by their first record, so later ones are skipped.
+		name := strings.TrimPrefix(attrs.Name, StorePrefix)
+		if i := strings.LastIndex(name, "-"); i >= 0 && !q.Until.IsZero() {
+			if first, err := time.Parse(objectTimeLayout, name[:i]); err == nil && !first.Before(q.Until) {
+				return nil
+			}
+		}
+		rd, err := st.Read(ctx, attrs.Name)
+		if err != nil {
+			return err
+		}
+		defer rd.Close()
+		if err := Read(rd, q, fn); err != nil {
+			return fmt.Errorf("%s: %v", st.URL(attrs.Name), err)
+		}
+		return nil
+	})
+}
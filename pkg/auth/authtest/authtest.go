@@ -0,0 +1,88 @@
+// Package authtest issues ID tokens signed by a local key, for tests of
+// their verification, and certificates of a local CA, for tests of TLS.
+package authtest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Issuer signs ID tokens with a local key, and serves the key at JWKSURL.
+type Issuer struct {
+	// Name is the iss claim of the tokens.
+	Name string
+	// JWKSURL serves the public key of the issuer.
+	JWKSURL string
+
+	key *rsa.PrivateKey
+	kid string
+}
+
+// NewIssuer returns an Issuer named name, whose key is served until the
+// test ends.
+func NewIssuer(t *testing.T, name string) *Issuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := &Issuer{Name: name, key: key, kid: "test-key"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": iss.kid,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	iss.JWKSURL = srv.URL
+	return iss
+}
+
+// Token returns a token for email and audience, which expires in an hour.
+func (i *Issuer) Token(t *testing.T, email, audience string) string {
+	t.Helper()
+	return i.Sign(t, map[string]interface{}{
+		"iss":            i.Name,
+		"sub":            email,
+		"aud":            audience,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"email":          email,
+		"email_verified": true,
+	})
+}
+
+// Sign returns a token with claims.
+func (i *Issuer) Sign(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	hdr, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": i.kid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(body)
+	h := sha256.Sum256([]byte(in))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return in + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"golang.org/x/oauth2"
@@ -18,7 +19,56 @@ import (
 // Set a max receive message size: 500mb
 const maxMsgSize = 512 * 1024 * 1024
 
+// TLSOptions set the TLS of connections to servers whose certificate is
+// not signed by a public CA, or which require client certificates (mTLS).
+type TLSOptions struct {
+	// CAFile is a PEM bundle of the CAs signing the server certificate,
+	// trusted instead of the system roots if set.
+	CAFile string
+	// CertFile and KeyFile are the PEM client certificate and key presented
+	// to the server, if set.
+	CertFile string
+	KeyFile  string
+}
+
+// Config returns the TLS config of o; a nil o trusts the system roots.
+func (o *TLSOptions) Config() (*tls.Config, error) {
+	if o == nil {
+		o = &TLSOptions{}
+	}
+	c := &tls.Config{}
+	if o.CAFile != "" {
+		pem, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in CA bundle %s", o.CAFile)
+		}
+	} else {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = roots
+	}
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	return c, nil
+}
+
 func NewAuthConn(ctx context.Context, host string, saPath string) (*grpc.ClientConn, error) {
+	return NewAuthConnTLS(ctx, host, saPath, nil)
+}
+
+// NewAuthConnTLS is NewAuthConn, over the TLS set by o.
+func NewAuthConnTLS(ctx context.Context, host string, saPath string, o *TLSOptions) (*grpc.ClientConn, error) {
 	var opts []grpc.DialOption
 
 	var idTokenSource oauth2.TokenSource
@@ -45,13 +95,11 @@ func NewAuthConn(ctx context.Context, host string, saPath string) (*grpc.ClientC
 
 	opts = append(opts, grpc.WithAuthority(host))
 
-	systemRoots, err := x509.SystemCertPool()
+	tc, err := o.Config()
 	if err != nil {
 		return nil, err
 	}
-	cred := credentials.NewTLS(&tls.Config{
-		RootCAs: systemRoots,
-	})
+	cred := credentials.NewTLS(tc)
 
 	opts = append(opts,
 		[]grpc.DialOption{
@@ -64,6 +112,19 @@ func NewAuthConn(ctx context.Context, host string, saPath string) (*grpc.ClientC
 	return grpc.Dial(host, opts...)
 }
 
+// NewTLSConn connects to host over the TLS set by o, without an ID token,
+// for servers which identify callers by their client certificate.
+func NewTLSConn(host string, o *TLSOptions) (*grpc.ClientConn, error) {
+	tc, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Dial(host,
+		grpc.WithTransportCredentials(credentials.NewTLS(tc)),
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(maxMsgSize)),
+	)
+}
+
 func InsecureConn(host string) (*grpc.ClientConn, error) {
 	return grpc.Dial(host,
 		grpc.WithInsecure(),
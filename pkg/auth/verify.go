@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// GoogleJWKSURL serves the keys which sign Google ID tokens.
+	GoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+	// Keys are fetched again after jwksMaxAge, or when a token is signed
+	// by an unknown key, at most once per jwksMinAge.
+	jwksMaxAge = time.Hour
+	jwksMinAge = time.Minute
+
+	// clockSkew is tolerated on the expiry of tokens.
+	clockSkew = 30 * time.Second
+)
+
+// GoogleIssuers are the issuers of Google ID tokens.
+var GoogleIssuers = []string{"https://accounts.google.com", "accounts.google.com"}
+
+// Claims are the verified claims of an ID token.
+type Claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	Expiry        int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+}
+
+// audience is the aud claim, either a string or a list of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+	var l []string
+	if err := json.Unmarshal(b, &l); err != nil {
+		return fmt.Errorf("bad aud claim: %v", err)
+	}
+	*a = l
+	return nil
+}
+
+// Verifier verifies RS256 signed ID tokens against the keys of a JWKS
+// endpoint.
+type Verifier struct {
+	jwksURL  string
+	issuers  []string
+	audience string
+	client   *http.Client
+	now      func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+// NewVerifier returns a Verifier of tokens issued by one of issuers for
+// audience, with the keys served at jwksURL.
+func NewVerifier(jwksURL string, issuers []string, audience string) *Verifier {
+	return &Verifier{
+		jwksURL:  jwksURL,
+		issuers:  issuers,
+		audience: audience,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+	}
+}
+
+// Verify checks the signature, issuer, audience and expiry of the raw
+// token, and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var hdr struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, fmt.Errorf("bad token header: %v", err)
+	}
+	if hdr.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", hdr.Alg)
+	}
+	key, err := v.key(ctx, hdr.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("bad token signature: %v", err)
+	}
+	h := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], sig); err != nil {
+		return nil, errors.New("invalid token signature")
+	}
+
+	c := &Claims{}
+	if err := decodeSegment(parts[1], c); err != nil {
+		return nil, fmt.Errorf("bad token claims: %v", err)
+	}
+	if !contains(v.issuers, c.Issuer) {
+		return nil, fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	if !contains(c.Audience, v.audience) {
+		return nil, fmt.Errorf("unexpected audience %q", c.Audience)
+	}
+	if exp := time.Unix(c.Expiry, 0); v.now().After(exp.Add(clockSkew)) {
+		return nil, fmt.Errorf("token expired at %s", exp.Format(time.RFC3339))
+	}
+	return c, nil
+}
+
+// key returns the public key kid, fetching the keys again if they are
+// stale or kid is unknown.
+func (v *Verifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	age := v.now().Sub(v.fetched)
+	if k, ok := v.keys[kid]; ok && age < jwksMaxAge {
+		return k, nil
+	}
+	if v.keys == nil || age >= jwksMinAge {
+		keys, err := fetchJWKS(ctx, v.client, v.jwksURL)
+		if err != nil {
+			return nil, err
+		}
+		v.keys, v.fetched = keys, v.now()
+	}
+	if k, ok := v.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// fetchJWKS returns the RSA keys served at url, by key ID.
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch keys: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch keys: %s", resp.Status)
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode keys: %v", err)
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("bad modulus of key %q: %v", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("bad exponent of key %q: %v", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
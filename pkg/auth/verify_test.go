@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/routeviews/google-cloud-storage/pkg/auth/authtest"
+)
+
+func TestVerify(t *testing.T) {
+	const aud = "https://rv-server.example.com"
+	iss := authtest.NewIssuer(t, "https://accounts.google.com")
+	other := authtest.NewIssuer(t, "https://accounts.google.com")
+	now := time.Now()
+
+	tests := []struct {
+		desc    string
+		token   string
+		want    string
+		wantErr string
+	}{{
+		desc:  "valid token",
+		token: iss.Token(t, "uploader@example.com", aud),
+		want:  "uploader@example.com",
+	}, {
+		desc: "audience in a list",
+		token: iss.Sign(t, map[string]interface{}{
+			"iss":   "https://accounts.google.com",
+			"aud":   []string{"other", aud},
+			"exp":   now.Add(time.Hour).Unix(),
+			"email": "uploader@example.com",
+		}),
+		want: "uploader@example.com",
+	}, {
+		desc:    "wrong audience",
+		token:   iss.Token(t, "uploader@example.com", "https://elsewhere.example.com"),
+		wantErr: "unexpected audience",
+	}, {
+		desc: "wrong issuer",
+		token: iss.Sign(t, map[string]interface{}{
+			"iss": "https://issuer.example.com",
+			"aud": aud,
+			"exp": now.Add(time.Hour).Unix(),
+		}),
+		wantErr: "unexpected issuer",
+	}, {
+		desc: "expired",
+		token: iss.Sign(t, map[string]interface{}{
+			"iss": "https://accounts.google.com",
+			"aud": aud,
+			"exp": now.Add(-time.Hour).Unix(),
+		}),
+		wantErr: "token expired",
+	}, {
+		desc:    "signed by another key",
+		token:   other.Token(t, "uploader@example.com", aud),
+		wantErr: "invalid token signature",
+	}, {
+		desc:    "tampered claims",
+		token:   strings.Replace(iss.Token(t, "uploader@example.com", aud), ".", ".e30", 1),
+		wantErr: "invalid token signature",
+	}, {
+		desc:    "malformed",
+		token:   "not-a-token",
+		wantErr: "malformed token",
+	}}
+
+	v := NewVerifier(iss.JWKSURL, GoogleIssuers, aud)
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := v.Verify(context.Background(), test.token)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Verify() = %v; want error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify(): %v", err)
+			}
+			if got.Email != test.want {
+				t.Errorf("Verify() email = %q; want %q", got.Email, test.want)
+			}
+		})
+	}
+}
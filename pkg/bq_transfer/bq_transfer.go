@@ -11,6 +11,8 @@ import (
 	"cloud.google.com/go/storage"
 	"github.com/cenkalti/backoff"
 	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
 	"google.golang.org/api/iterator"
 	"google.golang.org/protobuf/types/known/structpb"
 
@@ -20,12 +22,23 @@ import (
 
 var queryInterval = time.Second
 
+// DefaultDir is the directory of each month transferred if
+// TransferParams.Dir is empty: the updates, as the other directories hold
+// rows of other tables.
+const DefaultDir = "UPDATES"
+
 type TransferParams struct {
 	Project  string
 	Location string
 	Dataset  string
 	Table    string
 	Bucket   string
+	// Dir is the directory of the converted rows transferred in each month,
+	// e.g. UPDATES, RIBS or STATE_CHANGES, as each kind of row has a table
+	// of its own. DefaultDir if empty.
+	Dir string
+	// Metadata tracks the transfer state of the source archives, if set.
+	Metadata metadata.Store
 }
 
 // fetchMonthDirs traverse all directories and finds the month directories (i.e.
@@ -87,9 +100,23 @@ func fetchCoveredDirs(ctx context.Context, cli *datatransfer.Client, project str
 	return res, nil
 }
 
+// dir returns the directory transferred in each month.
+func (cfg *TransferParams) dir() string {
+	if cfg.Dir == "" {
+		return DefaultDir
+	}
+	return cfg.Dir
+}
+
+// subdir returns the prefix of the objects of cfg's directory in the month
+// directory month.
+func subdir(month string, cfg *TransferParams) string {
+	return strings.TrimSuffix(month, "/") + "/" + cfg.dir() + "/"
+}
+
 func makeTransferConfig(cfg *TransferParams, dir, pattern string) *dpb.TransferConfig {
 	return &dpb.TransferConfig{
-		DisplayName:  dir,
+		DisplayName:  strings.TrimSuffix(subdir(dir, cfg), "/"),
 		DataSourceId: "google_cloud_storage",
 		Destination: &dpb.TransferConfig_DestinationDatasetId{
 			DestinationDatasetId: cfg.Dataset,
@@ -112,13 +139,23 @@ func makeTransferConfig(cfg *TransferParams, dir, pattern string) *dpb.TransferC
 	}
 }
 
-func createTransferRuns(ctx context.Context, cli *datatransfer.Client, dirs []string, covered map[string]string, cfg *TransferParams) error {
+// createTransferRuns creates a transfer config for each directory not yet
+// covered, and returns the directories for which configs were created.
+func createTransferRuns(ctx context.Context, cli *datatransfer.Client, dirs []string, covered map[string]string, cfg *TransferParams) ([]string, error) {
+	var created []string
 	for _, dir := range dirs {
-		pattern := fmt.Sprintf("gs://%s/%s*/*.gz", cfg.Bucket, dir)
+		pattern := fmt.Sprintf("gs://%s/%s*.gz", cfg.Bucket, subdir(dir, cfg))
 		if cid, ok := covered[pattern]; ok {
 			glog.Warningf("Skipped: config %s is covering %s", cid, pattern)
 			continue
 		}
+		// Configs created before the month directories held other rows
+		// cover every directory of the month, the updates included.
+		all := fmt.Sprintf("gs://%s/%s*/*.gz", cfg.Bucket, dir)
+		if cid, ok := covered[all]; ok && cfg.dir() == DefaultDir {
+			glog.Warningf("Skipped: config %s is covering %s, along with the other directories of the month", cid, pattern)
+			continue
+		}
 		// Create a new transfer config if not found.
 		err := backoff.Retry(func() error {
 			resp, err := cli.CreateTransferConfig(ctx, &dpb.CreateTransferConfigRequest{
@@ -133,12 +170,42 @@ func createTransferRuns(ctx context.Context, cli *datatransfer.Client, dirs []st
 			return nil
 		}, backoff.WithMaxRetries(backoff.NewConstantBackOff(30*time.Second), 3))
 		if err != nil {
-			return err
+			return created, err
 		}
+		created = append(created, dir)
 
 		// Avoid exceeding DTS API access quota.
 		time.Sleep(queryInterval)
 	}
+	return created, nil
+}
+
+// markScheduled marks the source archives of all converted objects in the
+// cfg.Dir directories of dirs as scheduled for transfer.
+func markScheduled(ctx context.Context, cli *storage.Client, dirs []string, cfg *TransferParams) error {
+	for _, dir := range dirs {
+		prefix := subdir(dir, cfg)
+		it := cli.Bucket(cfg.Bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list gs://%s/%s: %v", cfg.Bucket, prefix, err)
+			}
+			src, ok := attrs.Metadata[converter.SourceObjectMetadataKey]
+			proj := attrs.Metadata[converter.SourceProjectMetadataKey]
+			if !ok || proj == "" {
+				continue
+			}
+			metadata.Track(ctx, cfg.Metadata, &metadata.Record{
+				Project: proj,
+				Object:  src,
+				Status:  metadata.StatusTransferScheduled,
+			})
+		}
+	}
 	return nil
 }
 
@@ -155,5 +222,11 @@ func Transfer(ctx context.Context, sc *storage.Client, dc *datatransfer.Client,
 	}
 	glog.Infof("Found %d month dirs, %d covered dirs", len(monthPrefixes), len(covered))
 
-	return createTransferRuns(ctx, dc, monthPrefixes, covered, params)
+	created, err := createTransferRuns(ctx, dc, monthPrefixes, covered, params)
+	if params.Metadata != nil {
+		if err := markScheduled(ctx, sc, created, params); err != nil {
+			glog.Errorf("markScheduled: %v", err)
+		}
+	}
+	return err
 }
@@ -0,0 +1,128 @@
+// Package events publishes the events of the archive: an ArchiveStored
+// event for each archive the upload server stores, which triggers its
+// conversion.
+//
+// Events go to a Publisher. HTTPPublisher posts them as JSON to a URL,
+// PubSubPublisher publishes them to a Pub/Sub topic, and Func hands them to
+// a function of the same process. Unmarshal decodes an event from either
+// form of push.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/idtoken"
+)
+
+// ArchiveStoredType is the type of ArchiveStored events, also set as the
+// eventType attribute of their Pub/Sub messages.
+const ArchiveStoredType = "ARCHIVE_STORED"
+
+// ArchiveStored is the event of an archive stored by the upload server.
+type ArchiveStored struct {
+	// Type is always ArchiveStoredType.
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// Bucket is the store of the archive: a GCS bucket name, or an s3:// or
+	// file:// location as accepted by objstore.Open.
+	Bucket     string `json:"bucket"`
+	Object     string `json:"object"`
+	Generation int64  `json:"generation,omitempty"`
+	Project    string `json:"project"`
+	// Collector and MRTType are derived from the archive, empty if unknown.
+	Collector string `json:"collector,omitempty"`
+	MRTType   string `json:"mrt_type,omitempty"`
+}
+
+// attributes returns the Pub/Sub attributes of ev, named after those of
+// GCS notifications so subscribers can filter on them.
+func (ev *ArchiveStored) attributes() map[string]string {
+	return map[string]string{
+		"eventType": ArchiveStoredType,
+		"bucketId":  ev.Bucket,
+		"objectId":  ev.Object,
+		"project":   ev.Project,
+	}
+}
+
+// Publisher sends events to their subscribers.
+type Publisher interface {
+	// Publish sends ev, returning once it is accepted.
+	Publish(ctx context.Context, ev *ArchiveStored) error
+	// Close releases the publisher.
+	Close() error
+}
+
+// NewPublisher returns the publisher to one of url, with an ID token of
+// audience if set, or topic.
+func NewPublisher(ctx context.Context, url, audience, topic string) (Publisher, error) {
+	switch {
+	case url != "" && topic != "":
+		return nil, fmt.Errorf("events require one of url and topic, not both")
+	case topic != "":
+		return NewPubSubPublisher(ctx, topic)
+	case url == "":
+		return nil, fmt.Errorf("events require a url or a topic")
+	case audience == "":
+		return NewHTTPPublisher(url, nil), nil
+	}
+	c, err := idtoken.NewClient(ctx, audience)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ID token client for %s: %v", audience, err)
+	}
+	return NewHTTPPublisher(url, c), nil
+}
+
+// Func publishes events by calling itself in-process, ie: to convert
+// archives in the same binary, or to record the events of tests.
+type Func func(ctx context.Context, ev *ArchiveStored) error
+
+func (f Func) Publish(ctx context.Context, ev *ArchiveStored) error {
+	return f(ctx, ev)
+}
+
+func (f Func) Close() error {
+	return nil
+}
+
+// pushMessage is the body of a Pub/Sub push request.
+type pushMessage struct {
+	Message struct {
+		Attributes map[string]string `json:"attributes"`
+		// Data is decoded from base64.
+		Data      []byte `json:"data"`
+		MessageID string `json:"messageId"`
+	} `json:"message"`
+}
+
+// Unmarshal decodes the ArchiveStored event of body, either posted as is by
+// an HTTPPublisher, or wrapped in a Pub/Sub push message. It returns nil if
+// body holds another event, ie: a GCS notification.
+func Unmarshal(body []byte) (*ArchiveStored, error) {
+	ev := &ArchiveStored{}
+	if err := json.Unmarshal(body, ev); err != nil {
+		return nil, err
+	}
+	if ev.Type == ArchiveStoredType {
+		return ev, nil
+	}
+
+	var msg pushMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Message.Attributes["eventType"] != ArchiveStoredType {
+		return nil, nil
+	}
+	ev = &ArchiveStored{}
+	if err := json.Unmarshal(msg.Message.Data, ev); err != nil {
+		return nil, fmt.Errorf("bad data of message %s: %v", msg.Message.MessageID, err)
+	}
+	if ev.Type != ArchiveStoredType {
+		return nil, fmt.Errorf("message %s holds an event of type %q", msg.Message.MessageID, ev.Type)
+	}
+	return ev, nil
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+)
+
+var testEvent = &ArchiveStored{
+	Type:       ArchiveStoredType,
+	Time:       time.Date(2021, 11, 1, 0, 15, 0, 0, time.UTC),
+	Bucket:     "routeviews-archives",
+	Object:     "route-views2/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+	Generation: 42,
+	Project:    "ROUTEVIEWS",
+	Collector:  "route-views2",
+	MRTType:    "UPDATES",
+}
+
+// TestPublish tests that events of each publisher are decoded as sent.
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		desc string
+		// publisher returns a publisher sending to srv, whose handler
+		// passes the body of the push of each event to push.
+		publisher func(t *testing.T, srv *httptest.Server) Publisher
+		// handler returns the handler of srv.
+		handler func(t *testing.T, push func([]byte)) http.HandlerFunc
+	}{{
+		desc: "http",
+		publisher: func(t *testing.T, srv *httptest.Server) Publisher {
+			return NewHTTPPublisher(srv.URL, srv.Client())
+		},
+		handler: func(t *testing.T, push func([]byte)) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Error(err)
+				}
+				push(body)
+			}
+		},
+	}, {
+		desc: "pubsub",
+		publisher: func(t *testing.T, srv *httptest.Server) Publisher {
+			p, err := NewPubSubPublisher(context.Background(), "projects/p/topics/archives",
+				option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return p
+		},
+		handler: func(t *testing.T, push func([]byte)) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/projects/p/topics/archives:publish" {
+					t.Errorf("got publish to %s", r.URL.Path)
+				}
+				var req struct {
+					Messages []json.RawMessage `json:"messages"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+				// Wrap each message as a push subscription does.
+				for _, m := range req.Messages {
+					body, err := json.Marshal(map[string]interface{}{
+						"message":      m,
+						"subscription": "projects/p/subscriptions/converter",
+					})
+					if err != nil {
+						t.Error(err)
+					}
+					push(body)
+				}
+				w.Write([]byte(`{"messageIds": ["1"]}`))
+			}
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var got []*ArchiveStored
+			srv := httptest.NewServer(test.handler(t, func(body []byte) {
+				ev, err := Unmarshal(body)
+				if err != nil {
+					t.Errorf("Unmarshal(%s): %v", body, err)
+				}
+				got = append(got, ev)
+			}))
+			t.Cleanup(srv.Close)
+			p := test.publisher(t, srv)
+			defer p.Close()
+
+			if err := p.Publish(context.Background(), testEvent); err != nil {
+				t.Fatalf("Publish(): %v", err)
+			}
+			if diff := cmp.Diff([]*ArchiveStored{testEvent}, got); diff != "" {
+				t.Errorf("pushed events diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHTTPPublisherError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+	if err := NewHTTPPublisher(srv.URL, nil).Publish(context.Background(), testEvent); err == nil {
+		t.Error("Publish() succeeded on a 403")
+	}
+}
+
+// TestUnmarshalOther tests that other events, ie: GCS notifications, are
+// left to the caller.
+func TestUnmarshalOther(t *testing.T) {
+	body := []byte(`{"message": {"attributes": {"bucketId": "b", "objectId": "o", "eventType": "OBJECT_FINALIZE"}, "messageId": "1"}}`)
+	ev, err := Unmarshal(body)
+	if err != nil || ev != nil {
+		t.Errorf("Unmarshal() = %v, %v; want nil", ev, err)
+	}
+}
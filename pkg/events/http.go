@@ -0,0 +1,54 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// HTTPPublisher posts each event as a JSON body to a URL, ie: the converter
+// or the pubsub_forwarder. Any status other than 2xx fails the publish.
+type HTTPPublisher struct {
+	url string
+	c   *http.Client
+}
+
+// NewHTTPPublisher returns a publisher posting events to url with c, which
+// may add credentials to the requests. A nil c is http.DefaultClient.
+func NewHTTPPublisher(url string, c *http.Client) *HTTPPublisher {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	return &HTTPPublisher{url: url, c: c}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, ev *ArchiveStored) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post event of %s to %s: %v", ev.Object, p.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to post event of %s to %s: %s: %s", ev.Object, p.url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func (p *HTTPPublisher) Close() error {
+	p.c.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/api/option"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// PubSubPublisher publishes each event as a message of a Pub/Sub topic. The
+// data of messages is the JSON event, their attributes hold its type,
+// bucket, object and project.
+type PubSubPublisher struct {
+	topic  string
+	topics *pubsub.ProjectsTopicsService
+}
+
+// NewPubSubPublisher returns a publisher to topic, given as
+// projects/PROJECT/topics/TOPIC. Application Default Credentials are used
+// unless opts say otherwise.
+func NewPubSubPublisher(ctx context.Context, topic string, opts ...option.ClientOption) (*PubSubPublisher, error) {
+	svc, err := pubsub.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %v", err)
+	}
+	return &PubSubPublisher{topic: topic, topics: pubsub.NewProjectsTopicsService(svc)}, nil
+}
+
+func (p *PubSubPublisher) Publish(ctx context.Context, ev *ArchiveStored) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = p.topics.Publish(p.topic, &pubsub.PublishRequest{
+		Messages: []*pubsub.PubsubMessage{{
+			Data:       base64.StdEncoding.EncodeToString(b),
+			Attributes: ev.attributes(),
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to publish event of %s to %s: %v", ev.Object, p.topic, err)
+	}
+	return nil
+}
+
+func (p *PubSubPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var filesBucket = []byte("files")
+
+// BoltStore keeps records in an embedded bbolt database file, keyed by
+// project and object. Only one process may open the file at a time, see
+// BucketStore to share records between processes.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens, or creates, the database file at path.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("bolt.Open(%s): %v", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(filesBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create bucket: %v", err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (b *BoltStore) Update(_ context.Context, rec *Record) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket(filesBucket)
+		k := []byte(key(rec.Project, rec.Object))
+		var old *Record
+		if raw := bkt.Get(k); raw != nil {
+			old = &Record{}
+			if err := json.Unmarshal(raw, old); err != nil {
+				return fmt.Errorf("failed to decode record %s: %v", k, err)
+			}
+		}
+		raw, err := json.Marshal(merge(old, rec))
+		if err != nil {
+			return err
+		}
+		return bkt.Put(k, raw)
+	})
+}
+
+func (b *BoltStore) Get(_ context.Context, project, object string) (*Record, error) {
+	var res *Record
+	err := b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(filesBucket).Get([]byte(key(project, object)))
+		if raw == nil {
+			return ErrNotFound
+		}
+		res = &Record{}
+		return json.Unmarshal(raw, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (b *BoltStore) List(_ context.Context, q *Query) ([]*Record, error) {
+	var res []*Record
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(filesBucket).Cursor()
+		// Keys are sorted, so the scan can start at and stop after the
+		// records of the project.
+		p := projectKey(q.Project)
+		for k, v := c.Seek([]byte(p)); k != nil && strings.HasPrefix(string(k), p); k, v = c.Next() {
+			r := &Record{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return fmt.Errorf("failed to decode record %s: %v", k, err)
+			}
+			if !q.matches(r) {
+				continue
+			}
+			res = append(res, r)
+			if q.Limit > 0 && len(res) == q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
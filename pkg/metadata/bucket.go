@@ -0,0 +1,99 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+)
+
+// BucketStore keeps each record as a JSON object of a bucket, named
+// <project>/<object>.json, so records are shared by every process with
+// access to the bucket. Updates read, merge and write a record without a
+// lock: of concurrent updates to the same record, the last write wins. The
+// stages of the pipeline update a file's record one after the other, so
+// updates are rarely lost.
+type BucketStore struct {
+	st objstore.ObjectStore
+}
+
+// NewBucketStore returns a store of the records in st.
+func NewBucketStore(st objstore.ObjectStore) *BucketStore {
+	return &BucketStore{st: st}
+}
+
+const recordExt = ".json"
+
+func (b *BucketStore) read(ctx context.Context, name string) (*Record, error) {
+	rd, err := b.st.Read(ctx, name)
+	if errors.Is(err, objstore.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record %s: %v", b.st.URL(name), err)
+	}
+	defer rd.Close()
+	r := &Record{}
+	if err := json.NewDecoder(rd).Decode(r); err != nil {
+		return nil, fmt.Errorf("failed to decode record %s: %v", b.st.URL(name), err)
+	}
+	return r, nil
+}
+
+func (b *BucketStore) Update(ctx context.Context, rec *Record) error {
+	name := key(rec.Project, rec.Object) + recordExt
+	old, err := b.read(ctx, name)
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+	raw, err := json.Marshal(merge(old, rec))
+	if err != nil {
+		return err
+	}
+	if _, err := b.st.Put(ctx, name, bytes.NewReader(raw), &objstore.Attrs{ContentType: "application/json"}); err != nil {
+		return fmt.Errorf("failed to store record %s: %v", b.st.URL(name), err)
+	}
+	return nil
+}
+
+func (b *BucketStore) Get(ctx context.Context, project, object string) (*Record, error) {
+	return b.read(ctx, key(project, object)+recordExt)
+}
+
+// List reads every record of the queried project, or of all projects.
+func (b *BucketStore) List(ctx context.Context, q *Query) ([]*Record, error) {
+	var res []*Record
+	err := b.st.List(ctx, projectKey(q.Project), func(a *objstore.Attrs) error {
+		if !strings.HasSuffix(a.Name, recordExt) {
+			return nil
+		}
+		r, err := b.read(ctx, a.Name)
+		if err == ErrNotFound {
+			// Deleted since it was listed.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if q.matches(r) {
+			res = append(res, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortRecords(res)
+	if q.Limit > 0 && len(res) > q.Limit {
+		res = res[:q.Limit]
+	}
+	return res, nil
+}
+
+func (b *BucketStore) Close() error {
+	return nil
+}
@@ -0,0 +1,56 @@
+package metadata
+
+import (
+	"context"
+	"sync"
+)
+
+// MemStore keeps records in memory.
+type MemStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{records: map[string]*Record{}}
+}
+
+func (m *MemStore) Update(_ context.Context, rec *Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := key(rec.Project, rec.Object)
+	m.records[k] = merge(m.records[k], rec)
+	return nil
+}
+
+func (m *MemStore) Get(_ context.Context, project, object string) (*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[key(project, object)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *r
+	return &c, nil
+}
+
+func (m *MemStore) List(_ context.Context, q *Query) ([]*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []*Record
+	for _, r := range m.records {
+		if q.matches(r) {
+			c := *r
+			res = append(res, &c)
+		}
+	}
+	sortRecords(res)
+	if q.Limit > 0 && len(res) > q.Limit {
+		res = res[:q.Limit]
+	}
+	return res, nil
+}
+
+func (m *MemStore) Close() error {
+	return nil
+}
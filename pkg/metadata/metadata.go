@@ -0,0 +1,164 @@
+// Package metadata tracks the state of each archived file while it moves
+// through the pipeline: upload, storage, conversion and BigQuery transfer.
+//
+// Records are kept in a Store. MemStore is meant for tests and one-off
+// runs, BoltStore keeps records in an embedded on-disk database for
+// local use by a single process. BucketStore keeps records in a bucket, to
+// share them between the upload server, the converter and the BigQuery
+// transfer, which run as separate processes.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+)
+
+// Status is the processing state of a file. Values match the names of
+// rv.proto's FileStatus.Status.
+type Status string
+
+const (
+	StatusUnknown           Status = "UNKNOWN"
+	StatusReceiving         Status = "RECEIVING"
+	StatusStored            Status = "STORED"
+	StatusConverting        Status = "CONVERTING"
+	StatusConverted         Status = "CONVERTED"
+	StatusTransferScheduled Status = "TRANSFER_SCHEDULED"
+	StatusFailed            Status = "FAILED"
+	StatusRetracted         Status = "RETRACTED"
+)
+
+// ErrNotFound is returned when no record exists for a file.
+var ErrNotFound = errors.New("metadata: record not found")
+
+// Record is the metadata of a single file, as listed in the README.
+type Record struct {
+	// Project and Object, the name of the file in the project's bucket,
+	// identify the record.
+	Project string
+	Object  string
+	// Filename is the path used in the FileRequest, Object is the same
+	// unless the project stores files under a path prefix.
+	Filename        string
+	Status          Status
+	Collector       string
+	MRTType         string
+	ContentType     string
+	ContentEncoding string
+	// Error explains a StatusFailed, or the reason of a StatusRetracted.
+	Error   string
+	Updated time.Time
+}
+
+// merge returns old updated with the non-empty fields of rec. The error is
+// replaced whenever the status changes.
+func merge(old, rec *Record) *Record {
+	res := &Record{Project: rec.Project, Object: rec.Object}
+	if old != nil {
+		*res = *old
+	}
+	if rec.Status != "" {
+		res.Status = rec.Status
+		res.Error = rec.Error
+	}
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&res.Filename, rec.Filename},
+		{&res.Collector, rec.Collector},
+		{&res.MRTType, rec.MRTType},
+		{&res.ContentType, rec.ContentType},
+		{&res.ContentEncoding, rec.ContentEncoding},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	res.Updated = time.Now().UTC()
+	return res
+}
+
+// key identifies the record of object in project.
+func key(project, object string) string {
+	return project + "/" + object
+}
+
+// projectKey is the prefix of the keys of the records of project, or of
+// every record if project is empty.
+func projectKey(project string) string {
+	if project == "" {
+		return ""
+	}
+	return project + "/"
+}
+
+// Query selects records to list. Empty fields match every record.
+type Query struct {
+	Project string
+	// Prefix matches the start of filenames.
+	Prefix string
+	Status Status
+	// Limit is the maximum number of records returned, 0 means no limit.
+	Limit int
+}
+
+func (q *Query) matches(r *Record) bool {
+	return (q.Project == "" || q.Project == r.Project) &&
+		strings.HasPrefix(r.Filename, q.Prefix) &&
+		(q.Status == "" || q.Status == r.Status)
+}
+
+// Store keeps the metadata records of files.
+type Store interface {
+	// Update merges the non-empty fields of rec into the record with the
+	// same project and object, creating it if needed.
+	Update(ctx context.Context, rec *Record) error
+	// Get returns the record of object in project, or ErrNotFound.
+	Get(ctx context.Context, project, object string) (*Record, error)
+	// List returns the records matching q, ordered by project and object.
+	List(ctx context.Context, q *Query) ([]*Record, error)
+	Close() error
+}
+
+// Open returns the store at loc: a MemStore if loc is empty, a BucketStore
+// if loc is a gs://, s3:// or file:// location, opened with clients, or
+// else a BoltStore of the database file at loc. Processes sharing records
+// must use a bucket, a database file is only opened by one process at a
+// time.
+func Open(loc string, clients *objstore.Clients) (Store, error) {
+	switch {
+	case loc == "":
+		return NewMemStore(), nil
+	case strings.Contains(loc, "://"):
+		st, err := objstore.Open(loc, clients)
+		if err != nil {
+			return nil, err
+		}
+		return NewBucketStore(st), nil
+	}
+	return OpenBoltStore(loc)
+}
+
+// Track updates rec in s if a store is set. Failures are only logged,
+// tracking must not interrupt the processing of a file.
+func Track(ctx context.Context, s Store, rec *Record) {
+	if s == nil {
+		return
+	}
+	if err := s.Update(ctx, rec); err != nil {
+		glog.Errorf("failed to track metadata of %s: %v", key(rec.Project, rec.Object), err)
+	}
+}
+
+func sortRecords(recs []*Record) {
+	sort.Slice(recs, func(i, j int) bool {
+		return key(recs[i].Project, recs[i].Object) < key(recs[j].Project, recs[j].Object)
+	})
+}
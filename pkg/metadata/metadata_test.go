@@ -0,0 +1,126 @@
+package metadata
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemStore() },
+		"bolt": func(t *testing.T) Store {
+			s, err := OpenBoltStore(filepath.Join(t.TempDir(), "metadata.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+		"bucket": func(t *testing.T) Store {
+			s, err := Open("file://"+t.TempDir(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			s := open(t)
+			t.Cleanup(func() { s.Close() })
+
+			const (
+				upd = "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+				rib = "bgpdata/2021.11/RIBS/rib.20211101.0000.bz2"
+				sg  = "route-views.sg/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"
+			)
+			updates := []*Record{
+				{Project: "ROUTEVIEWS", Object: upd, Filename: upd, Status: StatusReceiving},
+				{Project: "ROUTEVIEWS", Object: upd, Status: StatusFailed, Error: "checksum failure"},
+				{Project: "ROUTEVIEWS", Object: upd, Status: StatusStored},
+				{Project: "ROUTEVIEWS", Object: upd, Status: StatusConverted, Collector: "route-views2", MRTType: "UPDATES"},
+				{Project: "ROUTEVIEWS_RIB", Object: rib, Filename: rib, Status: StatusStored},
+				// The same filename in another project is another record.
+				{Project: "ROUTEVIEWS_RIB", Object: "route-views2/" + upd, Filename: upd, Status: StatusStored},
+				{Project: "ROUTEVIEWS", Object: sg, Filename: sg, Status: StatusStored},
+			}
+			for _, u := range updates {
+				if err := s.Update(ctx, u); err != nil {
+					t.Fatalf("Update(%s, %s): %v", u.Project, u.Object, err)
+				}
+			}
+
+			ignoreUpdated := cmpopts.IgnoreFields(Record{}, "Updated")
+			got, err := s.Get(ctx, "ROUTEVIEWS", upd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &Record{
+				Project:   "ROUTEVIEWS",
+				Object:    upd,
+				Filename:  upd,
+				Status:    StatusConverted,
+				Collector: "route-views2",
+				MRTType:   "UPDATES",
+			}
+			if diff := cmp.Diff(want, got, ignoreUpdated); diff != "" {
+				t.Errorf("Get() diff (-want +got):\n%s", diff)
+			}
+			if got.Updated.IsZero() {
+				t.Error("Get() has no updated time")
+			}
+			if _, err := s.Get(ctx, "ROUTEVIEWS", "missing"); err != ErrNotFound {
+				t.Errorf("Get(missing) = %v; want ErrNotFound", err)
+			}
+			if _, err := s.Get(ctx, "ROUTEVIEWS_RIB", upd); err != ErrNotFound {
+				t.Errorf("Get() of another project = %v; want ErrNotFound", err)
+			}
+
+			queries := []struct {
+				q    *Query
+				want []string
+			}{
+				{&Query{}, []string{
+					"ROUTEVIEWS/" + upd,
+					"ROUTEVIEWS/" + sg,
+					"ROUTEVIEWS_RIB/" + rib,
+					"ROUTEVIEWS_RIB/route-views2/" + upd,
+				}},
+				{&Query{Project: "ROUTEVIEWS"}, []string{
+					"ROUTEVIEWS/" + upd,
+					"ROUTEVIEWS/" + sg,
+				}},
+				{&Query{Prefix: "bgpdata/"}, []string{
+					"ROUTEVIEWS/" + upd,
+					"ROUTEVIEWS_RIB/" + rib,
+					"ROUTEVIEWS_RIB/route-views2/" + upd,
+				}},
+				{&Query{Status: StatusStored}, []string{
+					"ROUTEVIEWS/" + sg,
+					"ROUTEVIEWS_RIB/" + rib,
+					"ROUTEVIEWS_RIB/route-views2/" + upd,
+				}},
+				{&Query{Limit: 1}, []string{
+					"ROUTEVIEWS/" + upd,
+				}},
+			}
+			for _, q := range queries {
+				recs, err := s.List(ctx, q.q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []string
+				for _, r := range recs {
+					got = append(got, key(r.Project, r.Object))
+				}
+				if diff := cmp.Diff(q.want, got); diff != "" {
+					t.Errorf("List(%+v) diff (-want +got):\n%s", q.q, diff)
+				}
+			}
+		})
+	}
+}
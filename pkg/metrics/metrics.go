@@ -0,0 +1,111 @@
+// Package metrics defines the Prometheus metrics shared by the upload server,
+// the converter, the synchronizer and the mass uploader. Metrics register
+// with the default registry, each binary serves them on Path.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is the HTTP path metrics are served on.
+const Path = "/metrics"
+
+const namespace = "routeviews"
+
+var (
+	// Uploads counts files uploaded to the upload server, by project and
+	// FileResponse status.
+	Uploads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "uploads_total",
+		Help:      "Files uploaded, by project and response status.",
+	}, []string{"project", "status"})
+
+	// BytesReceived counts file content received by the upload server, by
+	// project.
+	BytesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upload_received_bytes_total",
+		Help:      "File content received, by project.",
+	}, []string{"project"})
+
+	// ChecksumFailures counts uploads whose content does not match the
+	// md5sum sent along, by project.
+	ChecksumFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upload_checksum_failures_total",
+		Help:      "Uploads with mismatched checksums, by project.",
+	}, []string{"project"})
+
+	// EventsPublished counts the events published by the upload server, by
+	// type and result: ok or error.
+	EventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_published_total",
+		Help:      "Events published, by type and result.",
+	}, []string{"type", "result"})
+
+	// ConfigReloads counts reloads of the upload server's config, by
+	// result: ok or error.
+	ConfigReloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "Config reloads, by result.",
+	}, []string{"result"})
+	// ConversionLatency observes the time to convert an archive for
+	// BigQuery, by collector.
+	ConversionLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "conversion_duration_seconds",
+		Help:      "Time to convert an archive, by collector.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12),
+	}, []string{"collector"})
+
+	// MessagesConverted counts MRT messages converted for BigQuery, by
+	// collector.
+	MessagesConverted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_converted_total",
+		Help:      "MRT messages converted, by collector.",
+	}, []string{"collector"})
+
+	// MessagesSkipped counts MRT messages left out of conversions, by
+	// collector and reason.
+	MessagesSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_skipped_total",
+		Help:      "MRT messages skipped during conversion, by collector and reason.",
+	}, []string{"collector", "reason"})
+
+	// ArchivesSynced counts archives checked by the synchronizer and the
+	// mass uploader, by result: sync, skip or error.
+	ArchivesSynced = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archives_synced_total",
+		Help:      "Archives checked against the archive bucket, by result.",
+	}, []string{"result"})
+
+	// ArchivesMissing counts gaps found by the synchronizer, where two
+	// consecutive archives of a collector are over 15 minutes apart.
+	ArchivesMissing = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archives_missing_total",
+		Help:      "Gaps between consecutive archives, by collector.",
+	}, []string{"collector"})
+)
+
+// Handler returns the HTTP handler which serves the metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ListenAndServe serves the metrics on Path of addr.
+func ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	return http.ListenAndServe(addr, mux)
+}
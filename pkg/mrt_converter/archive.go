@@ -0,0 +1,154 @@
+package converter
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+)
+
+// Keys of the archive attributes in an archive's GCS metadata, along with
+// ProjectMetadataKey.
+const (
+	FilenameMetadataKey  = "routingDataFilename"
+	CollectorMetadataKey = "routingDataCollector"
+	MRTTypeMetadataKey   = "routingDataMRTType"
+	// SHA256MetadataKey maps to the hex encoded SHA-256 of the content.
+	SHA256MetadataKey = "routingDataSHA256"
+)
+
+// Prefixes of the objects the upload server keeps next to the archives of
+// a bucket, which are not converted. Under a path prefix, they follow it.
+const (
+	SupersededPrefix = "superseded/"
+	RetractedPrefix  = "retracted/"
+	AuditPrefix      = "audit/"
+	UploadPartPrefix = ".uploads/"
+)
+
+// reservedPrefixes are all the prefixes of objects which are not archives.
+var reservedPrefixes = []string{SupersededPrefix, RetractedPrefix, AuditPrefix, UploadPartPrefix}
+
+// Reserved reports whether object is kept by the upload server next to the
+// archives, rather than an archive: a superseded or retracted generation,
+// an audit record or a part of an upload.
+func Reserved(object string) bool {
+	for _, p := range reservedPrefixes {
+		if strings.HasPrefix(object, p) || strings.Contains(object, "/"+p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MRT types of archives.
+const (
+	MRTTypeRIB     = "RIB"
+	MRTTypeUpdates = "UPDATES"
+)
+
+var (
+	bzip2Magic = []byte("BZh")
+	gzipMagic  = []byte{0x1f, 0x8b}
+)
+
+// SniffLen is the number of leading content bytes DescribeArchive needs. A
+// bzip2 reader does not return any content until the first block, of up to
+// 900k, is decompressed.
+const SniffLen = 1024 * 1024
+
+// ArchiveAttrs are the attributes of a stored archive, as listed in the
+// README's Metadata section.
+type ArchiveAttrs struct {
+	Filename        string
+	Project         pb.FileRequest_Project
+	ContentType     string
+	ContentEncoding string
+	Collector       string
+	MRTType         string
+	// SHA256 is the hex encoded SHA-256 of the content, set once known.
+	SHA256 string
+}
+
+// DescribeArchive derives the attributes of an archive from its filename,
+// project and the leading bytes of its content. Attributes which cannot be
+// derived are left empty.
+func DescribeArchive(filename string, proj pb.FileRequest_Project, head []byte) *ArchiveAttrs {
+	a := &ArchiveAttrs{Filename: filename, Project: proj}
+
+	var r io.Reader = bytes.NewReader(head)
+	switch {
+	case bytes.HasPrefix(head, bzip2Magic):
+		a.ContentEncoding = "bzip2"
+		r = bzip2.NewReader(r)
+	case bytes.HasPrefix(head, gzipMagic):
+		a.ContentEncoding = "gzip"
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			r = bytes.NewReader(nil)
+			break
+		}
+		r = gr
+	}
+	// The head may end in the middle of the compressed content, only the
+	// start of the decompressed content matters here.
+	buf := make([]byte, 512)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+	if len(buf) > 0 {
+		a.ContentType = http.DetectContentType(buf)
+	} else {
+		a.ContentType = "application/octet-stream"
+	}
+	a.MRTType = mrtType(buf)
+
+	switch proj {
+	case pb.FileRequest_ROUTEVIEWS, pb.FileRequest_ROUTEVIEWS_RIB:
+		// Not every file uploaded is an archive of a collector.
+		a.Collector, _ = RouteViewsCollectorFromPath(filename)
+	}
+	return a
+}
+
+// mrtType returns the MRT type of the first MRT record in b, if any.
+func mrtType(b []byte) string {
+	if len(b) < mrt.MRT_COMMON_HEADER_LEN {
+		return ""
+	}
+	h := &mrt.MRTHeader{}
+	if err := h.DecodeFromBytes(b[:mrt.MRT_COMMON_HEADER_LEN]); err != nil {
+		return ""
+	}
+	switch h.Type {
+	case mrt.TABLE_DUMP, mrt.TABLE_DUMPv2:
+		return MRTTypeRIB
+	case mrt.BGP4MP, mrt.BGP4MP_ET:
+		return MRTTypeUpdates
+	}
+	return ""
+}
+
+// Metadata returns the GCS metadata of the archive. Empty attributes are
+// left out.
+func (a *ArchiveAttrs) Metadata() map[string]string {
+	md := map[string]string{
+		ProjectMetadataKey:  a.Project.String(),
+		FilenameMetadataKey: a.Filename,
+	}
+	if a.Collector != "" {
+		md[CollectorMetadataKey] = a.Collector
+	}
+	if a.MRTType != "" {
+		md[MRTTypeMetadataKey] = a.MRTType
+	}
+	if a.SHA256 != "" {
+		md[SHA256MetadataKey] = a.SHA256
+	}
+	return md
+}
@@ -0,0 +1,136 @@
+package converter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+)
+
+func bzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	bw, err := bzip2.NewWriter(buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	if _, err := gw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDescribeArchive(t *testing.T) {
+	updates := encodeMRTMessage(t, fakeMRTMessage(t, time.Now(), mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Ann))
+	ribHeader, err := fakeMRTHeader(t, time.Now(), mrt.TABLE_DUMPv2, mrt.PEER_INDEX_TABLE, 0).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc     string
+		filename string
+		project  pb.FileRequest_Project
+		head     []byte
+		want     *ArchiveAttrs
+	}{
+		{
+			desc:     "bzip2 updates",
+			filename: "route-views.sg/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			project:  pb.FileRequest_ROUTEVIEWS,
+			head:     bzipped(t, updates),
+			want: &ArchiveAttrs{
+				ContentType:     "application/octet-stream",
+				ContentEncoding: "bzip2",
+				Collector:       "route-views.sg",
+				MRTType:         MRTTypeUpdates,
+			},
+		},
+		{
+			desc:     "gzip RIB",
+			filename: "bgpdata/2021.11/RIBS/rib.20211101.0000.gz",
+			project:  pb.FileRequest_ROUTEVIEWS_RIB,
+			head:     gzipped(t, ribHeader),
+			want: &ArchiveAttrs{
+				ContentType:     "application/octet-stream",
+				ContentEncoding: "gzip",
+				Collector:       "route-views2",
+				MRTType:         MRTTypeRIB,
+			},
+		},
+		{
+			desc:     "truncated bzip2",
+			filename: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			project:  pb.FileRequest_ROUTEVIEWS,
+			head:     bzipped(t, updates)[:20],
+			want: &ArchiveAttrs{
+				ContentType:     "application/octet-stream",
+				ContentEncoding: "bzip2",
+				Collector:       "route-views2",
+			},
+		},
+		{
+			desc:     "uncompressed non-MRT file",
+			filename: "rpki/2021.11/rarc.tar",
+			project:  pb.FileRequest_RPKI_RARC,
+			head:     []byte("Foo Bar Baz"),
+			want: &ArchiveAttrs{
+				ContentType: "text/plain; charset=utf-8",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			test.want.Filename = test.filename
+			test.want.Project = test.project
+			got := DescribeArchive(test.filename, test.project, test.head)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("DescribeArchive() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReserved(t *testing.T) {
+	tests := []struct {
+		object string
+		want   bool
+	}{
+		{"bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", false},
+		{"route-views2/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", false},
+		{SupersededPrefix + "1/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", true},
+		{RetractedPrefix + "1/route-views2/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", true},
+		{AuditPrefix + "retractions/20211101T000000.000000000Z-1.json", true},
+		{UploadPartPrefix + "0123456789abcdef/00000000000000000000", true},
+		// Under a path prefix.
+		{"route-views2/" + RetractedPrefix + "1/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2", true},
+		{"route-views2/not-" + AuditPrefix + "updates.20211101.0000.bz2", false},
+	}
+	for _, test := range tests {
+		if got := Reserved(test.object); got != test.want {
+			t.Errorf("Reserved(%q) = %v; want %v", test.object, got, test.want)
+		}
+	}
+}
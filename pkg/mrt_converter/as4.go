@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+)
+
+// mergeAS4Path rewrites the attributes of a path from a session of 2-octet
+// ASNs, where the AS_PATH holds AS_TRANS in place of 4-octet ASNs. The
+// AS_PATH is replaced by the path of 4-octet ASNs reconstructed with the
+// AS4_PATH, following RFC 6793, section 4.2.3. The AS4_PATH is left as is.
+func mergeAS4Path(attrs []bgp.PathAttributeInterface) ([]bgp.PathAttributeInterface, error) {
+	asIdx := -1
+	var as4 *bgp.PathAttributeAs4Path
+	for i, attr := range attrs {
+		switch a := attr.(type) {
+		case *bgp.PathAttributeAsPath:
+			asIdx = i
+		case *bgp.PathAttributeAs4Path:
+			as4 = a
+		}
+	}
+	if asIdx < 0 {
+		return attrs, nil
+	}
+
+	as := attrs[asIdx].(*bgp.PathAttributeAsPath)
+	path, err := as2Path(as)
+	if err != nil {
+		return nil, err
+	}
+	if as4 != nil {
+		path = mergePaths(path, as4.Value)
+	}
+	res := append([]bgp.PathAttributeInterface(nil), attrs...)
+	res[asIdx] = &bgp.PathAttributeAsPath{PathAttribute: as.PathAttribute, Value: path}
+	return res, nil
+}
+
+// as2Path decodes the segments of an AS_PATH of 2-octet ASNs into segments of
+// 4-octet ASNs. GoBGP guesses the size of ASNs from the attribute, and may
+// take a path of 2-octet ASNs for 4-octet ones.
+func as2Path(as *bgp.PathAttributeAsPath) ([]bgp.AsPathParamInterface, error) {
+	raw, err := as.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("bad AS_PATH: %v", err)
+	}
+	// Skip the attribute header.
+	if as.Flags&bgp.BGP_ATTR_FLAG_EXTENDED_LENGTH != 0 || len(raw) > math.MaxUint8+3 {
+		raw = raw[4:]
+	} else {
+		raw = raw[3:]
+	}
+
+	var res []bgp.AsPathParamInterface
+	for len(raw) > 0 {
+		if len(raw) < 2 {
+			return nil, fmt.Errorf("short AS_PATH segment header: %d bytes", len(raw))
+		}
+		typ, n := raw[0], int(raw[1])
+		raw = raw[2:]
+		if len(raw) < 2*n {
+			return nil, fmt.Errorf("short AS_PATH segment: %d of %d bytes", len(raw), 2*n)
+		}
+		asns := make([]uint32, n)
+		for i := range asns {
+			asns[i] = uint32(binary.BigEndian.Uint16(raw[2*i:]))
+		}
+		raw = raw[2*n:]
+		res = append(res, bgp.NewAs4PathParam(typ, asns))
+	}
+	return res, nil
+}
+
+// mergePaths reconstructs a path from its AS_PATH and AS4_PATH segments: the
+// leading ASNs of the AS_PATH which the AS4_PATH does not cover, followed by
+// the AS4_PATH. The AS_PATH is kept if it is shorter than the AS4_PATH.
+func mergePaths(path []bgp.AsPathParamInterface, as4 []*bgp.As4PathParam) []bgp.AsPathParamInterface {
+	// AS4_PATH must not carry confederation segments, they are discarded.
+	var tail []*bgp.As4PathParam
+	n4 := 0
+	for _, seg := range as4 {
+		switch seg.Type {
+		case bgp.BGP_ASPATH_ATTR_TYPE_CONFED_SEQ, bgp.BGP_ASPATH_ATTR_TYPE_CONFED_SET:
+			continue
+		}
+		tail = append(tail, seg)
+		n4 += seg.ASLen()
+	}
+	n := 0
+	for _, seg := range path {
+		n += seg.ASLen()
+	}
+	if n < n4 {
+		return path
+	}
+
+	var res []bgp.AsPathParamInterface
+	for keep := n - n4; keep > 0 && len(path) > 0; path = path[1:] {
+		seg := path[0]
+		if l := seg.ASLen(); l <= keep {
+			res = append(res, seg)
+			keep -= l
+			continue
+		}
+		// Only an AS_SEQUENCE is partly kept.
+		res = append(res, bgp.NewAs4PathParam(seg.GetType(), seg.GetAS()[:keep]))
+		keep = 0
+	}
+	for _, seg := range tail {
+		if len(res) > 0 {
+			last := res[len(res)-1]
+			if last.GetType() == bgp.BGP_ASPATH_ATTR_TYPE_SEQ && seg.Type == bgp.BGP_ASPATH_ATTR_TYPE_SEQ && len(last.GetAS())+len(seg.AS) <= math.MaxUint8 {
+				res[len(res)-1] = bgp.NewAs4PathParam(seg.Type, append(append([]uint32(nil), last.GetAS()...), seg.AS...))
+				continue
+			}
+		}
+		res = append(res, seg)
+	}
+	return res
+}
@@ -0,0 +1,139 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+)
+
+// decodedAttr returns attr as GoBGP decodes it from the wire.
+func decodedAttr(t *testing.T, attr bgp.PathAttributeInterface) bgp.PathAttributeInterface {
+	t.Helper()
+	raw, err := attr.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := bgp.GetPathAttribute(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.DecodeFromBytes(raw); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func as2PathAttr(segs ...*bgp.AsPathParam) bgp.PathAttributeInterface {
+	var params []bgp.AsPathParamInterface
+	for _, s := range segs {
+		params = append(params, s)
+	}
+	return bgp.NewPathAttributeAsPath(params)
+}
+
+func as4PathAttr(segs ...*bgp.As4PathParam) bgp.PathAttributeInterface {
+	var params []bgp.AsPathParamInterface
+	for _, s := range segs {
+		params = append(params, s)
+	}
+	return bgp.NewPathAttributeAsPath(params)
+}
+
+func TestMergeAS4Path(t *testing.T) {
+	const (
+		seq       = bgp.BGP_ASPATH_ATTR_TYPE_SEQ
+		set       = bgp.BGP_ASPATH_ATTR_TYPE_SET
+		confedSeq = bgp.BGP_ASPATH_ATTR_TYPE_CONFED_SEQ
+	)
+	origin := bgp.NewPathAttributeOrigin(0)
+	tests := []struct {
+		desc  string
+		attrs []bgp.PathAttributeInterface
+		want  []bgp.PathAttributeInterface
+	}{
+		{
+			desc:  "no AS_PATH",
+			attrs: []bgp.PathAttributeInterface{origin},
+			want:  []bgp.PathAttributeInterface{origin},
+		},
+		{
+			desc:  "no AS4_PATH",
+			attrs: []bgp.PathAttributeInterface{origin, as2PathAttr(bgp.NewAsPathParam(seq, []uint16{701, 15169}))},
+			want:  []bgp.PathAttributeInterface{origin, as4PathAttr(bgp.NewAs4PathParam(seq, []uint32{701, 15169}))},
+		},
+		{
+			desc: "AS_TRANS replaced",
+			attrs: []bgp.PathAttributeInterface{
+				as2PathAttr(bgp.NewAsPathParam(seq, []uint16{701, 23456, 23456})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{bgp.NewAs4PathParam(seq, []uint32{100000, 200000})}),
+			},
+			want: []bgp.PathAttributeInterface{
+				as4PathAttr(bgp.NewAs4PathParam(seq, []uint32{701, 100000, 200000})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{bgp.NewAs4PathParam(seq, []uint32{100000, 200000})}),
+			},
+		},
+		{
+			desc: "AS_SET counted as one ASN",
+			attrs: []bgp.PathAttributeInterface{
+				as2PathAttr(bgp.NewAsPathParam(seq, []uint16{701, 23456}), bgp.NewAsPathParam(set, []uint16{23456, 3})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{
+					bgp.NewAs4PathParam(seq, []uint32{100000}), bgp.NewAs4PathParam(set, []uint32{100000, 3})}),
+			},
+			want: []bgp.PathAttributeInterface{
+				as4PathAttr(bgp.NewAs4PathParam(seq, []uint32{701, 100000}), bgp.NewAs4PathParam(set, []uint32{100000, 3})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{
+					bgp.NewAs4PathParam(seq, []uint32{100000}), bgp.NewAs4PathParam(set, []uint32{100000, 3})}),
+			},
+		},
+		{
+			desc: "confederation segments of AS4_PATH discarded",
+			attrs: []bgp.PathAttributeInterface{
+				as2PathAttr(bgp.NewAsPathParam(seq, []uint16{701, 23456})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{
+					bgp.NewAs4PathParam(confedSeq, []uint32{64512}), bgp.NewAs4PathParam(seq, []uint32{100000})}),
+			},
+			want: []bgp.PathAttributeInterface{
+				as4PathAttr(bgp.NewAs4PathParam(seq, []uint32{701, 100000})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{
+					bgp.NewAs4PathParam(confedSeq, []uint32{64512}), bgp.NewAs4PathParam(seq, []uint32{100000})}),
+			},
+		},
+		{
+			desc: "AS4_PATH longer than AS_PATH ignored",
+			attrs: []bgp.PathAttributeInterface{
+				as2PathAttr(bgp.NewAsPathParam(seq, []uint16{23456})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{bgp.NewAs4PathParam(seq, []uint32{701, 100000})}),
+			},
+			want: []bgp.PathAttributeInterface{
+				as4PathAttr(bgp.NewAs4PathParam(seq, []uint32{23456})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{bgp.NewAs4PathParam(seq, []uint32{701, 100000})}),
+			},
+		},
+		{
+			// GoBGP takes this path for a single segment of 4-octet ASNs.
+			desc: "2-octet AS_PATH read as 4-octet",
+			attrs: []bgp.PathAttributeInterface{
+				decodedAttr(t, as2PathAttr(bgp.NewAsPathParam(seq, []uint16{701, 23456}), bgp.NewAsPathParam(seq, []uint16{15169}))),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{bgp.NewAs4PathParam(seq, []uint32{100000, 15169})}),
+			},
+			want: []bgp.PathAttributeInterface{
+				as4PathAttr(bgp.NewAs4PathParam(seq, []uint32{701, 100000, 15169})),
+				bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{bgp.NewAs4PathParam(seq, []uint32{100000, 15169})}),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := mergeAS4Path(test.attrs)
+			if err != nil {
+				t.Fatalf("mergeAS4Path(): %v", err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(test.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("mergeAS4Path() = %s; want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
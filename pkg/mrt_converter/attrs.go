@@ -0,0 +1,229 @@
+package converter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+)
+
+// asTrans is the 2-octet ASN which stands in for 4-octet ASNs, of RFC 6793.
+const asTrans = 23456
+
+// pathAttributes are the typed columns of the path attributes of a route, of
+// FormatV2. Attributes without columns are kept as their type and GoBGP JSON.
+type pathAttributes struct {
+	Origin string
+	// ASPath holds the segments of the AS_PATH, and ASPathASNs every ASN of
+	// its AS_SEQUENCE and AS_SET segments in order. OriginAS is the last ASN
+	// of the path, null if it ends with an AS_SET of several ASNs.
+	ASPath     []*asPathSegment
+	ASPathASNs []uint32
+	OriginAS   *uint32
+
+	// NextHop is the NEXT_HOP, or the next hop of the MP_REACH_NLRI.
+	NextHop   string
+	MED       *uint32
+	LocalPref *uint32
+
+	Communities         []string
+	LargeCommunities    []string
+	ExtendedCommunities []*extendedCommunity
+
+	Aggregator      *aggregator
+	AtomicAggregate bool
+
+	OtherAttributes []*attributePayload
+}
+
+// asPathSegment is a segment of an AS_PATH, e.g. an AS_SEQUENCE.
+type asPathSegment struct {
+	Type string
+	ASNs []uint32
+}
+
+// extendedCommunity is an extended community, with its type and subtype, as
+// GoBGP writes it, e.g. 65000:100 for a route target.
+type extendedCommunity struct {
+	Type    uint8
+	Subtype uint8
+	Value   string
+}
+
+// aggregator is the AS and address of the AGGREGATOR, or AS4_AGGREGATOR for
+// the AS_TRANS of a 2-octet AGGREGATOR.
+type aggregator struct {
+	AS      uint32
+	Address string
+}
+
+// origins are the names of the ORIGIN values.
+var origins = map[uint8]string{
+	bgp.BGP_ORIGIN_ATTR_TYPE_IGP:        "IGP",
+	bgp.BGP_ORIGIN_ATTR_TYPE_EGP:        "EGP",
+	bgp.BGP_ORIGIN_ATTR_TYPE_INCOMPLETE: "INCOMPLETE",
+}
+
+// segmentTypes are the names of the AS_PATH segment types.
+var segmentTypes = map[uint8]string{
+	bgp.BGP_ASPATH_ATTR_TYPE_SET:        "AS_SET",
+	bgp.BGP_ASPATH_ATTR_TYPE_SEQ:        "AS_SEQUENCE",
+	bgp.BGP_ASPATH_ATTR_TYPE_CONFED_SEQ: "AS_CONFED_SEQUENCE",
+	bgp.BGP_ASPATH_ATTR_TYPE_CONFED_SET: "AS_CONFED_SET",
+}
+
+// typedAttrs returns the typed columns of attrs. The prefixes of
+// MP_REACH_NLRI and MP_UNREACH_NLRI are left to the caller.
+func typedAttrs(attrs []bgp.PathAttributeInterface) pathAttributes {
+	var (
+		res pathAttributes
+		as4 *aggregator
+	)
+	for _, attr := range attrs {
+		switch a := attr.(type) {
+		case *bgp.PathAttributeOrigin:
+			res.Origin = origins[a.Value]
+			if res.Origin == "" {
+				res.Origin = fmt.Sprint(a.Value)
+			}
+		case *bgp.PathAttributeAsPath:
+			res.ASPath, res.ASPathASNs, res.OriginAS = typedASPath(a)
+		case *bgp.PathAttributeNextHop:
+			res.NextHop = a.Value.String()
+		case *bgp.PathAttributeMpReachNLRI:
+			if res.NextHop == "" && a.Nexthop != nil {
+				res.NextHop = a.Nexthop.String()
+			}
+		case *bgp.PathAttributeMpUnreachNLRI:
+		case *bgp.PathAttributeMultiExitDisc:
+			med := a.Value
+			res.MED = &med
+		case *bgp.PathAttributeLocalPref:
+			pref := a.Value
+			res.LocalPref = &pref
+		case *bgp.PathAttributeCommunities:
+			for _, c := range a.Value {
+				res.Communities = append(res.Communities, fmt.Sprintf("%d:%d", c>>16, c&0xffff))
+			}
+		case *bgp.PathAttributeLargeCommunities:
+			for _, c := range a.Values {
+				res.LargeCommunities = append(res.LargeCommunities, c.String())
+			}
+		case *bgp.PathAttributeExtendedCommunities:
+			for _, c := range a.Value {
+				typ, sub := c.GetTypes()
+				res.ExtendedCommunities = append(res.ExtendedCommunities, &extendedCommunity{
+					Type:    uint8(typ),
+					Subtype: uint8(sub),
+					Value:   c.String(),
+				})
+			}
+		case *bgp.PathAttributeAggregator:
+			res.Aggregator = &aggregator{AS: a.Value.AS, Address: a.Value.Address.String()}
+		case *bgp.PathAttributeAs4Aggregator:
+			as4 = &aggregator{AS: a.Value.AS, Address: a.Value.Address.String()}
+			res.OtherAttributes = append(res.OtherAttributes, translateAttrs([]bgp.PathAttributeInterface{a})...)
+		case *bgp.PathAttributeAtomicAggregate:
+			res.AtomicAggregate = true
+		default:
+			res.OtherAttributes = append(res.OtherAttributes, translateAttrs([]bgp.PathAttributeInterface{a})...)
+		}
+	}
+	if res.Aggregator != nil && res.Aggregator.AS == asTrans && as4 != nil {
+		res.Aggregator = as4
+	}
+	return res
+}
+
+func typedASPath(as *bgp.PathAttributeAsPath) ([]*asPathSegment, []uint32, *uint32) {
+	var (
+		segs   []*asPathSegment
+		asns   []uint32
+		origin *uint32
+	)
+	for _, p := range as.Value {
+		typ := segmentTypes[p.GetType()]
+		if typ == "" {
+			typ = fmt.Sprint(p.GetType())
+		}
+		segs = append(segs, &asPathSegment{Type: typ, ASNs: p.GetAS()})
+		switch p.GetType() {
+		case bgp.BGP_ASPATH_ATTR_TYPE_SEQ, bgp.BGP_ASPATH_ATTR_TYPE_SET:
+			asns = append(asns, p.GetAS()...)
+		}
+	}
+	if n := len(as.Value); n > 0 {
+		last := as.Value[n-1].GetAS()
+		switch t := as.Value[n-1].GetType(); {
+		case t == bgp.BGP_ASPATH_ATTR_TYPE_SEQ && len(last) > 0, t == bgp.BGP_ASPATH_ATTR_TYPE_SET && len(last) == 1:
+			asn := last[len(last)-1]
+			origin = &asn
+		}
+	}
+	return segs, asns, origin
+}
+
+// mpPrefixes returns the prefixes of the MP_REACH_NLRI and MP_UNREACH_NLRI of
+// attrs.
+func mpPrefixes(attrs []bgp.PathAttributeInterface) (reach, unreach []string) {
+	for _, attr := range attrs {
+		switch a := attr.(type) {
+		case *bgp.PathAttributeMpReachNLRI:
+			for _, p := range a.Value {
+				reach = append(reach, p.String())
+			}
+		case *bgp.PathAttributeMpUnreachNLRI:
+			for _, p := range a.Value {
+				unreach = append(unreach, p.String())
+			}
+		}
+	}
+	return reach, unreach
+}
+
+// updateV2 is an update of FormatV2. The prefixes of MP_REACH_NLRI and
+// MP_UNREACH_NLRI are announced and withdrawn along with the others.
+type updateV2 struct {
+	Collector string
+	SeenAt    time.Time
+	PeerAS    uint32
+
+	Announced []string
+	Withdrawn []string
+	pathAttributes
+}
+
+func (u *update) v2() *updateV2 {
+	reach, unreach := mpPrefixes(u.attrs)
+	return &updateV2{
+		Collector:      u.Collector,
+		SeenAt:         u.SeenAt,
+		PeerAS:         u.PeerAS,
+		Announced:      append(append([]string(nil), u.Announced...), reach...),
+		Withdrawn:      append(append([]string(nil), u.Withdrawn...), unreach...),
+		pathAttributes: typedAttrs(u.attrs),
+	}
+}
+
+// ribEntryV2 is a RIB entry of FormatV2.
+type ribEntryV2 struct {
+	Collector    string
+	DumpedAt     time.Time
+	OriginatedAt time.Time
+	Prefix       string
+	PeerIP       string
+	PeerAS       uint32
+	pathAttributes
+}
+
+func (e *ribEntry) v2() *ribEntryV2 {
+	return &ribEntryV2{
+		Collector:      e.Collector,
+		DumpedAt:       e.DumpedAt,
+		OriginatedAt:   e.OriginatedAt,
+		Prefix:         e.Prefix,
+		PeerIP:         e.PeerIP,
+		PeerAS:         e.PeerAS,
+		pathAttributes: typedAttrs(e.attrs),
+	}
+}
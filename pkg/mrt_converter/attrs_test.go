@@ -0,0 +1,166 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+)
+
+func uint32p(v uint32) *uint32 {
+	return &v
+}
+
+func TestTypedAttrs(t *testing.T) {
+	unknown := bgp.NewPathAttributeUnknown(bgp.BGP_ATTR_FLAG_OPTIONAL|bgp.BGP_ATTR_FLAG_TRANSITIVE, 99, []byte{1, 2})
+	as4Aggregator := bgp.NewPathAttributeAs4Aggregator(100000, "192.0.2.2")
+	tests := []struct {
+		desc  string
+		attrs []bgp.PathAttributeInterface
+		want  pathAttributes
+	}{
+		{
+			desc: "common attributes",
+			attrs: []bgp.PathAttributeInterface{
+				bgp.NewPathAttributeOrigin(bgp.BGP_ORIGIN_ATTR_TYPE_IGP),
+				bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
+					bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{3280, 15169}),
+				}),
+				bgp.NewPathAttributeNextHop("192.0.2.1"),
+				bgp.NewPathAttributeMultiExitDisc(10),
+				bgp.NewPathAttributeLocalPref(100),
+				bgp.NewPathAttributeCommunities([]uint32{3280<<16 | 100, 0xffffff01}),
+				bgp.NewPathAttributeLargeCommunities([]*bgp.LargeCommunity{bgp.NewLargeCommunity(100000, 1, 2)}),
+				bgp.NewPathAttributeExtendedCommunities([]bgp.ExtendedCommunityInterface{
+					bgp.NewTwoOctetAsSpecificExtended(bgp.EC_SUBTYPE_ROUTE_TARGET, 3280, 100, true),
+				}),
+			},
+			want: pathAttributes{
+				Origin:           "IGP",
+				ASPath:           []*asPathSegment{{Type: "AS_SEQUENCE", ASNs: []uint32{3280, 15169}}},
+				ASPathASNs:       []uint32{3280, 15169},
+				OriginAS:         uint32p(15169),
+				NextHop:          "192.0.2.1",
+				MED:              uint32p(10),
+				LocalPref:        uint32p(100),
+				Communities:      []string{"3280:100", "65535:65281"},
+				LargeCommunities: []string{"100000:1:2"},
+				ExtendedCommunities: []*extendedCommunity{{
+					Type:    uint8(bgp.EC_TYPE_TRANSITIVE_TWO_OCTET_AS_SPECIFIC),
+					Subtype: uint8(bgp.EC_SUBTYPE_ROUTE_TARGET),
+					Value:   "3280:100",
+				}},
+			},
+		},
+		{
+			desc: "path ending with an AS_SET of one ASN",
+			attrs: []bgp.PathAttributeInterface{
+				bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
+					bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_CONFED_SEQ, []uint32{65000}),
+					bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{3280}),
+					bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SET, []uint32{15169}),
+				}),
+			},
+			want: pathAttributes{
+				ASPath: []*asPathSegment{
+					{Type: "AS_CONFED_SEQUENCE", ASNs: []uint32{65000}},
+					{Type: "AS_SEQUENCE", ASNs: []uint32{3280}},
+					{Type: "AS_SET", ASNs: []uint32{15169}},
+				},
+				ASPathASNs: []uint32{3280, 15169},
+				OriginAS:   uint32p(15169),
+			},
+		},
+		{
+			desc: "path ending with an AS_SET of several ASNs",
+			attrs: []bgp.PathAttributeInterface{
+				bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
+					bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{3280}),
+					bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SET, []uint32{15169, 36040}),
+				}),
+			},
+			want: pathAttributes{
+				ASPath: []*asPathSegment{
+					{Type: "AS_SEQUENCE", ASNs: []uint32{3280}},
+					{Type: "AS_SET", ASNs: []uint32{15169, 36040}},
+				},
+				ASPathASNs: []uint32{3280, 15169, 36040},
+			},
+		},
+		{
+			desc: "aggregator of AS_TRANS with an AS4_AGGREGATOR",
+			attrs: []bgp.PathAttributeInterface{
+				bgp.NewPathAttributeAggregator(uint16(asTrans), "192.0.2.2"),
+				as4Aggregator,
+				bgp.NewPathAttributeAtomicAggregate(),
+			},
+			want: pathAttributes{
+				Aggregator:      &aggregator{AS: 100000, Address: "192.0.2.2"},
+				AtomicAggregate: true,
+				OtherAttributes: translateAttrs([]bgp.PathAttributeInterface{as4Aggregator}),
+			},
+		},
+		{
+			desc: "2-octet aggregator",
+			attrs: []bgp.PathAttributeInterface{
+				bgp.NewPathAttributeAggregator(uint16(3280), "192.0.2.2"),
+			},
+			want: pathAttributes{
+				Aggregator: &aggregator{AS: 3280, Address: "192.0.2.2"},
+			},
+		},
+		{
+			desc: "MP_REACH_NLRI next hop and unknown attribute",
+			attrs: []bgp.PathAttributeInterface{
+				bgp.NewPathAttributeMpReachNLRI("2001:db8::1", []bgp.AddrPrefixInterface{bgp.NewIPv6AddrPrefix(32, "2001:db8::")}),
+				unknown,
+			},
+			want: pathAttributes{
+				NextHop:         "2001:db8::1",
+				OtherAttributes: translateAttrs([]bgp.PathAttributeInterface{unknown}),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, typedAttrs(test.attrs), cmp.AllowUnexported(pathAttributes{})); diff != "" {
+				t.Errorf("typedAttrs() diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateV2(t *testing.T) {
+	fakeTime := time.Unix(time.Now().Unix(), 0)
+	u := &update{
+		Collector: "route-views2",
+		SeenAt:    fakeTime,
+		PeerAS:    3280,
+		Announced: []string{"10.0.0.0/24"},
+		Withdrawn: []string{"20.0.0.0/24"},
+		attrs: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeOrigin(bgp.BGP_ORIGIN_ATTR_TYPE_INCOMPLETE),
+			bgp.NewPathAttributeMpReachNLRI("2001:db8::1", []bgp.AddrPrefixInterface{bgp.NewIPv6AddrPrefix(32, "2001:db8::")}),
+			bgp.NewPathAttributeMpUnreachNLRI([]bgp.AddrPrefixInterface{bgp.NewIPv6AddrPrefix(48, "2001:db8:1::")}),
+		},
+	}
+	want := &updateV2{
+		Collector: "route-views2",
+		SeenAt:    fakeTime,
+		PeerAS:    3280,
+		Announced: []string{"10.0.0.0/24", "2001:db8::/32"},
+		Withdrawn: []string{"20.0.0.0/24", "2001:db8:1::/48"},
+		pathAttributes: pathAttributes{
+			Origin:  "INCOMPLETE",
+			NextHop: "2001:db8::1",
+		},
+	}
+	if diff := cmp.Diff(want, u.v2(), cmp.AllowUnexported(updateV2{})); diff != "" {
+		t.Errorf("v2() diff: (-want +got)\n%s", diff)
+	}
+	if len(u.Announced) != 1 || len(u.Withdrawn) != 1 {
+		t.Errorf("v2() changed the v1 update prefixes: %v, %v", u.Announced, u.Withdrawn)
+	}
+}
@@ -0,0 +1,49 @@
+package converter
+
+import "fmt"
+
+// Format is the version of the schema of converted updates and RIB entries.
+type Format string
+
+const (
+	// FormatV1 keeps every path attribute as its type and GoBGP JSON.
+	FormatV1 Format = "v1"
+	// FormatV2 has typed columns of the common path attributes, and keeps the
+	// others as in FormatV1.
+	FormatV2 Format = "v2"
+
+	// DefaultFormat is the format of conversions which do not set one. It
+	// stays FormatV1, which existing buckets and tables hold; FormatV2 is
+	// opt-in.
+	DefaultFormat = FormatV1
+)
+
+// FormatMetadataKey maps to the format of a converted object, in its GCS
+// metadata.
+const FormatMetadataKey = "routingDataFormat"
+
+// ParseFormat returns the format named s, or DefaultFormat if s is empty.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case "":
+		return DefaultFormat, nil
+	case FormatV1, FormatV2:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q, want %s or %s", s, FormatV1, FormatV2)
+}
+
+// formatRow returns the row of v in format. Rows without attributes are the
+// same in every format.
+func formatRow(v interface{}, format Format) interface{} {
+	if format == FormatV1 {
+		return v
+	}
+	switch r := v.(type) {
+	case *update:
+		return r.v2()
+	case *ribEntry:
+		return r.v2()
+	}
+	return v
+}
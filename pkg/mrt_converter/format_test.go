@@ -0,0 +1,27 @@
+package converter
+
+import "testing"
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{in: "", want: DefaultFormat},
+		{in: "v1", want: FormatV1},
+		{in: "v2", want: FormatV2},
+		{in: "v3", wantErr: true},
+		{in: "V2", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseFormat(test.in)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("ParseFormat(%q) = err %v; wantErr = %v", test.in, err, test.wantErr)
+		}
+		if got != test.want {
+			t.Errorf("ParseFormat(%q) = %s; want %s", test.in, got, test.want)
+		}
+	}
+}
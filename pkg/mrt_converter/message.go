@@ -0,0 +1,267 @@
+package converter
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+	log "github.com/sirupsen/logrus"
+)
+
+// bgpMessageTypes are the names of the BGP message types, of RFC 4271 and
+// RFC 2918.
+var bgpMessageTypes = map[uint8]string{
+	bgp.BGP_MSG_OPEN:          "open",
+	bgp.BGP_MSG_UPDATE:        "update",
+	bgp.BGP_MSG_NOTIFICATION:  "notification",
+	bgp.BGP_MSG_KEEPALIVE:     "keepalive",
+	bgp.BGP_MSG_ROUTE_REFRESH: "route_refresh",
+}
+
+// messageDirs are the directories of the BGP messages converted as session
+// events. Other messages than these and updates are only counted.
+var messageDirs = map[uint8]string{
+	bgp.BGP_MSG_OPEN:         OpensDir,
+	bgp.BGP_MSG_NOTIFICATION: NotificationsDir,
+}
+
+// sessionOpen represents the BGP OPEN message of a peer, which sets up the
+// capabilities of its session. It will be written as JSON, which will then
+// be picked up by BigQuery, in a table of its own.
+type sessionOpen struct {
+	Collector string
+	SeenAt    time.Time
+	PeerIP    string
+	PeerAS    uint32
+
+	// Data inside BGP OPENs. MyAS is AS_TRANS for 4-octet ASNs.
+	Version  uint8
+	MyAS     uint16
+	HoldTime uint16
+	RouterID string
+
+	// Decoded capabilities: the 4-octet ASN, the multiprotocol families and
+	// the ADD-PATH modes by family. Every capability is also kept as JSON.
+	FourOctetAS  uint32
+	Families     []string
+	AddPaths     []*addPath
+	Capabilities []*capabilityPayload
+}
+
+// addPath is the ADD-PATH mode of a family, e.g. receive/send.
+type addPath struct {
+	Family string
+	Mode   string
+}
+
+// capabilityPayload represents a capability of an OPEN. It contains the
+// capability code, its name and JSON of the capability.
+type capabilityPayload struct {
+	Code    bgp.BGPCapabilityCode
+	Name    string
+	Payload string
+}
+
+// notification represents the BGP NOTIFICATION message of a peer, the error
+// which closes its session. It will be written as JSON, which will then be
+// picked up by BigQuery, in a table of its own.
+type notification struct {
+	Collector string
+	SeenAt    time.Time
+	PeerIP    string
+	PeerAS    uint32
+
+	// Data inside BGP NOTIFICATIONs. Message is the shutdown communication
+	// of an administrative shutdown or reset, of RFC 9003, and Data is the
+	// hex of the data.
+	Code        uint8
+	Subcode     uint8
+	CodeName    string
+	SubcodeName string
+	Message     string
+	Data        string
+}
+
+// notificationCodes are the names of the NOTIFICATION error codes.
+var notificationCodes = map[uint8]string{
+	bgp.BGP_ERROR_MESSAGE_HEADER_ERROR:        "MESSAGE_HEADER_ERROR",
+	bgp.BGP_ERROR_OPEN_MESSAGE_ERROR:          "OPEN_MESSAGE_ERROR",
+	bgp.BGP_ERROR_UPDATE_MESSAGE_ERROR:        "UPDATE_MESSAGE_ERROR",
+	bgp.BGP_ERROR_HOLD_TIMER_EXPIRED:          "HOLD_TIMER_EXPIRED",
+	bgp.BGP_ERROR_FSM_ERROR:                   "FSM_ERROR",
+	bgp.BGP_ERROR_CEASE:                       "CEASE",
+	bgp.BGP_ERROR_ROUTE_REFRESH_MESSAGE_ERROR: "ROUTE_REFRESH_MESSAGE_ERROR",
+}
+
+// notificationSubcodes are the names of the NOTIFICATION error subcodes, by
+// error code, of RFC 4271, RFC 4486, RFC 6608, RFC 7313 and RFC 8538. The
+// subcode 0 is unspecific to every code.
+var notificationSubcodes = map[uint8]map[uint8]string{
+	bgp.BGP_ERROR_MESSAGE_HEADER_ERROR: {
+		bgp.BGP_ERROR_SUB_CONNECTION_NOT_SYNCHRONIZED: "CONNECTION_NOT_SYNCHRONIZED",
+		bgp.BGP_ERROR_SUB_BAD_MESSAGE_LENGTH:          "BAD_MESSAGE_LENGTH",
+		bgp.BGP_ERROR_SUB_BAD_MESSAGE_TYPE:            "BAD_MESSAGE_TYPE",
+	},
+	bgp.BGP_ERROR_OPEN_MESSAGE_ERROR: {
+		bgp.BGP_ERROR_SUB_UNSUPPORTED_VERSION_NUMBER:        "UNSUPPORTED_VERSION_NUMBER",
+		bgp.BGP_ERROR_SUB_BAD_PEER_AS:                       "BAD_PEER_AS",
+		bgp.BGP_ERROR_SUB_BAD_BGP_IDENTIFIER:                "BAD_BGP_IDENTIFIER",
+		bgp.BGP_ERROR_SUB_UNSUPPORTED_OPTIONAL_PARAMETER:    "UNSUPPORTED_OPTIONAL_PARAMETER",
+		bgp.BGP_ERROR_SUB_DEPRECATED_AUTHENTICATION_FAILURE: "AUTHENTICATION_FAILURE",
+		bgp.BGP_ERROR_SUB_UNACCEPTABLE_HOLD_TIME:            "UNACCEPTABLE_HOLD_TIME",
+		bgp.BGP_ERROR_SUB_UNSUPPORTED_CAPABILITY:            "UNSUPPORTED_CAPABILITY",
+	},
+	bgp.BGP_ERROR_UPDATE_MESSAGE_ERROR: {
+		bgp.BGP_ERROR_SUB_MALFORMED_ATTRIBUTE_LIST:          "MALFORMED_ATTRIBUTE_LIST",
+		bgp.BGP_ERROR_SUB_UNRECOGNIZED_WELL_KNOWN_ATTRIBUTE: "UNRECOGNIZED_WELL_KNOWN_ATTRIBUTE",
+		bgp.BGP_ERROR_SUB_MISSING_WELL_KNOWN_ATTRIBUTE:      "MISSING_WELL_KNOWN_ATTRIBUTE",
+		bgp.BGP_ERROR_SUB_ATTRIBUTE_FLAGS_ERROR:             "ATTRIBUTE_FLAGS_ERROR",
+		bgp.BGP_ERROR_SUB_ATTRIBUTE_LENGTH_ERROR:            "ATTRIBUTE_LENGTH_ERROR",
+		bgp.BGP_ERROR_SUB_INVALID_ORIGIN_ATTRIBUTE:          "INVALID_ORIGIN_ATTRIBUTE",
+		bgp.BGP_ERROR_SUB_DEPRECATED_ROUTING_LOOP:           "AS_ROUTING_LOOP",
+		bgp.BGP_ERROR_SUB_INVALID_NEXT_HOP_ATTRIBUTE:        "INVALID_NEXT_HOP_ATTRIBUTE",
+		bgp.BGP_ERROR_SUB_OPTIONAL_ATTRIBUTE_ERROR:          "OPTIONAL_ATTRIBUTE_ERROR",
+		bgp.BGP_ERROR_SUB_INVALID_NETWORK_FIELD:             "INVALID_NETWORK_FIELD",
+		bgp.BGP_ERROR_SUB_MALFORMED_AS_PATH:                 "MALFORMED_AS_PATH",
+	},
+	bgp.BGP_ERROR_FSM_ERROR: {
+		bgp.BGP_ERROR_SUB_RECEIVE_UNEXPECTED_MESSAGE_IN_OPENSENT_STATE:    "UNEXPECTED_MESSAGE_IN_OPENSENT",
+		bgp.BGP_ERROR_SUB_RECEIVE_UNEXPECTED_MESSAGE_IN_OPENCONFIRM_STATE: "UNEXPECTED_MESSAGE_IN_OPENCONFIRM",
+		bgp.BGP_ERROR_SUB_RECEIVE_UNEXPECTED_MESSAGE_IN_ESTABLISHED_STATE: "UNEXPECTED_MESSAGE_IN_ESTABLISHED",
+	},
+	bgp.BGP_ERROR_CEASE: {
+		bgp.BGP_ERROR_SUB_MAXIMUM_NUMBER_OF_PREFIXES_REACHED: "MAXIMUM_NUMBER_OF_PREFIXES_REACHED",
+		bgp.BGP_ERROR_SUB_ADMINISTRATIVE_SHUTDOWN:            "ADMINISTRATIVE_SHUTDOWN",
+		bgp.BGP_ERROR_SUB_PEER_DECONFIGURED:                  "PEER_DECONFIGURED",
+		bgp.BGP_ERROR_SUB_ADMINISTRATIVE_RESET:               "ADMINISTRATIVE_RESET",
+		bgp.BGP_ERROR_SUB_CONNECTION_REJECTED:                "CONNECTION_REJECTED",
+		bgp.BGP_ERROR_SUB_OTHER_CONFIGURATION_CHANGE:         "OTHER_CONFIGURATION_CHANGE",
+		bgp.BGP_ERROR_SUB_CONNECTION_COLLISION_RESOLUTION:    "CONNECTION_COLLISION_RESOLUTION",
+		bgp.BGP_ERROR_SUB_OUT_OF_RESOURCES:                   "OUT_OF_RESOURCES",
+		bgp.BGP_ERROR_SUB_HARD_RESET:                         "HARD_RESET",
+	},
+	bgp.BGP_ERROR_ROUTE_REFRESH_MESSAGE_ERROR: {
+		bgp.BGP_ERROR_SUB_INVALID_MESSAGE_LENGTH: "INVALID_MESSAGE_LENGTH",
+	},
+}
+
+// notificationNames returns the names of an error code and subcode, or their
+// numbers if unknown.
+func notificationNames(code, subcode uint8) (string, string) {
+	codeName, ok := notificationCodes[code]
+	if !ok {
+		codeName = strconv.Itoa(int(code))
+	}
+	if subcode == 0 {
+		return codeName, "UNSPECIFIC"
+	}
+	subcodeName, ok := notificationSubcodes[code][subcode]
+	if !ok {
+		subcodeName = strconv.Itoa(int(subcode))
+	}
+	return codeName, subcodeName
+}
+
+// parseBGP4MPMessage parses a BGP4MP MESSAGE or MESSAGE_AS4 record.
+func parseBGP4MPMessage(h *mrt.MRTHeader, buf []byte) (*mrt.BGP4MPMessage, error) {
+	msg, err := parseBGP4MP(h, buf)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := msg.Body.(*mrt.BGP4MPMessage)
+	if !ok || m.BGPMessage == nil {
+		return nil, fmt.Errorf("not a BGP message: subtype %d", h.SubType)
+	}
+	return m, nil
+}
+
+// parseMessage converts a pair of MRT header and MESSAGE or MESSAGE_AS4
+// record into a BigQuery compatible row of its BGP message: an update, an
+// OPEN or a NOTIFICATION. It returns the type of the BGP message, and no row
+// for other types, such as KEEPALIVEs.
+func parseMessage(collector string, h *mrt.MRTHeader, buf []byte) (interface{}, uint8, error) {
+	m, err := parseBGP4MPMessage(h, buf)
+	if err != nil {
+		return nil, 0, err
+	}
+	typ := m.BGPMessage.Header.Type
+	switch body := m.BGPMessage.Body.(type) {
+	case *bgp.BGPUpdate:
+		u, err := updateRow(collector, h, m, body)
+		if err != nil {
+			return nil, typ, err
+		}
+		return u, typ, nil
+	case *bgp.BGPOpen:
+		return openRow(collector, h, m, body), typ, nil
+	case *bgp.BGPNotification:
+		return notificationRow(collector, h, m, body), typ, nil
+	}
+	return nil, typ, nil
+}
+
+func openRow(collector string, h *mrt.MRTHeader, m *mrt.BGP4MPMessage, open *bgp.BGPOpen) *sessionOpen {
+	res := &sessionOpen{
+		Collector: collector,
+		SeenAt:    h.GetTime(),
+		PeerIP:    m.PeerIpAddress.String(),
+		PeerAS:    m.PeerAS,
+		Version:   open.Version,
+		MyAS:      open.MyAS,
+		HoldTime:  open.HoldTime,
+		RouterID:  open.ID.String(),
+	}
+	for _, p := range open.OptParams {
+		caps, ok := p.(*bgp.OptionParameterCapability)
+		if !ok {
+			continue
+		}
+		for _, c := range caps.Capability {
+			switch c := c.(type) {
+			case *bgp.CapFourOctetASNumber:
+				res.FourOctetAS = c.CapValue
+			case *bgp.CapMultiProtocol:
+				res.Families = append(res.Families, c.CapValue.String())
+			case *bgp.CapAddPath:
+				for _, t := range c.Tuples {
+					res.AddPaths = append(res.AddPaths, &addPath{Family: t.RouteFamily.String(), Mode: t.Mode.String()})
+				}
+			}
+			p, err := json.Marshal(c)
+			if err != nil {
+				log.Error(err)
+			}
+			res.Capabilities = append(res.Capabilities, &capabilityPayload{
+				Code:    c.Code(),
+				Name:    c.Code().String(),
+				Payload: string(p),
+			})
+		}
+	}
+	return res
+}
+
+func notificationRow(collector string, h *mrt.MRTHeader, m *mrt.BGP4MPMessage, n *bgp.BGPNotification) *notification {
+	res := &notification{
+		Collector: collector,
+		SeenAt:    h.GetTime(),
+		PeerIP:    m.PeerIpAddress.String(),
+		PeerAS:    m.PeerAS,
+		Code:      n.ErrorCode,
+		Subcode:   n.ErrorSubcode,
+		Data:      hex.EncodeToString(n.Data),
+	}
+	res.CodeName, res.SubcodeName = notificationNames(n.ErrorCode, n.ErrorSubcode)
+	// A shutdown communication is its length, followed by UTF-8.
+	if n.ErrorCode == bgp.BGP_ERROR_CEASE && (n.ErrorSubcode == bgp.BGP_ERROR_SUB_ADMINISTRATIVE_SHUTDOWN || n.ErrorSubcode == bgp.BGP_ERROR_SUB_ADMINISTRATIVE_RESET) && len(n.Data) > 0 {
+		if l := int(n.Data[0]); l < len(n.Data) && utf8.Valid(n.Data[1:1+l]) {
+			res.Message = string(n.Data[1 : 1+l])
+		}
+	}
+	return res
+}
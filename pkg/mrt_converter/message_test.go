@@ -0,0 +1,183 @@
+package converter
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+)
+
+// Fake MRT BGP4MP messages of other types than updates.
+var (
+	fakeCaps = []bgp.ParameterCapabilityInterface{
+		bgp.NewCapMultiProtocol(bgp.RF_IPv4_UC),
+		bgp.NewCapMultiProtocol(bgp.RF_IPv6_UC),
+		bgp.NewCapRouteRefresh(),
+		bgp.NewCapFourOctetASNumber(100000),
+		bgp.NewCapAddPath([]*bgp.CapAddPathTuple{bgp.NewCapAddPathTuple(bgp.RF_IPv6_UC, bgp.BGP_ADD_PATH_BOTH)}),
+	}
+	fakeOpen = mrt.NewBGP4MPMessage(100000, 6447, 0, "1.0.0.0", "2.0.0.0", true, bgp.NewBGPOpenMessage(23456, 180, "192.0.2.1", []bgp.OptionParameterInterface{
+		bgp.NewOptionParameterCapability(fakeCaps),
+	}))
+	// An administrative shutdown, with a shutdown communication.
+	fakeShutdown = mrt.NewBGP4MPMessage(100000, 6447, 0, "1.0.0.0", "2.0.0.0", true, bgp.NewBGPNotificationMessage(
+		bgp.BGP_ERROR_CEASE, bgp.BGP_ERROR_SUB_ADMINISTRATIVE_SHUTDOWN, append([]byte{11}, "maintenance"...)))
+	fakeKeepalive    = mrt.NewBGP4MPMessage(100000, 6447, 0, "1.0.0.0", "2.0.0.0", true, bgp.NewBGPKeepAliveMessage())
+	fakeRouteRefresh = mrt.NewBGP4MPMessage(100000, 6447, 0, "1.0.0.0", "2.0.0.0", true, bgp.NewBGPRouteRefreshMessage(bgp.AFI_IP, 0, bgp.SAFI_UNICAST))
+)
+
+func capPayloads(t *testing.T, caps ...bgp.ParameterCapabilityInterface) []*capabilityPayload {
+	t.Helper()
+	var res []*capabilityPayload
+	for _, c := range caps {
+		p, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, &capabilityPayload{Code: c.Code(), Name: c.Code().String(), Payload: string(p)})
+	}
+	return res
+}
+
+// wantOpen is the row of fakeOpen.
+func wantOpen(t *testing.T, collector string, seenAt time.Time) *sessionOpen {
+	return &sessionOpen{
+		Collector:    collector,
+		SeenAt:       seenAt,
+		PeerIP:       "1.0.0.0",
+		PeerAS:       100000,
+		Version:      4,
+		MyAS:         23456,
+		HoldTime:     180,
+		RouterID:     "192.0.2.1",
+		FourOctetAS:  100000,
+		Families:     []string{"ipv4-unicast", "ipv6-unicast"},
+		AddPaths:     []*addPath{{Family: "ipv6-unicast", Mode: "receive/send"}},
+		Capabilities: capPayloads(t, fakeCaps...),
+	}
+}
+
+// wantShutdown is the row of fakeShutdown.
+func wantShutdown(collector string, seenAt time.Time) *notification {
+	return &notification{
+		Collector:   collector,
+		SeenAt:      seenAt,
+		PeerIP:      "1.0.0.0",
+		PeerAS:      100000,
+		Code:        bgp.BGP_ERROR_CEASE,
+		Subcode:     bgp.BGP_ERROR_SUB_ADMINISTRATIVE_SHUTDOWN,
+		CodeName:    "CEASE",
+		SubcodeName: "ADMINISTRATIVE_SHUTDOWN",
+		Message:     "maintenance",
+		Data:        hex.EncodeToString(append([]byte{11}, "maintenance"...)),
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	fakeTime := time.Unix(time.Now().Unix(), 0)
+	encode := func(m *mrt.BGP4MPMessage) []byte {
+		return encodeBGP4MP(t, m)
+	}
+	unknown := encode(mrt.NewBGP4MPMessage(15169, 6447, 0, "1.0.0.0", "2.0.0.0", false, bgp.NewBGPNotificationMessage(9, 3, nil)))
+	open := encode(fakeOpen)
+	state := encodeBody(t, mrt.NewBGP4MPStateChange(15169, 6447, 0, "1.0.0.0", "2.0.0.0", false, mrt.OPENCONFIRM, mrt.ESTABLISHED))
+	tests := []struct {
+		desc     string
+		header   *mrt.MRTHeader
+		body     []byte
+		want     interface{}
+		wantType uint8
+		wantErr  bool
+	}{
+		{
+			desc:     "update",
+			header:   fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, len(encode(fakeAS4Withdrawal))),
+			body:     encode(fakeAS4Withdrawal),
+			want:     &update{Collector: "route-views2", SeenAt: fakeTime, PeerAS: 100000, Withdrawn: []string{"30.0.0.0/24", "40.0.0.0/24"}},
+			wantType: bgp.BGP_MSG_UPDATE,
+		},
+		{
+			desc:     "OPEN with capabilities",
+			header:   fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, len(open)),
+			body:     open,
+			want:     wantOpen(t, "route-views2", fakeTime),
+			wantType: bgp.BGP_MSG_OPEN,
+		},
+		{
+			desc:     "NOTIFICATION with a shutdown communication",
+			header:   fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, len(encode(fakeShutdown))),
+			body:     encode(fakeShutdown),
+			want:     wantShutdown("route-views2", fakeTime),
+			wantType: bgp.BGP_MSG_NOTIFICATION,
+		},
+		{
+			desc:   "NOTIFICATION of unknown codes",
+			header: fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE, len(unknown)),
+			body:   unknown,
+			want: &notification{
+				Collector:   "route-views2",
+				SeenAt:      fakeTime,
+				PeerIP:      "1.0.0.0",
+				PeerAS:      15169,
+				Code:        9,
+				Subcode:     3,
+				CodeName:    "9",
+				SubcodeName: "3",
+			},
+			wantType: bgp.BGP_MSG_NOTIFICATION,
+		},
+		{
+			desc:     "KEEPALIVE",
+			header:   fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, len(encode(fakeKeepalive))),
+			body:     encode(fakeKeepalive),
+			wantType: bgp.BGP_MSG_KEEPALIVE,
+		},
+		{
+			desc:     "ROUTE-REFRESH",
+			header:   fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, len(encode(fakeRouteRefresh))),
+			body:     encode(fakeRouteRefresh),
+			wantType: bgp.BGP_MSG_ROUTE_REFRESH,
+		},
+		{
+			desc:    "truncated OPEN",
+			header:  fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, len(open)-4),
+			body:    open[:len(open)-4],
+			wantErr: true,
+		},
+		{
+			desc:    "not a BGP message",
+			header:  fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.STATE_CHANGE, len(state)),
+			body:    state,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, gotType, err := parseMessage("route-views2", test.header, test.body)
+			if gotErr := err != nil; test.wantErr != gotErr {
+				t.Errorf("parseMessage() = err %v; wantErr = %v", err, test.wantErr)
+			}
+			if gotType != test.wantType {
+				t.Errorf("parseMessage() = type %d; want %d", gotType, test.wantType)
+			}
+			if diff := cmp.Diff(test.want, got, rowCmpOpts); diff != "" {
+				t.Errorf("parseMessage diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseUpdateOfOtherMessages(t *testing.T) {
+	fakeTime := time.Unix(time.Now().Unix(), 0)
+	for _, m := range []*mrt.BGP4MPMessage{fakeOpen, fakeShutdown, fakeKeepalive, fakeRouteRefresh} {
+		body := encodeBGP4MP(t, m)
+		if _, err := parseUpdate("route-views2", fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, len(body)), body); err == nil {
+			t.Errorf("parseUpdate(%T) = nil err; want non-nil err", m.BGPMessage.Body)
+		}
+	}
+}
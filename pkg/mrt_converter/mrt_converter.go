@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"bufio"
 	"bytes"
 	"compress/bzip2"
 	"compress/gzip"
@@ -8,14 +9,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"cloud.google.com/go/storage"
 	"github.com/osrg/gobgp/pkg/packet/bgp"
 	"github.com/osrg/gobgp/pkg/packet/mrt"
 
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
 	log "github.com/sirupsen/logrus"
 )
@@ -23,6 +27,14 @@ import (
 // ProjectMetadataKey maps to the project source in an archive's GCS metadata.
 const ProjectMetadataKey = "routingDataProject"
 
+// SourceObjectMetadataKey maps to the archive a converted object was
+// created from, in the converted object's GCS metadata.
+const SourceObjectMetadataKey = "routingDataSource"
+
+// SourceProjectMetadataKey maps to the project of the archive a converted
+// object was created from, in the converted object's GCS metadata.
+const SourceProjectMetadataKey = "routingDataSourceProject"
+
 // attributePayload represents path attribute data to be saved in BigQuery. It
 // contains an attribute type and JSON of the BGP attribute.
 type attributePayload struct {
@@ -41,18 +53,30 @@ type update struct {
 	Announced  []string
 	Withdrawn  []string
 	Attributes []*attributePayload
+
+	// attrs are the decoded path attributes, for the other formats.
+	attrs []bgp.PathAttributeInterface
 }
 
 type Config struct {
-	SrcBucket string
-	DstBucket string
+	// Src holds the archives, and Dst receives the converted updates.
+	Src       objstore.ObjectStore
+	Dst       objstore.ObjectStore
 	SrcObject string
+	// Project of SrcObject, as in its ArchiveStored event. It is read from
+	// the metadata of SrcObject if empty.
+	Project string
+	// Metadata tracks the conversion state of SrcObject, if set.
+	Metadata metadata.Store
+	// Format is the format of the converted updates and RIB entries,
+	// DefaultFormat if empty.
+	Format Format
 }
 
-// routeViewsCollectorFromPath extracts the RV collector name from the input
+// RouteViewsCollectorFromPath extracts the RV collector name from the input
 // file path. The path will be treated like it has a preceding slash if it
 // doesn't have one.
-func routeViewsCollectorFromPath(filename string) (string, error) {
+func RouteViewsCollectorFromPath(filename string) (string, error) {
 	if filename == "" {
 		return "", fmt.Errorf("empty file path")
 	}
@@ -71,32 +95,34 @@ func routeViewsCollectorFromPath(filename string) (string, error) {
 	return dirs[1], nil
 }
 
-// readArchive reads from the source bucket and object. It returns the
-// collector name and its content reader if successful.
-func readArchive(ctx context.Context, gcsCli *storage.Client, bucket, object string) (string, io.Reader, error) {
-	obj := gcsCli.Bucket(bucket).Object(object)
-
-	// Read content from the object.
-	r, err := obj.NewReader(ctx)
-	if err != nil {
-		return "", nil, fmt.Errorf("NewReader(gs://%s/%s): %v", bucket, object, err)
-	}
-
+// readArchive reads object from the source store. It returns the project,
+// the collector name and its content reader if successful. The collector is
+// the one the upload server derived from the uploaded filename, or else is
+// derived from that filename, as the object may be named by a path prefix,
+// or else from the object name.
+func readArchive(ctx context.Context, st objstore.ObjectStore, object string) (string, string, io.ReadCloser, error) {
 	// Extract project type from the object metadata.
-	attrs, err := obj.Attrs(ctx)
+	attrs, err := st.Stat(ctx, object)
 	if err != nil {
-		return "", nil, fmt.Errorf("obj.Attrs: %v", err)
+		return "", "", nil, fmt.Errorf("Stat(%s): %v", st.URL(object), err)
 	}
 	projectType, ok := attrs.Metadata[ProjectMetadataKey]
 	if !ok {
-		return "", nil, fmt.Errorf("metadata '%s' is missing from gs://%s/%s", ProjectMetadataKey, bucket, object)
+		return "", "", nil, fmt.Errorf("metadata '%s' is missing from %s", ProjectMetadataKey, st.URL(object))
 	}
 	var collector string
 	switch projectType {
-	case pb.FileRequest_ROUTEVIEWS.String():
-		collector, err = routeViewsCollectorFromPath(object)
+	case pb.FileRequest_ROUTEVIEWS.String(), pb.FileRequest_ROUTEVIEWS_RIB.String():
+		if collector = attrs.Metadata[CollectorMetadataKey]; collector != "" {
+			break
+		}
+		fn := attrs.Metadata[FilenameMetadataKey]
+		if fn == "" {
+			fn = object
+		}
+		collector, err = RouteViewsCollectorFromPath(fn)
 		if err != nil {
-			return "", nil, err
+			return "", "", nil, err
 		}
 	default:
 		// If project type is unknown, we will just leave collector empty and
@@ -104,7 +130,12 @@ func readArchive(ctx context.Context, gcsCli *storage.Client, bucket, object str
 		log.Warnf("unsupported project type %s", projectType)
 	}
 
-	return collector, r, nil
+	// Read content from the object, as stored.
+	r, err := st.Read(ctx, object)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("Read(%s): %v", st.URL(object), err)
+	}
+	return projectType, collector, r, nil
 }
 
 func translateAttrs(attrs []bgp.PathAttributeInterface) []*attributePayload {
@@ -133,13 +164,18 @@ func translatePrefixes(prefixes []*bgp.IPAddrPrefix) []string {
 	return res
 }
 
-// parseUpdate converts a pair of MRT header and message into a BigQuery
-// compatible update. A BGP4MP_ET message will be treated as a BGP4MP message,
-// and the microsecond field will be ignored.
-func parseUpdate(collector string, h *mrt.MRTHeader, buf []byte) (*update, error) {
+// parseBGP4MP parses a BGP4MP or BGP4MP_ET record. A BGP4MP_ET record is
+// parsed as a BGP4MP record, and h is changed to match.
+func parseBGP4MP(h *mrt.MRTHeader, buf []byte) (msg *mrt.MRTMessage, err error) {
 	if h == nil {
 		return nil, fmt.Errorf("header cannot be nil")
 	}
+	// GoBGP message decoders may index past short values.
+	defer func() {
+		if r := recover(); r != nil {
+			msg, err = nil, fmt.Errorf("malformed BGP4MP record: %v", r)
+		}
+	}()
 	// Force GoBGP to parse BGP4MP_ET message. We do not need the extended
 	// timestamp.
 	if h.Type == mrt.BGP4MP_ET {
@@ -151,83 +187,199 @@ func parseUpdate(collector string, h *mrt.MRTHeader, buf []byte) (*update, error
 		buf = buf[4:]
 	}
 
-	msg, err := mrt.ParseMRTBody(h, buf)
+	msg, err = mrt.ParseMRTBody(h, buf)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse body: %v", err)
 	}
+	return msg, nil
+}
 
-	mrtMsg := msg.Body.(*mrt.BGP4MPMessage)
-	bgpUpdate := mrtMsg.BGPMessage.Body.(*bgp.BGPUpdate)
+// parseUpdate converts a pair of MRT header and message into a BigQuery
+// compatible update. A BGP4MP_ET message will be treated as a BGP4MP message,
+// and the microsecond field will be ignored. The AS_PATH of a MESSAGE, from a
+// session of 2-octet ASNs, is merged with its AS4_PATH.
+func parseUpdate(collector string, h *mrt.MRTHeader, buf []byte) (*update, error) {
+	mrtMsg, err := parseBGP4MPMessage(h, buf)
+	if err != nil {
+		return nil, err
+	}
+	bgpUpdate, ok := mrtMsg.BGPMessage.Body.(*bgp.BGPUpdate)
+	if !ok {
+		return nil, fmt.Errorf("not an update: BGP message type %d", mrtMsg.BGPMessage.Header.Type)
+	}
+	return updateRow(collector, h, mrtMsg, bgpUpdate)
+}
+
+func updateRow(collector string, h *mrt.MRTHeader, mrtMsg *mrt.BGP4MPMessage, bgpUpdate *bgp.BGPUpdate) (*update, error) {
+	attrs := bgpUpdate.PathAttributes
+	// Paths of sessions with 2-octet ASNs carry 4-octet ASNs in AS4_PATH.
+	if h.SubType == uint16(mrt.MESSAGE) {
+		var err error
+		if attrs, err = mergeAS4Path(attrs); err != nil {
+			return nil, err
+		}
+	}
 	return &update{
 		SeenAt:     h.GetTime(),
 		PeerAS:     mrtMsg.PeerAS,
 		Collector:  collector,
 		Announced:  translatePrefixes(bgpUpdate.NLRI),
 		Withdrawn:  translatePrefixes(bgpUpdate.WithdrawnRoutes),
-		Attributes: translateAttrs(bgpUpdate.PathAttributes),
+		Attributes: translateAttrs(attrs),
+		attrs:      attrs,
 	}, nil
 }
 
 type bzReaderFunc func(_ io.Reader) io.Reader
 
-func convertNext(r io.Reader, w io.Writer, collector string) error {
+// nextFunc converts the next MRT record of the decompressed archive r into
+// JSONL rows written to w. It returns io.EOF at the end of the archive.
+type nextFunc func(r io.Reader, w io.Writer) error
+
+// readRecord reads the next MRT record of r, returning its header and body.
+func readRecord(r io.Reader) (*mrt.MRTHeader, []byte, error) {
 	buf := make([]byte, mrt.MRT_COMMON_HEADER_LEN)
 	_, err := io.ReadFull(r, buf)
 	if err == io.EOF {
-		return err
+		return nil, nil, err
 	} else if err != nil {
-		return fmt.Errorf("failed to read MRT header: %v", err)
+		return nil, nil, fmt.Errorf("failed to read MRT header: %v", err)
 	}
 
 	h := &mrt.MRTHeader{}
 	err = h.DecodeFromBytes(buf)
 	if err != nil {
-		return fmt.Errorf("(*mrt.MRTHeader).DecodeFromBytes: %v", err)
+		return nil, nil, fmt.Errorf("(*mrt.MRTHeader).DecodeFromBytes: %v", err)
 	}
 
 	buf = make([]byte, h.Len)
 	_, err = io.ReadFull(r, buf)
 	if err != nil {
-		return fmt.Errorf("failed to read MRT body: %v", err)
+		return nil, nil, fmt.Errorf("failed to read MRT body: %v", err)
 	}
+	return h, buf, nil
+}
 
-	// We only parse updates at the moment.
-	if (h.Type != mrt.BGP4MP && h.Type != mrt.BGP4MP_ET) ||
-		(h.SubType != uint16(mrt.MESSAGE_AS4) && h.SubType != uint16(mrt.MESSAGE)) {
-		log.WithFields(log.Fields{"type": h.Type, "subType": h.SubType}).Debug("unsupported message types")
-		return nil
+// writeRow writes v to w as a line of JSONL.
+func writeRow(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %v", err)
+	}
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("writer.Write: %v", err)
 	}
+	return nil
+}
 
-	update, err := parseUpdate(collector, h, buf)
+// convertNext converts the next record of an archive of updates. Updates are
+// written to w, and session state changes to sw.
+func convertNext(r io.Reader, w io.Writer, sw map[string]io.Writer, collector string, format Format) error {
+	h, buf, err := readRecord(r)
 	if err != nil {
-		log.Debug(fmt.Errorf("failed to parse update: %v, bytes: %v", err, buf))
-		return nil
+		return err
 	}
 
-	b, err := json.Marshal(update)
+	// We only parse BGP messages and state changes at the moment.
+	var (
+		row     interface{}
+		dst     io.Writer
+		msgType uint8
+	)
+	switch {
+	case h.Type != mrt.BGP4MP && h.Type != mrt.BGP4MP_ET:
+	case h.SubType == uint16(mrt.MESSAGE_AS4) || h.SubType == uint16(mrt.MESSAGE):
+		row, msgType, err = parseMessage(collector, h, buf)
+		dst = w
+		if dir, ok := messageDirs[msgType]; ok {
+			dst = sw[dir]
+		}
+	case h.SubType == uint16(mrt.STATE_CHANGE_AS4) || h.SubType == uint16(mrt.STATE_CHANGE):
+		row, err = parseStateChange(collector, h, buf)
+		dst = sw[StateChangesDir]
+	}
+	if dst == nil {
+		log.WithFields(log.Fields{"type": h.Type, "subType": h.SubType}).Debug("unsupported message types")
+		metrics.MessagesSkipped.WithLabelValues(collector, "unsupported_type").Inc()
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("json.Marshal: %v", err)
+		log.Debug(fmt.Errorf("failed to parse message: %v, bytes: %v", err, buf))
+		metrics.MessagesSkipped.WithLabelValues(collector, "parse_error").Inc()
+		return nil
 	}
-	// Write as JSONL.
-	if _, err := w.Write(append(b, '\n')); err != nil {
-		return fmt.Errorf("writer.Write: %v", err)
+	if row == nil {
+		// KEEPALIVE and ROUTE-REFRESH messages are only counted.
+		metrics.MessagesSkipped.WithLabelValues(collector, bgpMessageTypes[msgType]).Inc()
+		return nil
 	}
+
+	if err := writeRow(dst, formatRow(row, format)); err != nil {
+		return err
+	}
+	metrics.MessagesConverted.WithLabelValues(collector).Inc()
 	return nil
 }
 
 // Convert translates the bzip'ed MRT raw bytes into a BigQuery compatible
-// format and write to the destination.
-func Convert(collector string, r io.Reader, dst io.Writer) {
-	convert(collector, r, dst, bzip2.NewReader)
+// format and write to the destination, with updates in format. Session events
+// are written to the writer of their directory in sessionDst, see SessionDirs,
+// or dropped if it has none.
+func Convert(collector string, format Format, r io.Reader, dst io.Writer, sessionDst map[string]io.Writer) {
+	convert(collector, format, r, dst, sessionDst, bzip2.NewReader)
+}
+
+func convert(collector string, format Format, r io.Reader, dst io.Writer, sessionDst map[string]io.Writer, bzip2Reader bzReaderFunc) {
+	convertUpdates(collector, format, bzip2Reader(r), dst, sessionDst)
+}
+
+// rowCounter counts the rows written to a writer, one per Write.
+type rowCounter struct {
+	io.Writer
+	n int
+}
+
+func (c *rowCounter) Write(b []byte) (int, error) {
+	c.n++
+	return c.Writer.Write(b)
+}
+
+// convertUpdates converts the decompressed archive of updates r, and writes
+// the gzip'ed rows of updates in format to dst and of session events to the
+// writers of their directories in sessionDst. It returns the number of session
+// events by directory.
+func convertUpdates(collector string, format Format, r io.Reader, dst io.Writer, sessionDst map[string]io.Writer) map[string]int {
+	sw := make(map[string]io.Writer)
+	counters := make(map[string]*rowCounter)
+	for _, dir := range SessionDirs {
+		w, ok := sessionDst[dir]
+		if !ok || w == nil {
+			w = ioutil.Discard
+		}
+		gw := gzip.NewWriter(w)
+		defer gw.Close()
+		counters[dir] = &rowCounter{Writer: gw}
+		sw[dir] = counters[dir]
+	}
+	convertRecords(r, dst, func(r io.Reader, w io.Writer) error {
+		return convertNext(r, w, sw, collector, format)
+	})
+
+	res := make(map[string]int)
+	for dir, c := range counters {
+		res[dir] = c.n
+	}
+	return res
 }
 
-func convert(collector string, r io.Reader, dst io.Writer, bzip2Reader bzReaderFunc) {
-	br := bzip2Reader(r)
+// convertRecords converts every record of the decompressed archive r with
+// next, and writes the gzip'ed rows to dst.
+func convertRecords(r io.Reader, dst io.Writer, next nextFunc) {
 	gw := gzip.NewWriter(dst)
 	defer gw.Close()
 
 	for {
-		err := convertNext(br, gw, collector)
+		err := next(r, gw)
 		if err != nil {
 			if err != io.EOF {
 				log.Errorf("cannot convert message: %v", err)
@@ -239,45 +391,116 @@ func convert(collector string, r io.Reader, dst io.Writer, bzip2Reader bzReaderF
 
 // ObjExists checks if a converted archive already exists at the
 // destination.
-func ObjExists(ctx context.Context, gcsCli *storage.Client, object, bucket string) (bool, error) {
-	_, err := gcsCli.Bucket(bucket).Object(object).Attrs(ctx)
+func ObjExists(ctx context.Context, st objstore.ObjectStore, object string) (bool, error) {
+	_, err := st.Stat(ctx, object)
+	if err == objstore.ErrNotExist {
+		return false, nil
+	}
 	if err != nil {
-		if err == storage.ErrObjectNotExist {
-			return false, nil
-		}
-		return false, fmt.Errorf("cannot open gs://%s/%s: %v", bucket, object, err)
+		return false, fmt.Errorf("cannot open %s: %v", st.URL(object), err)
 	}
 	return true, nil
 }
 
-// ProcessMRTArchive converts an MRT dump into updates on GCS, which will later
+// ConvertedObject returns the name of the converted updates of the archive
+// object, ie: updates.20211101.0000.gz for updates.20211101.0000.bz2.
+func ConvertedObject(object string) string {
+	return strings.Replace(object, filepath.Ext(object), ".gz", 1)
+}
+
+// ProcessMRTArchive converts an MRT dump into updates in cfg.Dst, which will later
 // be picked up by BigQuery automatically. ProcessMRTDump converts on a best-
-// effort basis as it will convert as much as it can from every archive, and it only
-// supports archives of updates.
-func ProcessMRTArchive(ctx context.Context, gcsCli *storage.Client, cfg *Config) error {
-	return processMRTArchive(ctx, gcsCli, cfg, bzip2.NewReader)
+// effort basis as it will convert as much as it can from every archive. Session
+// events, if any, are written to their own objects, see SessionObject. Archives
+// of TABLE_DUMP_V2 RIB dumps are converted into RIB entries instead, told apart
+// by the type of their first MRT record.
+func ProcessMRTArchive(ctx context.Context, cfg *Config) error {
+	return processMRTArchive(ctx, cfg, bzip2.NewReader)
 }
 
-func processMRTArchive(ctx context.Context, gcsCli *storage.Client, cfg *Config, br bzReaderFunc) error {
-	dstObject := strings.Replace(cfg.SrcObject, filepath.Ext(cfg.SrcObject), ".gz", 1)
-	if found, err := ObjExists(ctx, gcsCli, dstObject, cfg.DstBucket); err != nil {
+func processMRTArchive(ctx context.Context, cfg *Config, br bzReaderFunc) error {
+	if Reserved(cfg.SrcObject) {
+		log.Infof("skipped %s, which is not an archive", cfg.Src.URL(cfg.SrcObject))
+		return nil
+	}
+	dstObject := ConvertedObject(cfg.SrcObject)
+	if found, err := ObjExists(ctx, cfg.Dst, dstObject); err != nil {
 		return fmt.Errorf("ObjExists: %v", err)
 	} else if found {
-		log.Warnf("converted archive gs://%s/%s already exists.", cfg.DstBucket, dstObject)
+		log.Warnf("converted archive %s already exists.", cfg.Dst.URL(dstObject))
 		return nil
 	}
 
-	collector, reader, err := readArchive(ctx, gcsCli, cfg.SrcBucket, cfg.SrcObject)
+	project, collector, reader, err := readArchive(ctx, cfg.Src, cfg.SrcObject)
+	if cfg.Project != "" {
+		project = cfg.Project
+	}
+	// failed records the failure of the conversion.
+	failed := func(err error) error {
+		metadata.Track(ctx, cfg.Metadata, &metadata.Record{Project: project, Object: cfg.SrcObject, Status: metadata.StatusFailed, Error: err.Error()})
+		return err
+	}
 	if err != nil {
-		return fmt.Errorf("readArchive(%s, %s): %v", cfg.SrcBucket, cfg.SrcObject, err)
+		return failed(fmt.Errorf("readArchive(%s): %v", cfg.Src.URL(cfg.SrcObject), err))
 	}
-
+	defer reader.Close()
+	metadata.Track(ctx, cfg.Metadata, &metadata.Record{
+		Project:   project,
+		Object:    cfg.SrcObject,
+		Status:    metadata.StatusConverting,
+		Collector: collector,
+	})
+
+	format := cfg.Format
+	if format == "" {
+		format = DefaultFormat
+	}
+	start := time.Now()
 	buf := bytes.NewBuffer(nil)
-	convert(collector, reader, buf, br)
+	sessions := make(map[string]*bytes.Buffer)
+	sessionDst := make(map[string]io.Writer)
+	for _, dir := range SessionDirs {
+		sessions[dir] = bytes.NewBuffer(nil)
+		sessionDst[dir] = sessions[dir]
+	}
+	var n map[string]int
+	dr := bufio.NewReader(br(reader))
+	head, _ := dr.Peek(mrt.MRT_COMMON_HEADER_LEN)
+	if mrtType(head) == MRTTypeRIB {
+		c := &ribConverter{collector: collector, format: format}
+		convertRecords(dr, buf, c.convertNext)
+	} else {
+		n = convertUpdates(collector, format, dr, buf, sessionDst)
+	}
+	metrics.ConversionLatency.WithLabelValues(collector).Observe(time.Since(start).Seconds())
 
-	// Only write messages if the whole conversion is done.
-	dst := gcsCli.Bucket(cfg.DstBucket).Object(dstObject).NewWriter(ctx)
-	dst.Write(buf.Bytes())
-	defer dst.Close()
+	// Only write messages if the whole conversion is done. The updates are
+	// written last, as their object marks the archive converted.
+	for _, dir := range SessionDirs {
+		if n[dir] == 0 {
+			continue
+		}
+		if err := writeConverted(ctx, cfg, project, SessionObject(cfg.SrcObject, dir), sessions[dir].Bytes(), format); err != nil {
+			return failed(err)
+		}
+	}
+	if err := writeConverted(ctx, cfg, project, dstObject, buf.Bytes(), format); err != nil {
+		return failed(err)
+	}
+	metadata.Track(ctx, cfg.Metadata, &metadata.Record{Project: project, Object: cfg.SrcObject, Status: metadata.StatusConverted})
+	return nil
+}
+
+// writeConverted stores converted content to the destination store, along
+// with the name of its source archive and its format.
+func writeConverted(ctx context.Context, cfg *Config, project, dstObject string, b []byte, format Format) error {
+	attrs := &objstore.Attrs{Metadata: map[string]string{
+		SourceObjectMetadataKey:  cfg.SrcObject,
+		SourceProjectMetadataKey: project,
+		FormatMetadataKey:        string(format),
+	}}
+	if _, err := cfg.Dst.Put(ctx, dstObject, bytes.NewReader(b), attrs); err != nil {
+		return fmt.Errorf("failed to write %s: %v", cfg.Dst.URL(dstObject), err)
+	}
 	return nil
 }
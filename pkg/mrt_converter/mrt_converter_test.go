@@ -12,11 +12,16 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/osrg/gobgp/pkg/packet/bgp"
 	"github.com/osrg/gobgp/pkg/packet/mrt"
 
 	"github.com/fsouza/fake-gcs-server/fakestorage"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/routeviews/google-cloud-storage/pkg/metadata"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
 	log "github.com/sirupsen/logrus"
 )
@@ -53,7 +58,9 @@ var (
 	}))
 
 	gobgpCmpOpts = cmp.AllowUnexported(bgp.IPAddrPrefix{}, bgp.PrefixDefault{})
-	fakeBzip     = func(r io.Reader) io.Reader { return r }
+	// rowCmpOpts ignore the decoded attributes of rows.
+	rowCmpOpts = cmpopts.IgnoreUnexported(update{}, ribEntry{})
+	fakeBzip   = func(r io.Reader) io.Reader { return r }
 )
 
 var (
@@ -62,11 +69,8 @@ var (
 		Payload: marshalAttr(bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
 			&bgp.As4PathParam{Type: bgp.BGP_ASPATH_ATTR_TYPE_SEQ, Num: 1, AS: []uint32{100000}}})),
 	}
-	twoOctetASPath = &attributePayload{
-		AttrType: bgp.BGP_ATTR_TYPE_AS_PATH,
-		Payload: marshalAttr(bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
-			&bgp.AsPathParam{Type: bgp.BGP_ASPATH_ATTR_TYPE_SEQ, Num: 1, AS: []uint16{23456}}})),
-	}
+	// The AS_PATH of 2-octet ASNs, [23456], merged with its AS4_PATH.
+	mergedASPath    = fourOctetASPath
 	twoOctetAS4Path = &attributePayload{
 		AttrType: bgp.BGP_ATTR_TYPE_AS4_PATH,
 		Payload: marshalAttr(bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{
@@ -165,7 +169,7 @@ func TestParseUpdate(t *testing.T) {
 				SeenAt:     fakeTime,
 				PeerAS:     15169,
 				Announced:  []string{"30.0.0.0/24", "40.0.0.0/24"},
-				Attributes: []*attributePayload{twoOctetAS4Path, twoOctetASPath},
+				Attributes: []*attributePayload{twoOctetAS4Path, mergedASPath},
 			},
 		},
 		{
@@ -223,7 +227,7 @@ func TestParseUpdate(t *testing.T) {
 			if gotErr := err != nil; test.wantErr != gotErr {
 				t.Errorf("parseUpdate() = err %v; wantErr = %v", err, test.wantErr)
 			}
-			if diff := cmp.Diff(test.want, got, gobgpCmpOpts); diff != "" {
+			if diff := cmp.Diff(test.want, got, gobgpCmpOpts, rowCmpOpts); diff != "" {
 				t.Errorf("parseUpdate diff: (-got +want)\n%s", diff)
 			}
 		})
@@ -264,9 +268,9 @@ func TestRouteViewsCollectorFromPath(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
-			got, err := routeViewsCollectorFromPath(test.path)
+			got, err := RouteViewsCollectorFromPath(test.path)
 			if gotErr := err != nil; test.wantErr != gotErr || got != test.want {
-				t.Errorf("routeViewsCollectorFromPath(%s) = '%s', %v; want '%s', wantErr = %v", test.path, got, err, test.want, test.wantErr)
+				t.Errorf("RouteViewsCollectorFromPath(%s) = '%s', %v; want '%s', wantErr = %v", test.path, got, err, test.want, test.wantErr)
 			}
 		})
 	}
@@ -306,6 +310,71 @@ func makeResponse(t *testing.T, updates []*update) []byte {
 	return res
 }
 
+// makeRows returns the JSONL of the rows.
+func makeRows(t *testing.T, rows []interface{}) []byte {
+	t.Helper()
+	var res []byte
+	for _, r := range rows {
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, append(b, '\n')...)
+	}
+	return res
+}
+
+func TestReadArchiveCollector(t *testing.T) {
+	tests := []struct {
+		desc     string
+		object   string
+		metadata map[string]string
+		want     string
+	}{
+		{
+			desc:   "object path",
+			object: "route-views.sg/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			want:   "route-views.sg",
+		},
+		{
+			desc:     "uploaded filename",
+			object:   "ROUTEVIEWS/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			metadata: map[string]string{FilenameMetadataKey: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2"},
+			want:     "route-views2",
+		},
+		{
+			desc:   "collector metadata",
+			object: "ROUTEVIEWS/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			metadata: map[string]string{
+				FilenameMetadataKey:  "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+				CollectorMetadataKey: "route-views.sg",
+			},
+			want: "route-views.sg",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			md := map[string]string{ProjectMetadataKey: pb.FileRequest_ROUTEVIEWS.String()}
+			for k, v := range test.metadata {
+				md[k] = v
+			}
+			fakegcs := fakestorage.NewServer([]fakestorage.Object{{
+				ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "src-bucket", Name: test.object, Metadata: md},
+				Content:     []byte("archive"),
+			}})
+			t.Cleanup(fakegcs.Stop)
+			_, got, rd, err := readArchive(context.Background(), objstore.NewGCS(fakegcs.Client(), "src-bucket"), test.object)
+			if err != nil {
+				t.Fatalf("readArchive(%s): %v", test.object, err)
+			}
+			rd.Close()
+			if got != test.want {
+				t.Errorf("readArchive(%s) collector = %q; want %q", test.object, got, test.want)
+			}
+		})
+	}
+}
+
 func TestConvertMRT(t *testing.T) {
 	fakeTime := time.Now()
 	unextended := time.Unix(fakeTime.Unix(), 0)
@@ -314,6 +383,8 @@ func TestConvertMRT(t *testing.T) {
 		collector string
 		archive   []byte
 		want      []*update
+		// wantSessions are the session events by directory, written apart.
+		wantSessions map[string][]interface{}
 	}{
 		{
 			desc:      "convert an archive with one AS4 update",
@@ -336,7 +407,7 @@ func TestConvertMRT(t *testing.T) {
 				SeenAt:     unextended,
 				PeerAS:     15169,
 				Announced:  []string{"30.0.0.0/24", "40.0.0.0/24"},
-				Attributes: []*attributePayload{twoOctetAS4Path, twoOctetASPath},
+				Attributes: []*attributePayload{twoOctetAS4Path, mergedASPath},
 			}},
 		},
 		{
@@ -381,7 +452,7 @@ func TestConvertMRT(t *testing.T) {
 				SeenAt:     unextended,
 				PeerAS:     15169,
 				Announced:  []string{"30.0.0.0/24", "40.0.0.0/24"},
-				Attributes: []*attributePayload{twoOctetAS4Path, twoOctetASPath},
+				Attributes: []*attributePayload{twoOctetAS4Path, mergedASPath},
 			}, {
 				Collector:  "route-views3",
 				SeenAt:     unextended,
@@ -401,7 +472,7 @@ func TestConvertMRT(t *testing.T) {
 				SeenAt:     unextended,
 				PeerAS:     15169,
 				Announced:  []string{"30.0.0.0/24", "40.0.0.0/24"},
-				Attributes: []*attributePayload{twoOctetAS4Path, twoOctetASPath},
+				Attributes: []*attributePayload{twoOctetAS4Path, mergedASPath},
 			}},
 		}, {
 			desc:      "incomplete message - bad body",
@@ -432,8 +503,24 @@ func TestConvertMRT(t *testing.T) {
 			collector: "route-views3",
 			archive: concatMsgs(
 				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Withdrawal)),
+				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.TABLE_DUMPv2, mrt.PEER_INDEX_TABLE, fakePeers)),
+			),
+			want: []*update{{
+				Collector:  "route-views3",
+				SeenAt:     unextended,
+				PeerAS:     100000,
+				Withdrawn:  []string{"30.0.0.0/24", "40.0.0.0/24"},
+				Attributes: nil,
+			}},
+		}, {
+			desc:      "convert state changes apart from updates",
+			collector: "route-views3",
+			archive: concatMsgs(
 				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.STATE_CHANGE,
-					mrt.NewBGP4MPStateChange(15169, 6447, 0, "1.0.0.0", "2.0.0.0", true, mrt.CONNECT, mrt.ACTIVE))),
+					mrt.NewBGP4MPStateChange(15169, 6447, 0, "1.0.0.0", "2.0.0.0", false, mrt.CONNECT, mrt.ACTIVE))),
+				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Withdrawal)),
+				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP_ET, mrt.STATE_CHANGE_AS4,
+					mrt.NewBGP4MPStateChange(100000, 6447, 0, "2001:db8::1", "2001:db8::2", true, mrt.OPENCONFIRM, mrt.ESTABLISHED))),
 			),
 			want: []*update{{
 				Collector:  "route-views3",
@@ -442,6 +529,43 @@ func TestConvertMRT(t *testing.T) {
 				Withdrawn:  []string{"30.0.0.0/24", "40.0.0.0/24"},
 				Attributes: nil,
 			}},
+			wantSessions: map[string][]interface{}{
+				StateChangesDir: {&stateChange{
+					Collector: "route-views3",
+					SeenAt:    unextended,
+					PeerIP:    "1.0.0.0",
+					PeerAS:    15169,
+					OldState:  "CONNECT",
+					NewState:  "ACTIVE",
+				}, &stateChange{
+					Collector: "route-views3",
+					SeenAt:    unextended,
+					PeerIP:    "2001:db8::1",
+					PeerAS:    100000,
+					OldState:  "OPENCONFIRM",
+					NewState:  "ESTABLISHED",
+				}},
+			},
+		}, {
+			desc:      "convert OPENs and NOTIFICATIONs apart from updates, skip KEEPALIVEs",
+			collector: "route-views3",
+			archive: concatMsgs(
+				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, fakeOpen)),
+				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, fakeKeepalive)),
+				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Withdrawal)),
+				encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP_ET, mrt.MESSAGE_AS4, fakeShutdown)),
+			),
+			want: []*update{{
+				Collector:  "route-views3",
+				SeenAt:     unextended,
+				PeerAS:     100000,
+				Withdrawn:  []string{"30.0.0.0/24", "40.0.0.0/24"},
+				Attributes: nil,
+			}},
+			wantSessions: map[string][]interface{}{
+				OpensDir:         {wantOpen(t, "route-views3", unextended)},
+				NotificationsDir: {wantShutdown("route-views3", unextended)},
+			},
 		},
 	}
 	for _, test := range tests {
@@ -450,7 +574,13 @@ func TestConvertMRT(t *testing.T) {
 			// bzip2 encoder, and it will be difficult to create test data compressed by
 			// bzip2, so we disable bzip2 in tests.
 			buf := bytes.NewBuffer(nil)
-			convert(test.collector, bytes.NewBuffer(test.archive), buf, fakeBzip)
+			sessions := make(map[string]*bytes.Buffer)
+			sessionDst := make(map[string]io.Writer)
+			for _, dir := range SessionDirs {
+				sessions[dir] = bytes.NewBuffer(nil)
+				sessionDst[dir] = sessions[dir]
+			}
+			convert(test.collector, FormatV1, bytes.NewBuffer(test.archive), buf, sessionDst, fakeBzip)
 
 			// Decompress written data.
 			got := decompressed(t, buf)
@@ -458,10 +588,47 @@ func TestConvertMRT(t *testing.T) {
 			if string(want) != string(got) {
 				t.Errorf("convert() outputs mismatched:\nwant: %s\ngot: %s", string(want), string(got))
 			}
+			for _, dir := range SessionDirs {
+				got := decompressed(t, sessions[dir])
+				want := makeRows(t, test.wantSessions[dir])
+				if string(want) != string(got) {
+					t.Errorf("convert() %s mismatched:\nwant: %s\ngot: %s", dir, string(want), string(got))
+				}
+			}
 		})
 	}
 }
 
+func TestConvertMetrics(t *testing.T) {
+	fakeTime := time.Now()
+	collector := "route-views.metrics"
+	archive := concatMsgs(
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Withdrawal)),
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.TABLE_DUMPv2, mrt.PEER_INDEX_TABLE, fakePeers)),
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, fakeKeepalive)),
+		// Wrong withdrawn routes length (100).
+		[]byte{97, 157, 202, 61, 0, 16, 0, 4, 0, 0, 0, 43, 0, 1, 134, 160, 0, 0,
+			25, 47, 0, 0, 0, 1, 1, 0, 0, 0, 2, 0, 0, 0, 255, 255, 255, 255, 255, 255, 255,
+			255, 255, 255, 255, 255, 255, 255, 255, 255,
+			0, 23, 2, 100, 0, 0, 0},
+	)
+	convert(collector, FormatV1, bytes.NewBuffer(archive), ioutil.Discard, nil, fakeBzip)
+
+	for _, c := range []struct {
+		name string
+		got  float64
+	}{
+		{"converted", testutil.ToFloat64(metrics.MessagesConverted.WithLabelValues(collector))},
+		{"unsupported_type", testutil.ToFloat64(metrics.MessagesSkipped.WithLabelValues(collector, "unsupported_type"))},
+		{"parse_error", testutil.ToFloat64(metrics.MessagesSkipped.WithLabelValues(collector, "parse_error"))},
+		{"keepalive", testutil.ToFloat64(metrics.MessagesSkipped.WithLabelValues(collector, "keepalive"))},
+	} {
+		if c.got != 1 {
+			t.Errorf("%s messages = %v; want 1", c.name, c.got)
+		}
+	}
+}
+
 type badWriter struct {
 	err error
 }
@@ -473,7 +640,7 @@ func (w *badWriter) Write([]byte) (int, error) {
 func TestConvertMRTErrors(t *testing.T) {
 	t.Run("bad writer", func(t *testing.T) {
 		dst := &badWriter{err: fmt.Errorf("GCS not available")}
-		err := convertNext(bytes.NewReader(encodeMRTMessage(t, fakeMRTMessage(t, time.Now(), mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Withdrawal))), dst, "routeviews.sg")
+		err := convertNext(bytes.NewReader(encodeMRTMessage(t, fakeMRTMessage(t, time.Now(), mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Withdrawal))), dst, nil, "routeviews.sg", FormatV1)
 		if err == nil {
 			t.Error("convert() => nil err; want non-nil err")
 		}
@@ -505,21 +672,43 @@ func TestProcessMRTArchive(t *testing.T) {
 	})
 	t.Cleanup(fakegcs.Stop)
 	fakeCli := fakegcs.Client()
+	meta := metadata.NewMemStore()
 
-	err := processMRTArchive(ctx, fakeCli, &Config{
-		SrcBucket: srcBucket,
-		DstBucket: dstBucket,
+	err := processMRTArchive(ctx, &Config{
+		Src:       objstore.NewGCS(fakeCli, srcBucket),
+		Dst:       objstore.NewGCS(fakeCli, dstBucket),
 		SrcObject: srcObject,
+		Metadata:  meta,
+		Format:    FormatV2,
 	}, fakeBzip)
 	if err != nil {
 		t.Error(err)
 	}
-	wantUpdates := []*update{{
-		Collector:  "route-views2",
-		SeenAt:     fakeTime,
-		PeerAS:     100000,
-		Announced:  []string{"10.0.0.0/24", "20.0.0.0/24"},
-		Attributes: []*attributePayload{fourOctetASPath},
+	rec, err := meta.Get(ctx, pb.FileRequest_ROUTEVIEWS.String(), srcObject)
+	if err != nil {
+		t.Fatalf("meta.Get(%s): %v", srcObject, err)
+	}
+	wantRec := &metadata.Record{
+		Project:   pb.FileRequest_ROUTEVIEWS.String(),
+		Object:    srcObject,
+		Status:    metadata.StatusConverted,
+		Collector: "route-views2",
+	}
+	if diff := cmp.Diff(wantRec, rec, cmpopts.IgnoreFields(metadata.Record{}, "Updated")); diff != "" {
+		t.Errorf("tracked metadata diff (-want +got):\n%s", diff)
+	}
+	// Updates are converted in the format of the config.
+	originAS := uint32(100000)
+	wantUpdates := []interface{}{&updateV2{
+		Collector: "route-views2",
+		SeenAt:    fakeTime,
+		PeerAS:    100000,
+		Announced: []string{"10.0.0.0/24", "20.0.0.0/24"},
+		pathAttributes: pathAttributes{
+			ASPath:     []*asPathSegment{{Type: "AS_SEQUENCE", ASNs: []uint32{100000}}},
+			ASPathASNs: []uint32{100000},
+			OriginAS:   &originAS,
+		},
 	}}
 
 	// Check if converted archive is expected.
@@ -527,15 +716,24 @@ func TestProcessMRTArchive(t *testing.T) {
 	if err != nil {
 		t.Fatalf("fakegcs.GetObject(%s, %s): %v", dstBucket, wantObject, err)
 	}
-	want := makeResponse(t, wantUpdates)
+	want := makeRows(t, wantUpdates)
 	if got := decompressed(t, bytes.NewBuffer(gotObj.Content)); string(want) != string(got) {
 		t.Errorf("ProcessMRTArchive() outputs mismatched:\nwant: %s\ngot: %s", string(want), string(got))
 	}
+	if src := gotObj.Metadata[SourceObjectMetadataKey]; src != srcObject {
+		t.Errorf("got metadata %s=%s; want %s", SourceObjectMetadataKey, src, srcObject)
+	}
+	if proj := gotObj.Metadata[SourceProjectMetadataKey]; proj != pb.FileRequest_ROUTEVIEWS.String() {
+		t.Errorf("got metadata %s=%s; want %s", SourceProjectMetadataKey, proj, pb.FileRequest_ROUTEVIEWS)
+	}
+	if f := gotObj.Metadata[FormatMetadataKey]; f != string(FormatV2) {
+		t.Errorf("got metadata %s=%s; want %s", FormatMetadataKey, f, FormatV2)
+	}
 
 	// Converted archive already exists; conversion should be skipped.
-	err = processMRTArchive(ctx, fakeCli, &Config{
-		SrcBucket: srcBucket,
-		DstBucket: dstBucket,
+	err = processMRTArchive(ctx, &Config{
+		Src:       objstore.NewGCS(fakeCli, srcBucket),
+		Dst:       objstore.NewGCS(fakeCli, dstBucket),
 		SrcObject: srcObject,
 	}, fakeBzip)
 	if err != nil {
@@ -548,6 +746,31 @@ func TestProcessMRTArchive(t *testing.T) {
 	if got := decompressed(t, bytes.NewBuffer(gotObj.Content)); string(want) != string(got) {
 		t.Errorf("ProcessMRTArchive() outputs mismatched:\nwant: %s\ngot: %s", string(want), string(got))
 	}
+
+	// Objects kept by the upload server next to the archives are skipped.
+	tomb := RetractedPrefix + "1/" + srcObject
+	fakegcs.CreateObject(fakestorage.Object{
+		ObjectAttrs: fakestorage.ObjectAttrs{
+			BucketName: srcBucket,
+			Name:       tomb,
+			Metadata: map[string]string{
+				ProjectMetadataKey: pb.FileRequest_ROUTEVIEWS.String(),
+			},
+		},
+		Content: fakeMRT,
+	})
+	err = processMRTArchive(ctx, &Config{
+		Src:       objstore.NewGCS(fakeCli, srcBucket),
+		Dst:       objstore.NewGCS(fakeCli, dstBucket),
+		SrcObject: tomb,
+		Metadata:  meta,
+	}, fakeBzip)
+	if err != nil {
+		t.Errorf("processMRTArchive(%s): %v; want nil err", tomb, err)
+	}
+	if _, err := fakegcs.GetObject(dstBucket, ConvertedObject(tomb)); err == nil {
+		t.Errorf("%s was converted", tomb)
+	}
 }
 
 func TestProcessMRTArchiveErrors(t *testing.T) {
@@ -585,14 +808,21 @@ func TestProcessMRTArchiveErrors(t *testing.T) {
 				Name: "test-bucket",
 			})
 			t.Cleanup(fakegcs.Stop)
-			err := processMRTArchive(ctx, fakegcs.Client(), &Config{
-				SrcBucket: "src-bucket",
+			meta := metadata.NewMemStore()
+			err := processMRTArchive(ctx, &Config{
+				Src:       objstore.NewGCS(fakegcs.Client(), "src-bucket"),
 				SrcObject: test.filename,
-				DstBucket: "test-bucket",
+				// As set by the ArchiveStored event of the archive.
+				Project:  pb.FileRequest_ROUTEVIEWS.String(),
+				Dst:      objstore.NewGCS(fakegcs.Client(), "test-bucket"),
+				Metadata: meta,
 			}, fakeBzip)
 			if err == nil {
 				t.Error("ProcessMRTArchive() = nil err; want non-nil err")
 			}
+			if rec, err := meta.Get(ctx, pb.FileRequest_ROUTEVIEWS.String(), test.filename); err != nil || rec.Status != metadata.StatusFailed {
+				t.Errorf("tracked metadata = %+v, %v; want status %s", rec, err, metadata.StatusFailed)
+			}
 		})
 	}
 }
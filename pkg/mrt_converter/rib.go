@@ -0,0 +1,301 @@
+package converter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// ribEntry represents a route of a RIB dump, one per peer of each prefix. It will be written as JSON, which will then be picked up by
+// BigQuery, in a table of its own.
+type ribEntry struct {
+	Collector    string
+	DumpedAt     time.Time
+	OriginatedAt time.Time
+	Prefix       string
+	PeerIP       string
+	PeerAS       uint32
+	Attributes   []*attributePayload
+
+	// attrs are the decoded path attributes, for the other formats.
+	attrs []bgp.PathAttributeInterface
+}
+
+// ribFamilies are the route families of the TABLE_DUMP_V2 subtypes which are
+// converted.
+var ribFamilies = map[mrt.MRTSubTypeTableDumpv2]bgp.RouteFamily{
+	mrt.RIB_IPV4_UNICAST:   bgp.RF_IPv4_UC,
+	mrt.RIB_IPV4_MULTICAST: bgp.RF_IPv4_MC,
+	mrt.RIB_IPV6_UNICAST:   bgp.RF_IPv6_UC,
+	mrt.RIB_IPV6_MULTICAST: bgp.RF_IPv6_MC,
+}
+
+// tableDumpSubtype is the subtype of a TABLE_DUMP record, the address family
+// of its route. GoBGP only defines the subtypes of TABLE_DUMP_V2.
+type tableDumpSubtype uint16
+
+func (t tableDumpSubtype) ToUint16() uint16 {
+	return uint16(t)
+}
+
+const (
+	tableDumpIPv4 tableDumpSubtype = 1
+	tableDumpIPv6 tableDumpSubtype = 2
+)
+
+// ribConverter converts the records of a RIB dump, of either TABLE_DUMP_V2
+// or legacy TABLE_DUMP records, into RIB entries in format. TABLE_DUMP_V2 RIB
+// entries refer to their peers by index into the PEER_INDEX_TABLE record,
+// which comes first in a dump.
+type ribConverter struct {
+	collector string
+	format    Format
+	peers     []*mrt.Peer
+}
+
+func (c *ribConverter) convertNext(r io.Reader, w io.Writer) error {
+	h, buf, err := readRecord(r)
+	if err != nil {
+		return err
+	}
+
+	subType := mrt.MRTSubTypeTableDumpv2(h.SubType)
+	if h.Type == mrt.TABLE_DUMPv2 && subType == mrt.PEER_INDEX_TABLE {
+		msg, err := mrt.ParseMRTBody(h, buf)
+		if err != nil {
+			log.Debug(fmt.Errorf("failed to parse peer index: %v, bytes: %v", err, buf))
+			metrics.MessagesSkipped.WithLabelValues(c.collector, "parse_error").Inc()
+			return nil
+		}
+		c.peers = msg.Body.(*mrt.PeerIndexTable).Peers
+		return nil
+	}
+
+	var entries []*ribEntry
+	_, ok := ribFamilies[subType]
+	switch {
+	case h.Type == mrt.TABLE_DUMP:
+		var e *ribEntry
+		e, err = parseTableDump(c.collector, h, buf)
+		entries = []*ribEntry{e}
+	case h.Type == mrt.TABLE_DUMPv2 && ok:
+		entries, err = parseRIB(c.collector, h, buf, c.peers)
+	default:
+		log.WithFields(log.Fields{"type": h.Type, "subType": h.SubType}).Debug("unsupported message types")
+		metrics.MessagesSkipped.WithLabelValues(c.collector, "unsupported_type").Inc()
+		return nil
+	}
+	if err != nil {
+		log.Debug(fmt.Errorf("failed to parse RIB: %v, bytes: %v", err, buf))
+		metrics.MessagesSkipped.WithLabelValues(c.collector, "parse_error").Inc()
+		return nil
+	}
+	for _, e := range entries {
+		if err := writeRow(w, formatRow(e, c.format)); err != nil {
+			return err
+		}
+	}
+	metrics.MessagesConverted.WithLabelValues(c.collector).Inc()
+	return nil
+}
+
+// parseRIB converts a RIB_IPV4/IPV6 UNICAST or MULTICAST record into the
+// routes of its prefix, with their peers resolved through peers. The record is
+// decoded here rather than by GoBGP, which neither bounds checks the record
+// nor reads the abbreviated MP_REACH_NLRI of RFC 6396, section 4.3.4.
+func parseRIB(collector string, h *mrt.MRTHeader, buf []byte, peers []*mrt.Peer) (res []*ribEntry, err error) {
+	if h == nil {
+		return nil, fmt.Errorf("header cannot be nil")
+	}
+	rf, ok := ribFamilies[mrt.MRTSubTypeTableDumpv2(h.SubType)]
+	if h.Type != mrt.TABLE_DUMPv2 || !ok {
+		return nil, fmt.Errorf("not a RIB record: type %d, subtype %d", h.Type, h.SubType)
+	}
+	// GoBGP prefix and attribute decoders may index past short values.
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = nil, fmt.Errorf("malformed RIB record: %v", r)
+		}
+	}()
+
+	// Skip the sequence number.
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("short RIB record: %d bytes", len(buf))
+	}
+	buf = buf[4:]
+	afi, safi := bgp.RouteFamilyToAfiSafi(rf)
+	prefix, err := bgp.NewPrefixFromRouteFamily(afi, safi)
+	if err != nil {
+		return nil, err
+	}
+	if err := prefix.DecodeFromBytes(buf); err != nil {
+		return nil, fmt.Errorf("bad prefix: %v", err)
+	}
+	buf = buf[prefix.Len():]
+	if len(buf) < 2 {
+		return nil, fmt.Errorf("missing entry count")
+	}
+	n := int(binary.BigEndian.Uint16(buf))
+	buf = buf[2:]
+
+	for i := 0; i < n; i++ {
+		if len(buf) < 8 {
+			return nil, fmt.Errorf("short RIB entry %d: %d bytes", i, len(buf))
+		}
+		idx := binary.BigEndian.Uint16(buf)
+		originated := binary.BigEndian.Uint32(buf[2:])
+		l := int(binary.BigEndian.Uint16(buf[6:]))
+		buf = buf[8:]
+		if len(buf) < l {
+			return nil, fmt.Errorf("short attributes of RIB entry %d: %d of %d bytes", i, len(buf), l)
+		}
+		if int(idx) >= len(peers) {
+			return nil, fmt.Errorf("RIB entry %d refers to peer %d of %d", i, idx, len(peers))
+		}
+		attrs, err := parseRIBAttrs(afi, safi, buf[:l])
+		if err != nil {
+			return nil, fmt.Errorf("bad attributes of RIB entry %d: %v", i, err)
+		}
+		buf = buf[l:]
+		res = append(res, &ribEntry{
+			Collector:    collector,
+			DumpedAt:     h.GetTime(),
+			OriginatedAt: time.Unix(int64(originated), 0),
+			Prefix:       prefix.String(),
+			PeerIP:       peers[idx].IpAddress.String(),
+			PeerAS:       peers[idx].AS,
+			Attributes:   translateAttrs(attrs),
+			attrs:        attrs,
+		})
+	}
+	return res, nil
+}
+
+// parseRIBAttrs decodes the path attributes of a RIB entry. An MP_REACH_NLRI
+// attribute only holds its next hop there, its family is the RIB's.
+func parseRIBAttrs(afi uint16, safi uint8, buf []byte) ([]bgp.PathAttributeInterface, error) {
+	var res []bgp.PathAttributeInterface
+	for len(buf) > 0 {
+		if len(buf) < 3 {
+			return nil, fmt.Errorf("short attribute header: %d bytes", len(buf))
+		}
+		flags, typ := bgp.BGPAttrFlag(buf[0]), bgp.BGPAttrType(buf[1])
+		hl, l := 3, int(buf[2])
+		if flags&bgp.BGP_ATTR_FLAG_EXTENDED_LENGTH != 0 {
+			if len(buf) < 4 {
+				return nil, fmt.Errorf("short attribute header: %d bytes", len(buf))
+			}
+			hl, l = 4, int(binary.BigEndian.Uint16(buf[2:]))
+		}
+		if len(buf) < hl+l {
+			return nil, fmt.Errorf("short attribute %d: %d of %d bytes", typ, len(buf)-hl, l)
+		}
+		value := buf[hl : hl+l]
+
+		if typ == bgp.BGP_ATTR_TYPE_MP_REACH_NLRI && l > 0 && int(value[0]) == l-1 {
+			nh := value[1:]
+			p := &bgp.PathAttributeMpReachNLRI{
+				PathAttribute: bgp.PathAttribute{Flags: flags, Type: typ, Length: uint16(l)},
+				AFI:           afi,
+				SAFI:          safi,
+			}
+			switch len(nh) {
+			case 2 * net.IPv6len:
+				p.Nexthop = net.IP(nh[:net.IPv6len])
+				p.LinkLocalNexthop = net.IP(nh[net.IPv6len:])
+			case net.IPv6len, net.IPv4len:
+				p.Nexthop = net.IP(nh)
+			default:
+				return nil, fmt.Errorf("bad next hop length %d", len(nh))
+			}
+			res = append(res, p)
+		} else {
+			p, err := bgp.GetPathAttribute(buf)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.DecodeFromBytes(buf[:hl+l]); err != nil {
+				return nil, err
+			}
+			res = append(res, p)
+		}
+		buf = buf[hl+l:]
+	}
+	return res, nil
+}
+
+// parseTableDump converts a TABLE_DUMP record, the legacy format of a single
+// route with 2-octet ASNs, into its RIB entry.
+func parseTableDump(collector string, h *mrt.MRTHeader, buf []byte) (res *ribEntry, err error) {
+	if h == nil {
+		return nil, fmt.Errorf("header cannot be nil")
+	}
+	if h.Type != mrt.TABLE_DUMP {
+		return nil, fmt.Errorf("not a TABLE_DUMP record: type %d", h.Type)
+	}
+	var (
+		afi   uint16
+		ipLen int
+	)
+	switch tableDumpSubtype(h.SubType) {
+	case tableDumpIPv4:
+		afi, ipLen = bgp.AFI_IP, net.IPv4len
+	case tableDumpIPv6:
+		afi, ipLen = bgp.AFI_IP6, net.IPv6len
+	default:
+		return nil, fmt.Errorf("unsupported TABLE_DUMP subtype %d", h.SubType)
+	}
+	// GoBGP attribute decoders may index past short values.
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = nil, fmt.Errorf("malformed TABLE_DUMP record: %v", r)
+		}
+	}()
+
+	// The view and sequence numbers, prefix and its length, status,
+	// originated time, peer IP and AS, and the length of attributes.
+	if l := 4 + ipLen + 2 + 4 + ipLen + 2 + 2; len(buf) < l {
+		return nil, fmt.Errorf("short TABLE_DUMP record: %d of %d bytes", len(buf), l)
+	}
+	buf = buf[4:]
+	prefix, bits := net.IP(buf[:ipLen]), int(buf[ipLen])
+	if bits > 8*ipLen {
+		return nil, fmt.Errorf("bad prefix length %d", bits)
+	}
+	buf = buf[ipLen+2:]
+	originated := binary.BigEndian.Uint32(buf)
+	buf = buf[4:]
+	peerIP := net.IP(buf[:ipLen])
+	peerAS := binary.BigEndian.Uint16(buf[ipLen:])
+	l := int(binary.BigEndian.Uint16(buf[ipLen+2:]))
+	buf = buf[ipLen+4:]
+	if len(buf) < l {
+		return nil, fmt.Errorf("short attributes: %d of %d bytes", len(buf), l)
+	}
+
+	attrs, err := parseRIBAttrs(afi, bgp.SAFI_UNICAST, buf[:l])
+	if err != nil {
+		return nil, fmt.Errorf("bad attributes: %v", err)
+	}
+	if attrs, err = mergeAS4Path(attrs); err != nil {
+		return nil, err
+	}
+	return &ribEntry{
+		Collector:    collector,
+		DumpedAt:     h.GetTime(),
+		OriginatedAt: time.Unix(int64(originated), 0),
+		Prefix:       fmt.Sprintf("%s/%d", prefix, bits),
+		PeerIP:       peerIP.String(),
+		PeerAS:       uint32(peerAS),
+		Attributes:   translateAttrs(attrs),
+		attrs:        attrs,
+	}, nil
+}
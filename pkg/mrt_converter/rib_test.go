@@ -0,0 +1,459 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/osrg/gobgp/pkg/packet/bgp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+
+	"github.com/routeviews/google-cloud-storage/pkg/objstore"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+)
+
+// Fake TABLE_DUMP_V2 records.
+var (
+	fakePeers = mrt.NewPeerIndexTable("192.0.2.1", "", []*mrt.Peer{
+		mrt.NewPeer("10.0.0.1", "192.0.2.10", 100000, true),
+		mrt.NewPeer("10.0.0.2", "2001:db8::10", 15169, false),
+	})
+	ribAttrs = []bgp.PathAttributeInterface{
+		bgp.NewPathAttributeOrigin(0),
+		bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
+			bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{100000, 15169})}),
+	}
+	fakeRIBv4 = mrt.NewRib(1, bgp.NewIPAddrPrefix(24, "10.0.0.0"), []*mrt.RibEntry{
+		mrt.NewRibEntry(0, 1635724800, 0, ribAttrs, false),
+		mrt.NewRibEntry(1, 1635728400, 0, ribAttrs, false),
+	})
+	fakeRIBv6 = mrt.NewRib(2, bgp.NewIPv6AddrPrefix(32, "2001:db8::"), []*mrt.RibEntry{
+		mrt.NewRibEntry(1, 1635724800, 0, append([]bgp.PathAttributeInterface{
+			bgp.NewPathAttributeMpReachNLRI("2001:db8::1", []bgp.AddrPrefixInterface{bgp.NewIPv6AddrPrefix(32, "2001:db8::")}),
+		}, ribAttrs...), false),
+	})
+	// An entry referring to a peer missing from fakePeers.
+	fakeRIBBadPeer = mrt.NewRib(3, bgp.NewIPAddrPrefix(24, "20.0.0.0"), []*mrt.RibEntry{
+		mrt.NewRibEntry(2, 1635724800, 0, ribAttrs, false),
+	})
+)
+
+func encodeBody(t *testing.T, b mrt.Body) []byte {
+	t.Helper()
+	raw, err := b.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func translated(attrs ...bgp.PathAttributeInterface) []*attributePayload {
+	var res []*attributePayload
+	for _, a := range attrs {
+		res = append(res, &attributePayload{AttrType: a.GetType(), Payload: marshalAttr(a)})
+	}
+	return res
+}
+
+func TestParseRIB(t *testing.T) {
+	fakeTime := time.Unix(time.Now().Unix(), 0)
+	peers := fakePeers.Peers
+	v4 := encodeBody(t, fakeRIBv4)
+	tests := []struct {
+		desc    string
+		header  *mrt.MRTHeader
+		body    []byte
+		want    []*ribEntry
+		wantErr bool
+	}{
+		{
+			desc:   "RIB_IPV4_UNICAST entries of peers",
+			header: fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMPv2, mrt.RIB_IPV4_UNICAST, len(v4)),
+			body:   v4,
+			want: []*ribEntry{{
+				Collector:    "route-views2",
+				DumpedAt:     fakeTime,
+				OriginatedAt: time.Unix(1635724800, 0),
+				Prefix:       "10.0.0.0/24",
+				PeerIP:       "192.0.2.10",
+				PeerAS:       100000,
+				Attributes:   translated(ribAttrs...),
+			}, {
+				Collector:    "route-views2",
+				DumpedAt:     fakeTime,
+				OriginatedAt: time.Unix(1635728400, 0),
+				Prefix:       "10.0.0.0/24",
+				PeerIP:       "2001:db8::10",
+				PeerAS:       15169,
+				Attributes:   translated(ribAttrs...),
+			}},
+		},
+		{
+			desc:   "RIB_IPV6_UNICAST entry with a full MP_REACH_NLRI",
+			header: fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMPv2, mrt.RIB_IPV6_UNICAST, len(encodeBody(t, fakeRIBv6))),
+			body:   encodeBody(t, fakeRIBv6),
+			want: []*ribEntry{{
+				Collector:    "route-views2",
+				DumpedAt:     fakeTime,
+				OriginatedAt: time.Unix(1635724800, 0),
+				Prefix:       "2001:db8::/32",
+				PeerIP:       "2001:db8::10",
+				PeerAS:       15169,
+				Attributes: translated(append([]bgp.PathAttributeInterface{
+					bgp.NewPathAttributeMpReachNLRI("2001:db8::1", []bgp.AddrPrefixInterface{bgp.NewIPv6AddrPrefix(32, "2001:db8::")}),
+				}, ribAttrs...)...),
+			}},
+		},
+		{
+			desc:    "unknown peer",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMPv2, mrt.RIB_IPV4_UNICAST, len(encodeBody(t, fakeRIBBadPeer))),
+			body:    encodeBody(t, fakeRIBBadPeer),
+			wantErr: true,
+		},
+		{
+			desc:    "truncated entry",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMPv2, mrt.RIB_IPV4_UNICAST, len(v4)-1),
+			body:    v4[:len(v4)-1],
+			wantErr: true,
+		},
+		{
+			desc:    "truncated prefix",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMPv2, mrt.RIB_IPV4_UNICAST, 6),
+			body:    v4[:6],
+			wantErr: true,
+		},
+		{
+			desc:    "not a RIB",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMPv2, mrt.PEER_INDEX_TABLE, len(v4)),
+			body:    v4,
+			wantErr: true,
+		},
+		{
+			desc:    "bad MRT header",
+			body:    v4,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := parseRIB("route-views2", test.header, test.body, peers)
+			if gotErr := err != nil; test.wantErr != gotErr {
+				t.Errorf("parseRIB() = err %v; wantErr = %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got, rowCmpOpts); diff != "" {
+				t.Errorf("parseRIB diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseRIBAttrs(t *testing.T) {
+	nh := net.ParseIP("2001:db8::1")
+	ll := net.ParseIP("fe80::1")
+	tests := []struct {
+		desc    string
+		raw     []byte
+		want    []bgp.PathAttributeInterface
+		wantErr bool
+	}{
+		{
+			desc: "abbreviated MP_REACH_NLRI",
+			raw:  append([]byte{0x80, 0x0e, 17, 16}, nh...),
+			want: []bgp.PathAttributeInterface{&bgp.PathAttributeMpReachNLRI{
+				PathAttribute: bgp.PathAttribute{Flags: 0x80, Type: bgp.BGP_ATTR_TYPE_MP_REACH_NLRI, Length: 17},
+				Nexthop:       nh,
+				AFI:           bgp.AFI_IP6,
+				SAFI:          bgp.SAFI_UNICAST,
+			}},
+		},
+		{
+			desc: "abbreviated MP_REACH_NLRI with a link-local next hop",
+			raw:  append(append([]byte{0x80, 0x0e, 33, 32}, nh...), ll...),
+			want: []bgp.PathAttributeInterface{&bgp.PathAttributeMpReachNLRI{
+				PathAttribute:    bgp.PathAttribute{Flags: 0x80, Type: bgp.BGP_ATTR_TYPE_MP_REACH_NLRI, Length: 33},
+				Nexthop:          nh,
+				LinkLocalNexthop: ll,
+				AFI:              bgp.AFI_IP6,
+				SAFI:             bgp.SAFI_UNICAST,
+			}},
+		},
+		{
+			desc:    "bad next hop length",
+			raw:     []byte{0x80, 0x0e, 3, 2, 1, 2},
+			wantErr: true,
+		},
+		{
+			desc:    "truncated attribute",
+			raw:     []byte{0x40, 0x01, 1},
+			wantErr: true,
+		},
+		{
+			desc:    "truncated header",
+			raw:     []byte{0x40, 0x01},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := parseRIBAttrs(bgp.AFI_IP6, bgp.SAFI_UNICAST, test.raw)
+			if gotErr := err != nil; test.wantErr != gotErr {
+				t.Errorf("parseRIBAttrs() = err %v; wantErr = %v", err, test.wantErr)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(test.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("parseRIBAttrs() = %s; want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestProcessRIBArchive(t *testing.T) {
+	ctx := context.Background()
+	fakeTime := time.Unix(time.Now().Unix(), 0)
+	archive := concatMsgs(
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.TABLE_DUMPv2, mrt.PEER_INDEX_TABLE, fakePeers)),
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.TABLE_DUMPv2, mrt.RIB_IPV4_UNICAST, fakeRIBBadPeer)),
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.TABLE_DUMPv2, mrt.RIB_IPV6_UNICAST, fakeRIBv6)),
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Ann)),
+	)
+	srcObject := "route-views.sg/bgpdata/2021.11/RIBS/rib.20211101.0000.bz2"
+	wantObject := "route-views.sg/bgpdata/2021.11/RIBS/rib.20211101.0000.gz"
+	fakegcs := fakestorage.NewServer([]fakestorage.Object{{
+		ObjectAttrs: fakestorage.ObjectAttrs{
+			BucketName: "src-bucket",
+			Name:       srcObject,
+			Metadata:   map[string]string{ProjectMetadataKey: pb.FileRequest_ROUTEVIEWS_RIB.String()},
+		},
+		Content: archive,
+	}})
+	fakegcs.CreateBucketWithOpts(fakestorage.CreateBucketOpts{Name: "dst-bucket"})
+	t.Cleanup(fakegcs.Stop)
+
+	err := processMRTArchive(ctx, &Config{
+		Src:       objstore.NewGCS(fakegcs.Client(), "src-bucket"),
+		Dst:       objstore.NewGCS(fakegcs.Client(), "dst-bucket"),
+		SrcObject: srcObject,
+		Format:    FormatV1,
+	}, fakeBzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := fakegcs.GetObject("dst-bucket", wantObject)
+	if err != nil {
+		t.Fatalf("fakegcs.GetObject(dst-bucket, %s): %v", wantObject, err)
+	}
+
+	// Only the resolvable RIB entries are converted, updates are not part of
+	// a RIB dump.
+	var want []byte
+	for _, e := range []*ribEntry{{
+		Collector:    "route-views.sg",
+		DumpedAt:     fakeTime,
+		OriginatedAt: time.Unix(1635724800, 0),
+		Prefix:       "2001:db8::/32",
+		PeerIP:       "2001:db8::10",
+		PeerAS:       15169,
+		Attributes: translated(append([]bgp.PathAttributeInterface{
+			bgp.NewPathAttributeMpReachNLRI("2001:db8::1", []bgp.AddrPrefixInterface{bgp.NewIPv6AddrPrefix(32, "2001:db8::")}),
+		}, ribAttrs...)...),
+	}} {
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, append(b, '\n')...)
+	}
+	if got := decompressed(t, bytes.NewBuffer(obj.Content)); string(got) != string(want) {
+		t.Errorf("ProcessMRTArchive() outputs mismatched:\nwant: %s\ngot: %s", want, got)
+	}
+}
+
+// fakeTableDump is the body of a TABLE_DUMP record, which GoBGP does not
+// encode.
+type fakeTableDump struct {
+	prefix     string
+	bits       uint8
+	originated uint32
+	peerIP     string
+	peerAS     uint16
+	attrs      []bgp.PathAttributeInterface
+}
+
+func (d *fakeTableDump) DecodeFromBytes([]byte) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (d *fakeTableDump) Serialize() ([]byte, error) {
+	ip := func(s string) []byte {
+		if v4 := net.ParseIP(s).To4(); v4 != nil {
+			return v4
+		}
+		return net.ParseIP(s)
+	}
+	var attrs []byte
+	for _, a := range d.attrs {
+		raw, err := a.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, raw...)
+	}
+	// View and sequence numbers.
+	buf := []byte{0, 0, 0, 1}
+	buf = append(buf, ip(d.prefix)...)
+	buf = append(buf, d.bits, 1)
+	buf = binary.BigEndian.AppendUint32(buf, d.originated)
+	buf = append(buf, ip(d.peerIP)...)
+	buf = binary.BigEndian.AppendUint16(buf, d.peerAS)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(attrs)))
+	return append(buf, attrs...), nil
+}
+
+var (
+	// A route of a 2-octet ASN session, with a 4-octet ASN in AS4_PATH.
+	fakeTableDumpv4 = &fakeTableDump{
+		prefix: "10.0.0.0", bits: 8, originated: 1009843200, peerIP: "192.0.2.10", peerAS: 701,
+		attrs: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeOrigin(0),
+			bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{bgp.NewAsPathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint16{701, 23456})}),
+			bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{100000})}),
+		},
+	}
+	fakeTableDumpv6 = &fakeTableDump{
+		prefix: "2001:db8::", bits: 32, originated: 1009843200, peerIP: "2001:db8::10", peerAS: 15169,
+		attrs: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{bgp.NewAsPathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint16{15169})}),
+		},
+	}
+	tableDumpv4Entry = &ribEntry{
+		Collector:    "route-views2",
+		OriginatedAt: time.Unix(1009843200, 0),
+		Prefix:       "10.0.0.0/8",
+		PeerIP:       "192.0.2.10",
+		PeerAS:       701,
+		Attributes: translated(
+			bgp.NewPathAttributeOrigin(0),
+			bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{701, 100000})}),
+			bgp.NewPathAttributeAs4Path([]*bgp.As4PathParam{bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{100000})}),
+		),
+	}
+	tableDumpv6Entry = &ribEntry{
+		Collector:    "route-views2",
+		OriginatedAt: time.Unix(1009843200, 0),
+		Prefix:       "2001:db8::/32",
+		PeerIP:       "2001:db8::10",
+		PeerAS:       15169,
+		Attributes: translated(
+			bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{15169})}),
+		),
+	}
+)
+
+// dumpedAt returns a copy of e, dumped at time ts.
+func dumpedAt(e *ribEntry, ts time.Time) *ribEntry {
+	c := *e
+	c.DumpedAt = ts
+	return &c
+}
+
+func TestParseTableDump(t *testing.T) {
+	fakeTime := time.Unix(time.Now().Unix(), 0)
+	v4 := encodeBody(t, fakeTableDumpv4)
+	v6 := encodeBody(t, fakeTableDumpv6)
+	badBits := append([]byte(nil), v4...)
+	badBits[8] = 33
+	tests := []struct {
+		desc    string
+		header  *mrt.MRTHeader
+		body    []byte
+		want    *ribEntry
+		wantErr bool
+	}{
+		{
+			desc:   "IPv4 route with AS4_PATH",
+			header: fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMP, tableDumpIPv4, len(v4)),
+			body:   v4,
+			want:   dumpedAt(tableDumpv4Entry, fakeTime),
+		},
+		{
+			desc:   "IPv6 route",
+			header: fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMP, tableDumpIPv6, len(v6)),
+			body:   v6,
+			want:   dumpedAt(tableDumpv6Entry, fakeTime),
+		},
+		{
+			desc:    "truncated attributes",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMP, tableDumpIPv4, len(v4)-1),
+			body:    v4[:len(v4)-1],
+			wantErr: true,
+		},
+		{
+			desc:    "truncated route",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMP, tableDumpIPv6, len(v4)),
+			body:    v4,
+			wantErr: true,
+		},
+		{
+			desc:    "bad prefix length",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMP, tableDumpIPv4, len(badBits)),
+			body:    badBits,
+			wantErr: true,
+		},
+		{
+			desc:    "unknown address family",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMP, tableDumpSubtype(3), len(v4)),
+			body:    v4,
+			wantErr: true,
+		},
+		{
+			desc:    "not a TABLE_DUMP",
+			header:  fakeMRTHeader(t, fakeTime, mrt.TABLE_DUMPv2, mrt.RIB_IPV4_UNICAST, len(v4)),
+			body:    v4,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := parseTableDump("route-views2", test.header, test.body)
+			if gotErr := err != nil; test.wantErr != gotErr {
+				t.Errorf("parseTableDump() = err %v; wantErr = %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got, rowCmpOpts); diff != "" {
+				t.Errorf("parseTableDump diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertTableDump(t *testing.T) {
+	fakeTime := time.Unix(time.Now().Unix(), 0)
+	archive := concatMsgs(
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.TABLE_DUMP, tableDumpIPv4, fakeTableDumpv4)),
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.TABLE_DUMP, tableDumpSubtype(3), fakeTableDumpv4)),
+		encodeMRTMessage(t, fakeMRTMessage(t, fakeTime, mrt.TABLE_DUMP, tableDumpIPv6, fakeTableDumpv6)),
+	)
+	buf := bytes.NewBuffer(nil)
+	c := &ribConverter{collector: "route-views2", format: FormatV1}
+	convertRecords(bytes.NewReader(archive), buf, c.convertNext)
+
+	var want []byte
+	for _, e := range []*ribEntry{dumpedAt(tableDumpv4Entry, fakeTime), dumpedAt(tableDumpv6Entry, fakeTime)} {
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, append(b, '\n')...)
+	}
+	if got := decompressed(t, buf); string(got) != string(want) {
+		t.Errorf("convertRecords() outputs mismatched:\nwant: %s\ngot: %s", want, got)
+	}
+}
@@ -0,0 +1,26 @@
+package converter
+
+import (
+	"path"
+	"strings"
+)
+
+// Directories of the session events of an updates archive, converted apart
+// from its updates as each kind has a table of its own.
+const (
+	StateChangesDir  = "STATE_CHANGES"
+	OpensDir         = "OPENS"
+	NotificationsDir = "NOTIFICATIONS"
+)
+
+// SessionDirs are the directories of every kind of session events.
+var SessionDirs = []string{StateChangesDir, OpensDir, NotificationsDir}
+
+// SessionObject returns the name of the converted session events of the
+// archive object in dir, next to the directory of the archive, ie:
+// STATE_CHANGES/updates.20211101.0000.gz for UPDATES/updates.20211101.0000.bz2
+// and StateChangesDir.
+func SessionObject(object, dir string) string {
+	parent, file := path.Split(ConvertedObject(object))
+	return path.Join(path.Dir(strings.TrimSuffix(parent, "/")), dir, file)
+}
@@ -0,0 +1,27 @@
+package converter
+
+import "testing"
+
+func TestSessionObject(t *testing.T) {
+	tests := []struct {
+		object string
+		dir    string
+		want   string
+	}{
+		{
+			object: "route-views2/bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			dir:    StateChangesDir,
+			want:   "route-views2/bgpdata/2021.11/STATE_CHANGES/updates.20211101.0000.gz",
+		},
+		{
+			object: "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+			dir:    NotificationsDir,
+			want:   "bgpdata/2021.11/NOTIFICATIONS/updates.20211101.0000.gz",
+		},
+	}
+	for _, test := range tests {
+		if got := SessionObject(test.object, test.dir); got != test.want {
+			t.Errorf("SessionObject(%q, %q) = %q; want %q", test.object, test.dir, got, test.want)
+		}
+	}
+}
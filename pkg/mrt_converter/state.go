@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+)
+
+// stateChange represents a BGP4MP STATE_CHANGE record, a transition of the
+// FSM of a peer session at a collector. It will be written as JSON, which
+// will then be picked up by BigQuery, in a table of its own.
+type stateChange struct {
+	Collector string
+	SeenAt    time.Time
+	PeerIP    string
+	PeerAS    uint32
+	OldState  string
+	NewState  string
+}
+
+// bgpStates are the names of the BGP FSM states of RFC 4271.
+var bgpStates = map[mrt.BGPState]string{
+	mrt.IDLE:        "IDLE",
+	mrt.CONNECT:     "CONNECT",
+	mrt.ACTIVE:      "ACTIVE",
+	mrt.OPENSENT:    "OPENSENT",
+	mrt.OPENCONFIRM: "OPENCONFIRM",
+	mrt.ESTABLISHED: "ESTABLISHED",
+}
+
+func stateName(s mrt.BGPState) string {
+	if n, ok := bgpStates[s]; ok {
+		return n
+	}
+	return strconv.Itoa(int(s))
+}
+
+// parseStateChange converts a pair of MRT header and STATE_CHANGE or
+// STATE_CHANGE_AS4 message into a BigQuery compatible state change. Unknown
+// states are written as their numbers.
+func parseStateChange(collector string, h *mrt.MRTHeader, buf []byte) (*stateChange, error) {
+	msg, err := parseBGP4MP(h, buf)
+	if err != nil {
+		return nil, err
+	}
+	sc, ok := msg.Body.(*mrt.BGP4MPStateChange)
+	if !ok {
+		return nil, fmt.Errorf("not a state change: subtype %d", h.SubType)
+	}
+	return &stateChange{
+		Collector: collector,
+		SeenAt:    h.GetTime(),
+		PeerIP:    sc.PeerIpAddress.String(),
+		PeerAS:    sc.PeerAS,
+		OldState:  stateName(sc.OldState),
+		NewState:  stateName(sc.NewState),
+	}, nil
+}
@@ -0,0 +1,79 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+)
+
+func TestParseStateChange(t *testing.T) {
+	fakeTime := time.Unix(time.Now().Unix(), 0)
+	established := encodeBody(t, mrt.NewBGP4MPStateChange(15169, 6447, 0, "1.0.0.0", "2.0.0.0", false, mrt.OPENCONFIRM, mrt.ESTABLISHED))
+	unknown := encodeBody(t, mrt.NewBGP4MPStateChange(100000, 6447, 0, "2001:db8::1", "2001:db8::2", true, mrt.ESTABLISHED, mrt.BGPState(7)))
+	update := encodeBGP4MP(t, fakeAS4Ann)
+	tests := []struct {
+		desc    string
+		header  *mrt.MRTHeader
+		body    []byte
+		want    *stateChange
+		wantErr bool
+	}{
+		{
+			desc:   "STATE_CHANGE",
+			header: fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.STATE_CHANGE, len(established)),
+			body:   established,
+			want: &stateChange{
+				Collector: "route-views2",
+				SeenAt:    fakeTime,
+				PeerIP:    "1.0.0.0",
+				PeerAS:    15169,
+				OldState:  "OPENCONFIRM",
+				NewState:  "ESTABLISHED",
+			},
+		},
+		{
+			desc:   "STATE_CHANGE_AS4 to an unknown state",
+			header: fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.STATE_CHANGE_AS4, len(unknown)),
+			body:   unknown,
+			want: &stateChange{
+				Collector: "route-views2",
+				SeenAt:    fakeTime,
+				PeerIP:    "2001:db8::1",
+				PeerAS:    100000,
+				OldState:  "ESTABLISHED",
+				NewState:  "7",
+			},
+		},
+		{
+			desc:    "not a state change",
+			header:  fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.MESSAGE_AS4, len(update)),
+			body:    update,
+			wantErr: true,
+		},
+		{
+			desc:    "truncated state change",
+			header:  fakeMRTHeader(t, fakeTime, mrt.BGP4MP, mrt.STATE_CHANGE, len(established)-2),
+			body:    established[:len(established)-2],
+			wantErr: true,
+		},
+		{
+			desc:    "bad MRT header",
+			body:    established,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := parseStateChange("route-views2", test.header, test.body)
+			if gotErr := err != nil; test.wantErr != gotErr {
+				t.Errorf("parseStateChange() = err %v; wantErr = %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("parseStateChange diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
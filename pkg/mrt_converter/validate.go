@@ -0,0 +1,92 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+)
+
+// ValidationError describes where an archive is malformed.
+type ValidationError struct {
+	// Offset is the position of the bad byte in the decompressed content.
+	Offset int64
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid archive at byte offset %d: %s", e.Offset, e.Reason)
+}
+
+// mrtTypes are the MRT types defined by RFC 6396, deprecated types are not
+// expected in archives.
+var mrtTypes = map[mrt.MRTType]bool{
+	mrt.OSPFv2:       true,
+	mrt.TABLE_DUMP:   true,
+	mrt.TABLE_DUMPv2: true,
+	mrt.BGP4MP:       true,
+	mrt.BGP4MP_ET:    true,
+	mrt.ISIS:         true,
+	mrt.ISIS_ET:      true,
+	mrt.OSPFv3:       true,
+	mrt.OSPFv3_ET:    true,
+}
+
+// ValidateArchive fully decompresses an archive and walks its MRT records.
+// Content compressed with bzip2 or gzip is detected by its magic bytes. It
+// returns a *ValidationError for malformed content, such as a truncated
+// download, and nil for a well-formed archive.
+func ValidateArchive(r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(bzip2Magic))
+	var dr io.Reader = br
+	switch {
+	case bytes.HasPrefix(magic, bzip2Magic):
+		dr = bzip2.NewReader(br)
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return &ValidationError{Reason: fmt.Sprintf("bad gzip header: %v", err)}
+		}
+		dr = gr
+	}
+
+	var off int64
+	buf := make([]byte, mrt.MRT_COMMON_HEADER_LEN)
+	for {
+		n, err := io.ReadFull(dr, buf)
+		switch {
+		case err == io.EOF && off == 0:
+			return &ValidationError{Reason: "no MRT records"}
+		case err == io.EOF:
+			return nil
+		case err == io.ErrUnexpectedEOF:
+			return &ValidationError{Offset: off, Reason: fmt.Sprintf("truncated MRT header: %d of %d bytes", n, len(buf))}
+		case err != nil:
+			return &ValidationError{Offset: off + int64(n), Reason: fmt.Sprintf("decompression failed: %v", err)}
+		}
+
+		h := &mrt.MRTHeader{}
+		if err := h.DecodeFromBytes(buf); err != nil {
+			return &ValidationError{Offset: off, Reason: fmt.Sprintf("bad MRT header: %v", err)}
+		}
+		if !mrtTypes[h.Type] {
+			return &ValidationError{Offset: off, Reason: fmt.Sprintf("unknown MRT type %d", h.Type)}
+		}
+
+		body := off + mrt.MRT_COMMON_HEADER_LEN
+		m, err := io.CopyN(ioutil.Discard, dr, int64(h.Len))
+		switch {
+		case err == io.EOF:
+			return &ValidationError{Offset: body + m, Reason: fmt.Sprintf("truncated MRT record: %d of %d bytes", m, h.Len)}
+		case err != nil:
+			return &ValidationError{Offset: body + m, Reason: fmt.Sprintf("decompression failed: %v", err)}
+		}
+		off = body + m
+	}
+}
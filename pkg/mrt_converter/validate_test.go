@@ -0,0 +1,85 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/osrg/gobgp/pkg/packet/mrt"
+)
+
+func TestValidateArchive(t *testing.T) {
+	rec := encodeMRTMessage(t, fakeMRTMessage(t, time.Now(), mrt.BGP4MP, mrt.MESSAGE_AS4, fakeAS4Ann))
+	recs := concatMsgs(rec, rec)
+	badType := append([]byte(nil), rec...)
+	badType[5] = 99
+
+	tests := []struct {
+		desc    string
+		content []byte
+		want    *ValidationError
+	}{
+		{
+			desc:    "uncompressed",
+			content: recs,
+		},
+		{
+			desc:    "bzip2",
+			content: bzipped(t, recs),
+		},
+		{
+			desc:    "gzip",
+			content: gzipped(t, recs),
+		},
+		{
+			desc: "empty",
+			want: &ValidationError{Offset: 0, Reason: "no MRT records"},
+		},
+		{
+			desc:    "truncated header",
+			content: bzipped(t, recs[:len(rec)+5]),
+			want:    &ValidationError{Offset: int64(len(rec)), Reason: "truncated MRT header: 5 of 12 bytes"},
+		},
+		{
+			desc:    "truncated record",
+			content: gzipped(t, recs[:len(recs)-3]),
+			want: &ValidationError{
+				Offset: int64(len(recs) - 3),
+				Reason: fmt.Sprintf("truncated MRT record: %d of %d bytes", len(rec)-mrt.MRT_COMMON_HEADER_LEN-3, len(rec)-mrt.MRT_COMMON_HEADER_LEN),
+			},
+		},
+		{
+			desc:    "unknown MRT type",
+			content: concatMsgs(rec, badType),
+			want:    &ValidationError{Offset: int64(len(rec)), Reason: "unknown MRT type 99"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := ValidateArchive(bytes.NewReader(test.content))
+			if test.want == nil {
+				if err != nil {
+					t.Errorf("ValidateArchive() = %v; want nil", err)
+				}
+				return
+			}
+			got, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("ValidateArchive() = %v; want a *ValidationError", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ValidateArchive() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// A truncated bzip2 stream fails to decompress.
+	truncated := bzipped(t, recs)
+	truncated = truncated[:len(truncated)-8]
+	err := ValidateArchive(bytes.NewReader(truncated))
+	if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("ValidateArchive(truncated bzip2) = %v; want a *ValidationError", err)
+	}
+}
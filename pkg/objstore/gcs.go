@@ -0,0 +1,137 @@
+package objstore
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GCS stores objects in a GCS bucket.
+type GCS struct {
+	bucket string
+	bh     *storage.BucketHandle
+}
+
+// NewGCS returns the store of bucket.
+func NewGCS(c *storage.Client, bucket string) *GCS {
+	return &GCS{bucket: bucket, bh: c.Bucket(bucket)}
+}
+
+func fromGCS(a *storage.ObjectAttrs) *Attrs {
+	return &Attrs{
+		Name:            a.Name,
+		ContentType:     a.ContentType,
+		ContentEncoding: a.ContentEncoding,
+		Metadata:        a.Metadata,
+		StorageClass:    a.StorageClass,
+		Size:            a.Size,
+		MD5:             a.MD5,
+		CRC32C:          a.CRC32C,
+		HasCRC32C:       true,
+		Generation:      a.Generation,
+		Updated:         a.Updated,
+	}
+}
+
+func (g *GCS) Put(ctx context.Context, name string, r io.Reader, attrs *Attrs) (*Attrs, error) {
+	// Cancelling the context aborts the write, nothing is committed to the
+	// bucket unless the writer is closed successfully.
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := g.bh.Object(name).NewWriter(wctx)
+	if attrs != nil {
+		w.ContentType = attrs.ContentType
+		w.ContentEncoding = attrs.ContentEncoding
+		w.Metadata = attrs.Metadata
+		w.StorageClass = attrs.StorageClass
+	}
+	crc := crc32.New(crc32cTable)
+	n, err := io.Copy(io.MultiWriter(w, crc), r)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", g.URL(name), err)
+	}
+	a := fromGCS(w.Attrs())
+	a.Size = n
+	a.CRC32C = crc.Sum32()
+	return a, nil
+}
+
+func (g *GCS) Stat(ctx context.Context, name string) (*Attrs, error) {
+	a, err := g.bh.Object(name).Attrs(ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attrs of %s: %v", g.URL(name), err)
+	}
+	return fromGCS(a), nil
+}
+
+func (g *GCS) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	// Read the stored bytes, GCS would otherwise decompress gzip encoded
+	// objects.
+	r, err := g.bh.Object(name).ReadCompressed(true).NewReader(ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", g.URL(name), err)
+	}
+	return r, nil
+}
+
+func (g *GCS) List(ctx context.Context, prefix string, fn func(*Attrs) error) error {
+	it := g.bh.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		a, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %v", g.URL(prefix), err)
+		}
+		if err := fn(fromGCS(a)); err != nil {
+			return err
+		}
+	}
+}
+
+func (g *GCS) Delete(ctx context.Context, name string) error {
+	err := g.bh.Object(name).Delete(ctx)
+	if err == storage.ErrObjectNotExist {
+		return ErrNotExist
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", g.URL(name), err)
+	}
+	return nil
+}
+
+func (g *GCS) UpdateMetadata(ctx context.Context, name string, md map[string]string) (*Attrs, error) {
+	a, err := g.bh.Object(name).Update(ctx, storage.ObjectAttrsToUpdate{Metadata: md})
+	if err == storage.ErrObjectNotExist {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update metadata of %s: %v", g.URL(name), err)
+	}
+	return fromGCS(a), nil
+}
+
+func (g *GCS) URL(name string) string {
+	return fmt.Sprintf("gs://%s/%s", g.bucket, name)
+}
+
+func (g *GCS) Check(ctx context.Context) error {
+	if _, err := g.bh.Attrs(ctx); err != nil {
+		return fmt.Errorf("bad bucket %s: %v", g.bucket, err)
+	}
+	return nil
+}
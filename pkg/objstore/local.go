@@ -0,0 +1,317 @@
+package objstore
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// The attributes of each object are kept as JSON under attrsDir, and
+	// objects are written to tmpDir before they are moved in place. Both
+	// are reserved and never listed.
+	attrsDir = ".attrs"
+	tmpDir   = ".tmp"
+)
+
+// Local stores objects as files under a local directory, so the pipeline
+// can run without a cloud bucket.
+type Local struct {
+	root string
+}
+
+// NewLocal returns the store of the directory root, which is created if
+// needed.
+func NewLocal(root string) (*Local, error) {
+	for _, d := range []string{root, filepath.Join(root, attrsDir), filepath.Join(root, tmpDir)} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create store directory: %v", err)
+		}
+	}
+	return &Local{root: root}, nil
+}
+
+// path returns the file of the object name, and its attributes file.
+func (l *Local) path(name string) (string, string, error) {
+	clean := path.Clean("/" + name)[1:]
+	if name == "" || clean != strings.TrimPrefix(name, "/") {
+		return "", "", fmt.Errorf("bad object name %q", name)
+	}
+	if top := strings.SplitN(clean, "/", 2)[0]; top == attrsDir || top == tmpDir {
+		return "", "", fmt.Errorf("reserved object name %q", name)
+	}
+	return filepath.Join(l.root, filepath.FromSlash(clean)),
+		filepath.Join(l.root, attrsDir, filepath.FromSlash(clean)+".json"), nil
+}
+
+func (l *Local) Put(ctx context.Context, name string, r io.Reader, attrs *Attrs) (*Attrs, error) {
+	fp, ap, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Join(l.root, tmpDir), "put-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", l.URL(name), err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h, crc := md5.New(), crc32.New(crc32cTable)
+	size, err := io.Copy(io.MultiWriter(tmp, h, crc), &ctxReader{ctx: ctx, r: r})
+	if cerr := tmp.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to write %s: %v", l.URL(name), cerr)
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Attrs{
+		Name:      strings.TrimPrefix(name, "/"),
+		Size:      size,
+		MD5:       h.Sum(nil),
+		CRC32C:    crc.Sum32(),
+		HasCRC32C: true,
+	}
+	if attrs != nil {
+		a.ContentType = attrs.ContentType
+		a.ContentEncoding = attrs.ContentEncoding
+		a.Metadata = attrs.Metadata
+		a.StorageClass = attrs.StorageClass
+	}
+	a.Updated = time.Now()
+	a.Generation = a.Updated.UnixNano()
+	for _, d := range []string{filepath.Dir(fp), filepath.Dir(ap)} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %v", l.URL(name), err)
+		}
+	}
+	if err := l.writeAttrs(ap, a); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), fp); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", l.URL(name), err)
+	}
+	return a, nil
+}
+
+// writeAttrs replaces the attributes file ap with a.
+func (l *Local) writeAttrs(ap string, a *Attrs) error {
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Join(l.root, tmpDir), "attrs-")
+	if err != nil {
+		return fmt.Errorf("failed to write attrs of %s: %v", l.URL(a.Name), err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(raw)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), ap)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write attrs of %s: %v", l.URL(a.Name), err)
+	}
+	return nil
+}
+
+func (l *Local) Stat(ctx context.Context, name string) (*Attrs, error) {
+	fp, ap, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(fp)
+	if os.IsNotExist(err) || (err == nil && fi.IsDir()) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %v", l.URL(name), err)
+	}
+	raw, err := os.ReadFile(ap)
+	if err == nil {
+		a := &Attrs{}
+		if err := json.Unmarshal(raw, a); err != nil {
+			return nil, fmt.Errorf("bad attrs of %s: %v", l.URL(name), err)
+		}
+		return a, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read attrs of %s: %v", l.URL(name), err)
+	}
+
+	// Files placed in the directory by other means, e.g. a mirror, have no
+	// attributes file.
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", l.URL(name), err)
+	}
+	defer f.Close()
+	h, crc := md5.New(), crc32.New(crc32cTable)
+	if _, err := io.Copy(io.MultiWriter(h, crc), f); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", l.URL(name), err)
+	}
+	return &Attrs{
+		Name:       strings.TrimPrefix(name, "/"),
+		Size:       fi.Size(),
+		MD5:        h.Sum(nil),
+		CRC32C:     crc.Sum32(),
+		HasCRC32C:  true,
+		Generation: fi.ModTime().UnixNano(),
+		Updated:    fi.ModTime(),
+	}, nil
+}
+
+func (l *Local) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	fp, _, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(fp)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", l.URL(name), err)
+	}
+	return f, nil
+}
+
+func (l *Local) List(ctx context.Context, prefix string, fn func(*Attrs) error) error {
+	// Only walk the deepest directory which holds all matching names.
+	dir := path.Dir(prefix)
+	if strings.HasSuffix(prefix, "/") {
+		dir = strings.TrimSuffix(prefix, "/")
+	}
+	start := filepath.Join(l.root, filepath.FromSlash(dir))
+	var names []string
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == start {
+				return fs.SkipDir
+			}
+			return err
+		}
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if d.IsDir() {
+			if name == attrsDir || name == tmpDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %v", l.URL(prefix), err)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		a, err := l.Stat(ctx, n)
+		if err == ErrNotExist {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Local) Delete(ctx context.Context, name string) error {
+	fp, ap, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(fp)
+	if os.IsNotExist(err) {
+		return ErrNotExist
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", l.URL(name), err)
+	}
+	if err := os.Remove(ap); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attrs of %s: %v", l.URL(name), err)
+	}
+	return nil
+}
+
+func (l *Local) UpdateMetadata(ctx context.Context, name string, md map[string]string) (*Attrs, error) {
+	_, ap, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	a, err := l.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if a.Metadata == nil {
+		a.Metadata = make(map[string]string)
+	}
+	for k, v := range md {
+		a.Metadata[k] = v
+	}
+	a.Updated = time.Now()
+	if err := os.MkdirAll(filepath.Dir(ap), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to write attrs of %s: %v", l.URL(name), err)
+	}
+	if err := l.writeAttrs(ap, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (l *Local) URL(name string) string {
+	return "file://" + path.Join(filepath.ToSlash(l.root), name)
+}
+
+func (l *Local) Check(ctx context.Context) error {
+	fi, err := os.Stat(l.root)
+	if err != nil {
+		return fmt.Errorf("bad directory %s: %v", l.root, err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("bad directory %s: not a directory", l.root)
+	}
+	return nil
+}
+
+// ctxReader fails reads once ctx is done, so a cancelled Put is not
+// committed.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,124 @@
+// Package objstore stores archives and their attributes in a GCS bucket, an
+// S3 compatible bucket or a local directory, so the archive pipeline is not
+// tied to GCS.
+package objstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/minio/minio-go/v7"
+)
+
+// ErrNotExist is returned for objects which do not exist.
+var ErrNotExist = errors.New("object does not exist")
+
+// crc32cTable computes the CRC32C checksums of objects, as GCS does.
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Attrs are the attributes of a stored object.
+type Attrs struct {
+	Name            string
+	ContentType     string
+	ContentEncoding string
+	// Metadata holds the custom key-value pairs stored with the object.
+	Metadata map[string]string
+	// StorageClass of the object, e.g. NEARLINE or STANDARD_IA, the
+	// bucket's default if empty.
+	StorageClass string
+	Size         int64
+	// MD5 of the stored bytes, nil if the store does not know it, e.g. for
+	// composite GCS objects.
+	MD5 []byte
+	// CRC32C (Castagnoli) of the stored bytes, only set if HasCRC32C.
+	CRC32C    uint32
+	HasCRC32C bool
+	// Generation identifies the version of the object; it changes whenever
+	// the object is written.
+	Generation int64
+	Updated    time.Time
+}
+
+// ObjectStore stores the objects of a single bucket or directory.
+type ObjectStore interface {
+	// Put stores the content of r as the object name, along with the
+	// content type, content encoding and metadata of attrs. The object is
+	// only committed if r is read to io.EOF: if reading fails or ctx is
+	// cancelled, nothing is stored.
+	Put(ctx context.Context, name string, r io.Reader, attrs *Attrs) (*Attrs, error)
+	// Stat returns the attributes of the object name.
+	Stat(ctx context.Context, name string) (*Attrs, error)
+	// Read returns the content of the object name, as stored: it is never
+	// decompressed.
+	Read(ctx context.Context, name string) (io.ReadCloser, error)
+	// List calls fn with the attributes of each object whose name starts
+	// with prefix, until fn returns an error.
+	List(ctx context.Context, prefix string, fn func(*Attrs) error) error
+	// Delete removes the object name.
+	Delete(ctx context.Context, name string) error
+	// UpdateMetadata sets the metadata keys of md on the object name.
+	UpdateMetadata(ctx context.Context, name string, md map[string]string) (*Attrs, error)
+	// URL returns the location of the object name, e.g. gs://bucket/name.
+	URL(name string) string
+	// Check returns an error if the bucket or directory is unreachable.
+	Check(ctx context.Context) error
+}
+
+// Clients hold the clients of remote stores.
+type Clients struct {
+	GCS *storage.Client
+	S3  *minio.Client
+}
+
+// Open returns the store at loc, one of:
+//   - gs://bucket, or a bare bucket name, for a GCS bucket;
+//   - s3://bucket, for a bucket of the S3 endpoint of c;
+//   - file:///path, for a local directory.
+func Open(loc string, c *Clients) (ObjectStore, error) {
+	if c == nil {
+		c = &Clients{}
+	}
+	scheme, path := "gs", loc
+	if i := strings.Index(loc, "://"); i >= 0 {
+		scheme, path = loc[:i], loc[i+3:]
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no bucket or directory in %q", loc)
+	}
+	switch scheme {
+	case "gs":
+		if c.GCS == nil {
+			return nil, fmt.Errorf("no GCS client for %s", loc)
+		}
+		return NewGCS(c.GCS, path), nil
+	case "s3":
+		if c.S3 == nil {
+			return nil, fmt.Errorf("no S3 client for %s", loc)
+		}
+		return NewS3(c.S3, path), nil
+	case "file":
+		return NewLocal(path)
+	}
+	return nil, fmt.Errorf("unsupported store %q", loc)
+}
+
+// Copy stores a copy of the object src of s as the object dst of d, with
+// the same attributes.
+func Copy(ctx context.Context, s ObjectStore, src string, d ObjectStore, dst string) (*Attrs, error) {
+	attrs, err := s.Stat(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	rd, err := s.Read(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	return d.Put(ctx, dst, rd, attrs)
+}
@@ -0,0 +1,262 @@
+package objstore
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/minio/minio-go/v7"
+)
+
+// stores returns a store of each backend which does not need a remote
+// service.
+func stores(t *testing.T) map[string]ObjectStore {
+	t.Helper()
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	srv.CreateBucket("foo")
+	local, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]ObjectStore{
+		"gcs":   NewGCS(srv.Client(), "foo"),
+		"local": local,
+	}
+}
+
+// failingReader returns its content, then fails instead of returning EOF.
+type failingReader struct {
+	r io.Reader
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("broken stream")
+	}
+	return n, err
+}
+
+func TestObjectStore(t *testing.T) {
+	content := "Hello, RouteViews!"
+	sum := md5.Sum([]byte(content))
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if err := s.Check(ctx); err != nil {
+				t.Fatalf("Check(): %v", err)
+			}
+			want := &Attrs{
+				Name:            "bgpdata/2021.11/UPDATES/updates.20211101.0000.bz2",
+				ContentType:     "application/octet-stream",
+				ContentEncoding: "bzip2",
+				Metadata:        map[string]string{"routingDataProject": "ROUTEVIEWS"},
+				Size:            int64(len(content)),
+				MD5:             sum[:],
+				CRC32C:          crc32.Checksum([]byte(content), crc32cTable),
+				HasCRC32C:       true,
+			}
+			ignore := cmp.FilterPath(func(p cmp.Path) bool {
+				n := p.Last().String()
+				return n == ".Generation" || n == ".Updated"
+			}, cmp.Ignore())
+
+			got, err := s.Put(ctx, want.Name, strings.NewReader(content), want)
+			if err != nil {
+				t.Fatalf("Put(): %v", err)
+			}
+			if diff := cmp.Diff(want, got, ignore); diff != "" {
+				t.Errorf("Put() diff (-want +got):\n%s", diff)
+			}
+			got, err = s.Stat(ctx, want.Name)
+			if err != nil {
+				t.Fatalf("Stat(): %v", err)
+			}
+			if diff := cmp.Diff(want, got, ignore); diff != "" {
+				t.Errorf("Stat() diff (-want +got):\n%s", diff)
+			}
+
+			rd, err := s.Read(ctx, want.Name)
+			if err != nil {
+				t.Fatalf("Read(): %v", err)
+			}
+			b, err := ioutil.ReadAll(rd)
+			rd.Close()
+			if err != nil || string(b) != content {
+				t.Errorf("Read() = %q, %v; want %q", b, err, content)
+			}
+
+			got, err = s.UpdateMetadata(ctx, want.Name, map[string]string{"routingDataCollector": "route-views2"})
+			if err != nil {
+				t.Fatalf("UpdateMetadata(): %v", err)
+			}
+			wantMD := map[string]string{"routingDataProject": "ROUTEVIEWS", "routingDataCollector": "route-views2"}
+			if diff := cmp.Diff(wantMD, got.Metadata); diff != "" {
+				t.Errorf("UpdateMetadata() diff (-want +got):\n%s", diff)
+			}
+
+			// A failed read of the content stores nothing.
+			if _, err := s.Put(ctx, "bgpdata/broken", &failingReader{strings.NewReader(content)}, nil); err == nil {
+				t.Error("Put() of a failing reader = nil err; want non-nil err")
+			}
+			if _, err := s.Stat(ctx, "bgpdata/broken"); err != ErrNotExist {
+				t.Errorf("Stat() of an aborted object = %v; want ErrNotExist", err)
+			}
+
+			if _, err := s.Put(ctx, "other/file", strings.NewReader(content), nil); err != nil {
+				t.Fatalf("Put(): %v", err)
+			}
+			var names []string
+			err = s.List(ctx, "bgpdata/", func(a *Attrs) error {
+				names = append(names, a.Name)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("List(): %v", err)
+			}
+			if diff := cmp.Diff([]string{want.Name}, names); diff != "" {
+				t.Errorf("List() diff (-want +got):\n%s", diff)
+			}
+
+			cp, err := Copy(ctx, s, want.Name, s, "superseded/"+want.Name)
+			if err != nil {
+				t.Fatalf("Copy(): %v", err)
+			}
+			if diff := cmp.Diff(wantMD, cp.Metadata); diff != "" {
+				t.Errorf("Copy() metadata diff (-want +got):\n%s", diff)
+			}
+
+			if err := s.Delete(ctx, want.Name); err != nil {
+				t.Fatalf("Delete(): %v", err)
+			}
+			if _, err := s.Stat(ctx, want.Name); err != ErrNotExist {
+				t.Errorf("Stat() after Delete() = %v; want ErrNotExist", err)
+			}
+			if err := s.Delete(ctx, want.Name); err != ErrNotExist {
+				t.Errorf("Delete() of a missing object = %v; want ErrNotExist", err)
+			}
+		})
+	}
+}
+
+func TestLocalForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files mirrored into the directory have no attributes file.
+	if err := os.MkdirAll(filepath.Join(dir, "bgpdata"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "bgpdata", "rib.bz2"), []byte("rib"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a, err := s.Stat(context.Background(), "bgpdata/rib.bz2")
+	if err != nil {
+		t.Fatalf("Stat(): %v", err)
+	}
+	if sum := md5.Sum([]byte("rib")); a.Size != 3 || string(a.MD5) != string(sum[:]) {
+		t.Errorf("Stat() = size %d, md5 %x; want 3, %x", a.Size, a.MD5, sum)
+	}
+
+	for _, name := range []string{"../escape", ".attrs/x", "a/../../b", ""} {
+		if _, err := s.Put(context.Background(), name, strings.NewReader("x"), nil); err == nil {
+			t.Errorf("Put(%q) = nil err; want non-nil err", name)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	srv := fakestorage.NewServer(nil)
+	t.Cleanup(srv.Stop)
+	c := &Clients{GCS: srv.Client()}
+	for _, test := range []struct {
+		loc     string
+		want    string
+		wantErr bool
+	}{
+		{loc: "routeviews-archives", want: "gs://routeviews-archives/f"},
+		{loc: "gs://routeviews-archives", want: "gs://routeviews-archives/f"},
+		{loc: "file://" + t.TempDir(), want: "file://"},
+		{loc: "s3://routeviews-archives", wantErr: true}, // No S3 client.
+		{loc: "ftp://routeviews-archives", wantErr: true},
+		{loc: "gs://", wantErr: true},
+	} {
+		s, err := Open(test.loc, c)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Open(%q) = nil err; want non-nil err", test.loc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Open(%q): %v", test.loc, err)
+			continue
+		}
+		if got := s.URL("f"); !strings.HasPrefix(got, test.want) {
+			t.Errorf("Open(%q).URL(f) = %q; want prefix %q", test.loc, got, test.want)
+		}
+	}
+}
+
+// TestFromS3 tests the attributes of S3 objects, as stated and as listed
+// with their metadata.
+func TestFromS3(t *testing.T) {
+	modified := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	enc, err := encodeS3Attrs(&s3Attrs{
+		Metadata:   map[string]string{"collector": "route-views2"},
+		MD5:        "50e3903156f5d2dac6c9f89626d48c75",
+		Generation: modified.UnixNano() + 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Attrs{
+		Name:        "f",
+		ContentType: "application/octet-stream",
+		Metadata:    map[string]string{"collector": "route-views2"},
+		MD5:         []byte{0x50, 0xe3, 0x90, 0x31, 0x56, 0xf5, 0xd2, 0xda, 0xc6, 0xc9, 0xf8, 0x96, 0x26, 0xd4, 0x8c, 0x75},
+		Generation:  modified.UnixNano() + 1,
+		Updated:     modified,
+	}
+
+	stated := minio.ObjectInfo{
+		Key:          "f",
+		ContentType:  "application/octet-stream",
+		LastModified: modified,
+		Metadata:     http.Header{"X-Amz-Meta-" + s3AttrsKey: {enc}},
+	}
+	if diff := cmp.Diff(want, fromS3(stated)); diff != "" {
+		t.Errorf("fromS3() diff (-want +got):\n%s", diff)
+	}
+
+	listed := minio.ObjectInfo{
+		Key:          "f",
+		LastModified: modified,
+		UserMetadata: minio.StringMap{"content-type": "application/octet-stream", "X-Amz-Meta-Routing-Attrs": enc},
+	}
+	if diff := cmp.Diff(want, listedS3(listed)); diff != "" {
+		t.Errorf("listedS3() diff (-want +got):\n%s", diff)
+	}
+	if a := listedS3(minio.ObjectInfo{Key: "f"}); a != nil {
+		t.Errorf("listedS3() of an object listed without metadata = %+v; want nil", a)
+	}
+
+	// Objects written by other means fall back to their last modified time.
+	if got := fromS3(minio.ObjectInfo{Key: "f", LastModified: modified}).Generation; got != modified.UnixNano() {
+		t.Errorf("fromS3() of a foreign object has generation %d; want %d", got, modified.UnixNano())
+	}
+}
@@ -0,0 +1,299 @@
+package objstore
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	// S3 folds the case of metadata keys, so the metadata of an object is
+	// kept as base64 encoded JSON under s3AttrsKey instead, along with the
+	// MD5 of its content.
+	s3AttrsKey = "Routing-Attrs"
+
+	// s3PartSize is the size of the parts of multipart uploads, each part
+	// is buffered in memory.
+	s3PartSize = 16 * 1024 * 1024
+)
+
+// s3Attrs are the attributes kept under s3AttrsKey.
+type s3Attrs struct {
+	Metadata map[string]string `json:"metadata,omitempty"`
+	MD5      string            `json:"md5,omitempty"`
+	CRC32C   *uint32           `json:"crc32c,omitempty"`
+	// Generation is the generation of the object. S3 has none: the last
+	// modified time only has a precision of one second, and version IDs are
+	// opaque strings, which change when the attributes are replaced too.
+	// Each write sets it to the time of the write in nanoseconds, or past
+	// the generation of the object replaced, so that generations are unique
+	// and increasing.
+	Generation int64 `json:"generation,omitempty"`
+}
+
+// S3 stores objects in a bucket of an S3 compatible endpoint.
+type S3 struct {
+	bucket string
+	c      *minio.Client
+}
+
+// NewS3Client returns a client of the S3 compatible endpoint, e.g.
+// s3.amazonaws.com, with credentials from the AWS_* or MINIO_* environment
+// variables, or from the instance's IAM role.
+func NewS3Client(endpoint, region string, insecure bool) (*minio.Client, error) {
+	return minio.New(endpoint, &minio.Options{
+		Creds: credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		}),
+		Secure: !insecure,
+		Region: region,
+	})
+}
+
+// NewS3 returns the store of bucket.
+func NewS3(c *minio.Client, bucket string) *S3 {
+	return &S3{bucket: bucket, c: c}
+}
+
+func encodeS3Attrs(a *s3Attrs) (string, error) {
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func fromS3(o minio.ObjectInfo) *Attrs {
+	a := &Attrs{
+		Name:            o.Key,
+		ContentType:     o.ContentType,
+		ContentEncoding: o.Metadata.Get("Content-Encoding"),
+		StorageClass:    o.StorageClass,
+		Size:            o.Size,
+		Generation:      o.LastModified.UnixNano(),
+		Updated:         o.LastModified,
+	}
+	sa := &s3Attrs{}
+	if raw, err := base64.StdEncoding.DecodeString(o.Metadata.Get("X-Amz-Meta-" + s3AttrsKey)); err == nil {
+		json.Unmarshal(raw, sa)
+	}
+	a.Metadata = sa.Metadata
+	// Objects written by other means have no generation of their own.
+	if sa.Generation != 0 {
+		a.Generation = sa.Generation
+	}
+	if sa.CRC32C != nil {
+		a.CRC32C, a.HasCRC32C = *sa.CRC32C, true
+	}
+	// The ETag of objects uploaded in a single part is their MD5.
+	etag := strings.Trim(o.ETag, `"`)
+	if sa.MD5 != "" {
+		etag = sa.MD5
+	}
+	if md5, err := hex.DecodeString(etag); err == nil {
+		a.MD5 = md5
+	}
+	return a
+}
+
+func notExist(err error) bool {
+	return minio.ToErrorResponse(err).Code == "NoSuchKey"
+}
+
+func (s *S3) Put(ctx context.Context, name string, r io.Reader, attrs *Attrs) (*Attrs, error) {
+	if attrs == nil {
+		attrs = &Attrs{}
+	}
+	sa := &s3Attrs{Metadata: attrs.Metadata, Generation: time.Now().UnixNano()}
+	prev, err := s.Stat(ctx, name)
+	if err != nil && err != ErrNotExist {
+		return nil, err
+	}
+	if prev != nil && prev.Generation >= sa.Generation {
+		sa.Generation = prev.Generation + 1
+	}
+	enc, err := encodeS3Attrs(sa)
+	if err != nil {
+		return nil, err
+	}
+	// The upload is aborted if reading r fails.
+	h, crc := md5.New(), crc32.New(crc32cTable)
+	info, err := s.c.PutObject(ctx, s.bucket, name, io.TeeReader(r, io.MultiWriter(h, crc)), -1, minio.PutObjectOptions{
+		ContentType:     attrs.ContentType,
+		ContentEncoding: attrs.ContentEncoding,
+		UserMetadata:    map[string]string{s3AttrsKey: enc},
+		StorageClass:    attrs.StorageClass,
+		PartSize:        s3PartSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", s.URL(name), err)
+	}
+	if strings.Contains(info.ETag, "-") {
+		// The ETag of a multipart upload is not its MD5, keep the MD5 along
+		// with the metadata. The CRC32C is only known for these objects.
+		sum := crc.Sum32()
+		sa.MD5 = hex.EncodeToString(h.Sum(nil))
+		sa.CRC32C = &sum
+		if err := s.replaceAttrs(ctx, name, attrs, sa); err != nil {
+			return nil, err
+		}
+	}
+	return s.Stat(ctx, name)
+}
+
+// replaceAttrs copies the object name in place, with new attributes.
+func (s *S3) replaceAttrs(ctx context.Context, name string, attrs *Attrs, sa *s3Attrs) error {
+	enc, err := encodeS3Attrs(sa)
+	if err != nil {
+		return err
+	}
+	md := map[string]string{s3AttrsKey: enc}
+	if attrs.ContentType != "" {
+		md["Content-Type"] = attrs.ContentType
+	}
+	if attrs.ContentEncoding != "" {
+		md["Content-Encoding"] = attrs.ContentEncoding
+	}
+	if attrs.StorageClass != "" {
+		md["X-Amz-Storage-Class"] = attrs.StorageClass
+	}
+	_, err = s.c.CopyObject(ctx, minio.CopyDestOptions{
+		Bucket:          s.bucket,
+		Object:          name,
+		UserMetadata:    md,
+		ReplaceMetadata: true,
+	}, minio.CopySrcOptions{Bucket: s.bucket, Object: name})
+	if err != nil {
+		return fmt.Errorf("failed to update attrs of %s: %v", s.URL(name), err)
+	}
+	return nil
+}
+
+func (s *S3) Stat(ctx context.Context, name string) (*Attrs, error) {
+	o, err := s.c.StatObject(ctx, s.bucket, name, minio.StatObjectOptions{})
+	if notExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attrs of %s: %v", s.URL(name), err)
+	}
+	return fromS3(o), nil
+}
+
+func (s *S3) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	// GetObject is lazy, Stat first to report missing objects.
+	if _, err := s.Stat(ctx, name); err != nil {
+		return nil, err
+	}
+	o, err := s.c.GetObject(ctx, s.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", s.URL(name), err)
+	}
+	return o, nil
+}
+
+func (s *S3) List(ctx context.Context, prefix string, fn func(*Attrs) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	opts := minio.ListObjectsOptions{Prefix: prefix, Recursive: true, WithMetadata: true}
+	for o := range s.c.ListObjects(ctx, s.bucket, opts) {
+		if o.Err != nil {
+			return fmt.Errorf("failed to list %s: %v", s.URL(prefix), o.Err)
+		}
+		a := listedS3(o)
+		if a == nil {
+			// Only MinIO lists metadata, other endpoints need a Stat.
+			var err error
+			a, err = s.Stat(ctx, o.Key)
+			if err == ErrNotExist {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listedS3 returns the attributes of the listed object o, nil if the
+// listing did not carry its metadata.
+func listedS3(o minio.ObjectInfo) *Attrs {
+	if o.UserMetadata == nil {
+		return nil
+	}
+	// Listed metadata is keyed by header name, as in o.Metadata of a Stat.
+	md := make(http.Header)
+	for k, v := range o.UserMetadata {
+		md.Set(k, v)
+	}
+	o.Metadata = md
+	o.ContentType = md.Get("Content-Type")
+	return fromS3(o)
+}
+
+func (s *S3) Delete(ctx context.Context, name string) error {
+	// Deleting a missing S3 object succeeds.
+	if _, err := s.Stat(ctx, name); err != nil {
+		return err
+	}
+	if err := s.c.RemoveObject(ctx, s.bucket, name, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete %s: %v", s.URL(name), err)
+	}
+	return nil
+}
+
+func (s *S3) UpdateMetadata(ctx context.Context, name string, md map[string]string) (*Attrs, error) {
+	a, err := s.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	sa := &s3Attrs{Metadata: make(map[string]string), Generation: a.Generation}
+	for k, v := range a.Metadata {
+		sa.Metadata[k] = v
+	}
+	for k, v := range md {
+		sa.Metadata[k] = v
+	}
+	if a.MD5 != nil {
+		sa.MD5 = hex.EncodeToString(a.MD5)
+	}
+	if a.HasCRC32C {
+		sa.CRC32C = &a.CRC32C
+	}
+	if err := s.replaceAttrs(ctx, name, a, sa); err != nil {
+		return nil, err
+	}
+	return s.Stat(ctx, name)
+}
+
+func (s *S3) URL(name string) string {
+	return fmt.Sprintf("s3://%s/%s", s.bucket, name)
+}
+
+func (s *S3) Check(ctx context.Context) error {
+	ok, err := s.c.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("bad bucket %s: %v", s.bucket, err)
+	}
+	if !ok {
+		return fmt.Errorf("bad bucket %s: not found", s.bucket)
+	}
+	return nil
+}
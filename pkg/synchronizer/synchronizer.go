@@ -15,6 +15,8 @@ import (
 	"github.com/jlaffaye/ftp"
 
 	"cloud.google.com/go/storage"
+	"github.com/routeviews/google-cloud-storage/pkg/metrics"
+	converter "github.com/routeviews/google-cloud-storage/pkg/mrt_converter"
 	uploadutils "github.com/routeviews/google-cloud-storage/pkg/utils/upload"
 
 	pb "github.com/routeviews/google-cloud-storage/proto/rv"
@@ -25,6 +27,9 @@ type Synchronizer struct {
 	retryCount    uint64
 	retryInterval time.Duration
 	httpURLRoot   string
+	// batchSize is the number of files per FileUploadBatch call, used when
+	// more than batchSize files of a collector are synchronized at once.
+	batchSize int
 
 	expectedCollectorCount int
 
@@ -85,17 +90,18 @@ func New(cfg *Config) (*Synchronizer, error) {
 		retryCount:    5,
 		retryInterval: 10 * time.Second,
 		httpURLRoot:   hr,
+		batchSize:     16,
 	}, nil
 }
 
 func (s *Synchronizer) uploadFromFTP(ctx context.Context, f string) (bool, error) {
-	gcsMD5, err := uploadutils.MD5FromGCS(ctx, s.bh, f)
-	if err == nil && gcsMD5 != "" {
-		// Skip if file exists.
+	gcsSums, err := uploadutils.ChecksumsFromGCS(ctx, s.bh, f)
+	if err == nil && (gcsSums.MD5 != "" || gcsSums.HasCRC32C) {
+		// Skip if file exists. Composite objects only have a CRC32C.
 		return true, nil
 	}
 
-	ftpMD5, content, err := uploadutils.MD5FromHTTP(s.httpURLRoot + f)
+	ftpSums, content, err := uploadutils.ChecksumsFromHTTP(s.httpURLRoot + f)
 	if err != nil {
 		return false, fmt.Errorf("file %s cannot be downloaded from %s: %v", f, s.httpURLRoot, err)
 	}
@@ -103,7 +109,8 @@ func (s *Synchronizer) uploadFromFTP(ctx context.Context, f string) (bool, error
 	if resp, err := s.gc.FileUpload(ctx, &pb.FileRequest{
 		Filename: f,
 		Content:  content,
-		Md5Sum:   ftpMD5,
+		Md5Sum:   ftpSums.MD5,
+		Checksum: &pb.FileRequest_Sha256{Sha256: ftpSums.SHA256},
 		Project:  pb.FileRequest_ROUTEVIEWS,
 	}); err != nil {
 		return false, fmt.Errorf("FileUpload: err %v, resp %s", err, resp.String())
@@ -111,52 +118,170 @@ func (s *Synchronizer) uploadFromFTP(ctx context.Context, f string) (bool, error
 	return false, nil
 }
 
+// syncFile uploads f if it is missing, with retries. It returns true if f
+// was uploaded.
+func (s *Synchronizer) syncFile(ctx context.Context, f string) (bool, error) {
+	uploaded := false
+	err := backoff.Retry(func() error {
+		skipped, err := s.uploadFromFTP(ctx, f)
+		if err != nil {
+			metrics.ArchivesSynced.WithLabelValues("error").Inc()
+			return fmt.Errorf("uploadFromFTP(%s): %v", f, err)
+		}
+		if skipped {
+			metrics.ArchivesSynced.WithLabelValues("skip").Inc()
+		} else {
+			metrics.ArchivesSynced.WithLabelValues("sync").Inc()
+			uploaded = true
+		}
+		return nil
+	}, backoff.WithMaxRetries(backoff.NewConstantBackOff(s.retryInterval), s.retryCount))
+	return uploaded, err
+}
+
+// uploadBatchFromFTP uploads the missing files among files in a single
+// FileUploadBatch call. It returns the number of files uploaded, and the
+// files which could not be downloaded or uploaded.
+func (s *Synchronizer) uploadBatchFromFTP(ctx context.Context, files []string) (int, []string) {
+	var (
+		failed []string
+		req    = &pb.FileBatchRequest{}
+	)
+	for _, f := range files {
+		gcsSums, err := uploadutils.ChecksumsFromGCS(ctx, s.bh, f)
+		if err == nil && (gcsSums.MD5 != "" || gcsSums.HasCRC32C) {
+			metrics.ArchivesSynced.WithLabelValues("skip").Inc()
+			continue
+		}
+		ftpSums, content, err := uploadutils.ChecksumsFromHTTP(s.httpURLRoot + f)
+		if err != nil {
+			log.Warningf("file %s cannot be downloaded from %s: %v", f, s.httpURLRoot, err)
+			failed = append(failed, f)
+			continue
+		}
+		req.Files = append(req.Files, &pb.FileRequest{
+			Filename: f,
+			Content:  content,
+			Md5Sum:   ftpSums.MD5,
+			Checksum: &pb.FileRequest_Sha256{Sha256: ftpSums.SHA256},
+			Project:  pb.FileRequest_ROUTEVIEWS,
+		})
+	}
+	if len(req.Files) == 0 {
+		return 0, failed
+	}
+
+	log.Infof("Writing a batch of %d files", len(req.Files))
+	var resp *pb.FileBatchResponse
+	err := backoff.Retry(func() error {
+		var err error
+		resp, err = s.gc.FileUploadBatch(ctx, req)
+		if err == nil && len(resp.GetResponses()) != len(req.Files) {
+			err = fmt.Errorf("got %d responses for %d files", len(resp.GetResponses()), len(req.Files))
+		}
+		return err
+	}, backoff.WithMaxRetries(backoff.NewConstantBackOff(s.retryInterval), s.retryCount))
+	if err != nil {
+		log.Warningf("FileUploadBatch: %v", err)
+		for _, f := range req.Files {
+			failed = append(failed, f.GetFilename())
+		}
+		return 0, failed
+	}
+
+	uploaded := 0
+	for i, f := range req.Files {
+		r := resp.GetResponses()[i]
+		switch r.GetStatus() {
+		case pb.FileResponse_SUCCESS:
+			metrics.ArchivesSynced.WithLabelValues("sync").Inc()
+			uploaded++
+		case pb.FileResponse_ALREADY_EXISTS:
+			metrics.ArchivesSynced.WithLabelValues("skip").Inc()
+		default:
+			metrics.ArchivesSynced.WithLabelValues("error").Inc()
+			log.Warningf("FileUploadBatch(%s): %s", f.GetFilename(), r.GetErrorMessage())
+			failed = append(failed, f.GetFilename())
+		}
+	}
+	return uploaded, failed
+}
+
 func (s *Synchronizer) uploadFilesFromFTP(ctx context.Context, files []string) {
 	var lastUploaded string
 	uploadCount := 0
-	for _, f := range files {
-		err := backoff.Retry(func() error {
-			skipped, err := s.uploadFromFTP(ctx, f)
+
+	// Catching up on a large gap, upload the files in batches to save round
+	// trips. Files failing in a batch are retried one by one.
+	n := 1
+	if s.batchSize > 1 && len(files) > s.batchSize {
+		n = s.batchSize
+	}
+	for start := 0; start < len(files); start += n {
+		end := start + n
+		if end > len(files) {
+			end = len(files)
+		}
+		batch := files[start:end]
+		retry := batch
+		if n > 1 {
+			var uploaded int
+			uploaded, retry = s.uploadBatchFromFTP(ctx, batch)
+			uploadCount += uploaded
+		}
+		for _, f := range retry {
+			uploaded, err := s.syncFile(ctx, f)
 			if err != nil {
-				return fmt.Errorf("uploadFromFTP(%s): %v", f, err)
+				log.Error(err)
+				return
 			}
-			if !skipped {
+			if uploaded {
 				uploadCount++
 			}
-			return nil
-		}, backoff.WithMaxRetries(backoff.NewConstantBackOff(s.retryInterval), s.retryCount))
-		if err != nil {
-			log.Error(err)
-			return
 		}
 
-		// Check if any archive is missing (two consecutive archives are over
-		// 15 minutes apart). Structured log for potential metric collection.
-		latest, err := timeFromFilename(f)
-		if err != nil {
-			log.Errorf("failed to parse filename %s", f)
-			continue
-		}
-		if lastUploaded != "" {
-			prev, err := timeFromFilename(lastUploaded)
-			if err != nil {
-				log.Errorf("failed to parse filename %s", f)
-				continue
-			}
-			diff := latest.Sub(prev)
-			if diff > 15*time.Minute {
-				log.WithFields(log.Fields{
-					"prev": lastUploaded,
-					"next": f,
-					"diff": diff.Minutes(),
-				}).Warn("archive missing")
+		for _, f := range batch {
+			if checkGap(lastUploaded, f) {
+				lastUploaded = f
 			}
 		}
-		lastUploaded = f
 	}
 	log.Infof("Uploaded %d files to dir.", uploadCount)
 }
 
+// checkGap reports an archive missing if the archives prev and next are over
+// 15 minutes apart. It returns false if the time of either archive is
+// unknown.
+func checkGap(prev, next string) bool {
+	latest, err := timeFromFilename(next)
+	if err != nil {
+		log.Errorf("failed to parse filename %s", next)
+		return false
+	}
+	if prev == "" {
+		return true
+	}
+	prevTime, err := timeFromFilename(prev)
+	if err != nil {
+		log.Errorf("failed to parse filename %s", next)
+		return false
+	}
+	diff := latest.Sub(prevTime)
+	if diff > 15*time.Minute {
+		log.WithFields(log.Fields{
+			"prev": prev,
+			"next": next,
+			"diff": diff.Minutes(),
+		}).Warn("archive missing")
+		collector, err := converter.RouteViewsCollectorFromPath(next)
+		if err != nil {
+			collector = "unknown"
+		}
+		metrics.ArchivesMissing.WithLabelValues(collector).Inc()
+	}
+	return true
+}
+
 func (s *Synchronizer) initFTP() (*ftp.ServerConn, error) {
 	fc, err := ftp.Dial(s.ftpServer,
 		ftp.DialWithTimeout(10*time.Second))
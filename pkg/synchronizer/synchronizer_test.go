@@ -1,11 +1,20 @@
 package synchronizer
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/fsouza/fake-gcs-server/fakestorage"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	pb "github.com/routeviews/google-cloud-storage/proto/rv"
+	"google.golang.org/grpc"
 )
 
 func parseTime(t *testing.T, ts string) time.Time {
@@ -178,3 +187,84 @@ func TestPrepareDir(t *testing.T) {
 		})
 	}
 }
+
+// fakeRVClient records uploads, and fails the batch upload of failFile.
+type fakeRVClient struct {
+	pb.RVClient
+	failFile string
+
+	mu      sync.Mutex
+	batches [][]string
+	singles []string
+}
+
+func (f *fakeRVClient) FileUpload(ctx context.Context, req *pb.FileRequest, opts ...grpc.CallOption) (*pb.FileResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.singles = append(f.singles, req.GetFilename())
+	return &pb.FileResponse{Status: pb.FileResponse_SUCCESS}, nil
+}
+
+func (f *fakeRVClient) FileUploadBatch(ctx context.Context, req *pb.FileBatchRequest, opts ...grpc.CallOption) (*pb.FileBatchResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	resp := &pb.FileBatchResponse{}
+	for _, r := range req.GetFiles() {
+		names = append(names, r.GetFilename())
+		st := pb.FileResponse_SUCCESS
+		if r.GetFilename() == f.failFile {
+			st = pb.FileResponse_FAIL
+		}
+		resp.Responses = append(resp.Responses, &pb.FileResponse{Status: st, ErrorMessage: "failed"})
+	}
+	f.batches = append(f.batches, names)
+	return resp, nil
+}
+
+func TestUploadFilesFromFTPBatches(t *testing.T) {
+	var files []string
+	for i := 0; i < 10; i++ {
+		files = append(files, fmt.Sprintf("route-views6/bgpdata/2022.05/UPDATES/updates.20220511.%02d00.bz2", i))
+	}
+	gcs := fakestorage.NewServer([]fakestorage.Object{{
+		ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "archive", Name: files[1]},
+		Content:     []byte("stored"),
+	}})
+	t.Cleanup(gcs.Stop)
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "content of %s", r.URL.Path)
+	}))
+	t.Cleanup(web.Close)
+
+	gc := &fakeRVClient{failFile: files[5]}
+	s := &Synchronizer{
+		httpURLRoot: web.URL + "/",
+		batchSize:   4,
+		bh:          gcs.Client().Bucket("archive"),
+		gc:          gc,
+	}
+	s.uploadFilesFromFTP(context.Background(), files)
+
+	wantBatches := [][]string{
+		{files[0], files[2], files[3]},
+		{files[4], files[5], files[6], files[7]},
+		{files[8], files[9]},
+	}
+	if diff := cmp.Diff(wantBatches, gc.batches); diff != "" {
+		t.Errorf("batches diff (-want +got):\n%s", diff)
+	}
+	// The file failing in its batch is retried on its own.
+	if diff := cmp.Diff([]string{files[5]}, gc.singles); diff != "" {
+		t.Errorf("single uploads diff (-want +got):\n%s", diff)
+	}
+
+	// Few files are uploaded one by one.
+	gc = &fakeRVClient{}
+	s.gc = gc
+	s.uploadFilesFromFTP(context.Background(), files[:3])
+	sort.Strings(gc.singles)
+	if len(gc.batches) != 0 || !cmp.Equal([]string{files[0], files[2]}, gc.singles) {
+		t.Errorf("got batches %v, single uploads %v; want no batches, single uploads of %v", gc.batches, gc.singles, []string{files[0], files[2]})
+	}
+}
@@ -3,7 +3,9 @@ package uploadutils
 import (
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"fmt"
+	"hash/crc32"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -11,30 +13,72 @@ import (
 	"cloud.google.com/go/storage"
 )
 
-// MD5FromGCS returns the MD5 encoding from the GCS object's metadata.
-func MD5FromGCS(ctx context.Context, hd *storage.BucketHandle, path string) (string, error) {
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksums are the checksums of a file's content. MD5 and SHA256 are hex
+// encoded, and empty if unknown.
+type Checksums struct {
+	MD5    string
+	SHA256 string
+	// CRC32C (Castagnoli), only set if HasCRC32C.
+	CRC32C    uint32
+	HasCRC32C bool
+}
+
+// ChecksumsOf returns all checksums of content.
+func ChecksumsOf(content []byte) *Checksums {
+	return &Checksums{
+		MD5:       fmt.Sprintf("%x", md5.Sum(content)),
+		SHA256:    fmt.Sprintf("%x", sha256.Sum256(content)),
+		CRC32C:    crc32.Checksum(content, crc32cTable),
+		HasCRC32C: true,
+	}
+}
+
+// Match reports whether c and o are checksums of the same content. The MD5s
+// are compared if both are known, the CRC32Cs otherwise. Checksums without
+// anything in common never match.
+func (c *Checksums) Match(o *Checksums) bool {
+	switch {
+	case c == nil || o == nil:
+		return false
+	case c.MD5 != "" && o.MD5 != "":
+		return c.MD5 == o.MD5
+	case c.HasCRC32C && o.HasCRC32C:
+		return c.CRC32C == o.CRC32C
+	}
+	return false
+}
+
+// ChecksumsFromGCS returns the checksums from the GCS object's metadata.
+// Composite objects have no MD5, only a CRC32C.
+func ChecksumsFromGCS(ctx context.Context, hd *storage.BucketHandle, path string) (*Checksums, error) {
 	attrs, err := hd.Object(path).Attrs(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get attrs for obj: %v", err)
+		return nil, fmt.Errorf("failed to get attrs for obj: %v", err)
+	}
+	c := &Checksums{CRC32C: attrs.CRC32C, HasCRC32C: true}
+	if len(attrs.MD5) > 0 {
+		c.MD5 = fmt.Sprintf("%x", attrs.MD5)
 	}
-	return fmt.Sprintf("%x", attrs.MD5), nil
+	return c, nil
 }
 
-// MD5FromHTTP returns the MD5 encoding and raw bytes by fetching data from the
-// provided URL.
-func MD5FromHTTP(url string) (string, []byte, error) {
+// ChecksumsFromHTTP returns the checksums and raw bytes by fetching data from
+// the provided URL.
+func ChecksumsFromHTTP(url string) (*Checksums, []byte, error) {
 	cli := http.Client{
 		Timeout: time.Minute,
 	}
 	resp, err := cli.Get(url)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to download %s: %v", url, err)
+		return nil, nil, fmt.Errorf("failed to download %s: %v", url, err)
 	}
 	defer resp.Body.Close()
 
 	buf, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
-	return fmt.Sprintf("%x", md5.Sum(buf)), buf, nil
+	return ChecksumsOf(buf), buf, nil
 }
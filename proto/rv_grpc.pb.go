@@ -20,7 +20,60 @@ const _ = grpc.SupportPackageIsVersion7
 type RVClient interface {
 	// FileUpload accepts a single file upload request and
 	// returns a status message to the caller.
+	//
+	// Failed uploads return an error status, with a google.rpc.ErrorInfo
+	// detail whose reason is one of:
+	//   BAD_REQUEST (INVALID_ARGUMENT, along with a google.rpc.BadRequest),
+	//   CHECKSUM_MISMATCH (DATA_LOSS),
+	//   UNSUPPORTED_PROJECT (UNIMPLEMENTED),
+	//   CONFLICT (ALREADY_EXISTS, with the reject conflict policy),
+	//   INVALID_ARCHIVE (INVALID_ARGUMENT, the content failed validation),
+	//   STORAGE_FAILURE (UNAVAILABLE, the call may be retried),
+	//   STREAM_FAILURE (ABORTED, or the code of a failed stream),
+	//   INTERNAL (INTERNAL).
+	// The other upload RPCs fail the same way.
 	FileUpload(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	// FileUploadStream accepts a single file as a stream of chunks. The first
+	// message must carry the header, all following messages carry content.
+	// Use this for files too large to be sent in a single FileRequest.
+	FileUploadStream(ctx context.Context, opts ...grpc.CallOption) (RV_FileUploadStreamClient, error)
+	// FileUploadBatch accepts many files at once, each is handled as a
+	// FileUpload. A response is returned for each file, in the order of the
+	// request, so some files may succeed while others fail.
+	FileUploadBatch(ctx context.Context, in *FileBatchRequest, opts ...grpc.CallOption) (*FileBatchResponse, error)
+	// StartUpload opens a resumable upload session for a single file.
+	// Sessions expire after the session_ttl of the server, 7 days by default;
+	// calls on unknown, finished or expired sessions fail with NOT_FOUND.
+	StartUpload(ctx context.Context, in *StartUploadRequest, opts ...grpc.CallOption) (*UploadSession, error)
+	// AppendUpload adds a piece of content to an upload session at the given
+	// offset, and returns the offset committed so far. An offset past the
+	// committed offset fails with FAILED_PRECONDITION and the reason
+	// BAD_OFFSET, whose committed_offset metadata is where to resume; a
+	// negative offset fails with OUT_OF_RANGE.
+	AppendUpload(ctx context.Context, in *AppendUploadRequest, opts ...grpc.CallOption) (*UploadSession, error)
+	// QueryUpload returns the offset committed so far for an upload session.
+	// Clients resume an interrupted upload from the committed offset.
+	QueryUpload(ctx context.Context, in *QueryUploadRequest, opts ...grpc.CallOption) (*UploadSession, error)
+	// FinishUpload verifies the checksum of all committed content and
+	// finalizes the stored file.
+	FinishUpload(ctx context.Context, in *FinishUploadRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	// GetFileStatus returns the processing state and metadata of a file.
+	GetFileStatus(ctx context.Context, in *FileStatusRequest, opts ...grpc.CallOption) (*FileStatus, error)
+	// ListFileStatus returns the processing state and metadata of all files
+	// matching the request.
+	ListFileStatus(ctx context.Context, in *ListFileStatusRequest, opts ...grpc.CallOption) (*ListFileStatusResponse, error)
+	// ListArchive streams an entry for each file stored for a project, so
+	// clients can find the files they are missing without access to the
+	// archive bucket.
+	ListArchive(ctx context.Context, in *ListArchiveRequest, opts ...grpc.CallOption) (RV_ListArchiveClient, error)
+	// RetractFile withdraws a stored file from the archive, admins only. The
+	// file is moved under a tombstone prefix and its converted updates are
+	// deleted. Each retraction is recorded in an audit log next to the archive.
+	RetractFile(ctx context.Context, in *RetractFileRequest, opts ...grpc.CallOption) (*RetractFileResponse, error)
+	// ReloadConfig reads the server's config file again and swaps it in,
+	// admins only. Calls in flight finish with the config they started with.
+	// The config is unchanged if the file is invalid.
+	ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error)
 }
 
 type rVClient struct {
@@ -40,13 +93,213 @@ func (c *rVClient) FileUpload(ctx context.Context, in *FileRequest, opts ...grpc
 	return out, nil
 }
 
+func (c *rVClient) FileUploadStream(ctx context.Context, opts ...grpc.CallOption) (RV_FileUploadStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RV_ServiceDesc.Streams[0], "/rv.proto.RV/FileUploadStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rVFileUploadStreamClient{stream}
+	return x, nil
+}
+
+type RV_FileUploadStreamClient interface {
+	Send(*FileChunk) error
+	CloseAndRecv() (*FileResponse, error)
+	grpc.ClientStream
+}
+
+type rVFileUploadStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *rVFileUploadStreamClient) Send(m *FileChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *rVFileUploadStreamClient) CloseAndRecv() (*FileResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(FileResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *rVClient) FileUploadBatch(ctx context.Context, in *FileBatchRequest, opts ...grpc.CallOption) (*FileBatchResponse, error) {
+	out := new(FileBatchResponse)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/FileUploadBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rVClient) StartUpload(ctx context.Context, in *StartUploadRequest, opts ...grpc.CallOption) (*UploadSession, error) {
+	out := new(UploadSession)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/StartUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rVClient) AppendUpload(ctx context.Context, in *AppendUploadRequest, opts ...grpc.CallOption) (*UploadSession, error) {
+	out := new(UploadSession)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/AppendUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rVClient) QueryUpload(ctx context.Context, in *QueryUploadRequest, opts ...grpc.CallOption) (*UploadSession, error) {
+	out := new(UploadSession)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/QueryUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rVClient) FinishUpload(ctx context.Context, in *FinishUploadRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/FinishUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rVClient) GetFileStatus(ctx context.Context, in *FileStatusRequest, opts ...grpc.CallOption) (*FileStatus, error) {
+	out := new(FileStatus)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/GetFileStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rVClient) ListFileStatus(ctx context.Context, in *ListFileStatusRequest, opts ...grpc.CallOption) (*ListFileStatusResponse, error) {
+	out := new(ListFileStatusResponse)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/ListFileStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rVClient) ListArchive(ctx context.Context, in *ListArchiveRequest, opts ...grpc.CallOption) (RV_ListArchiveClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RV_ServiceDesc.Streams[1], "/rv.proto.RV/ListArchive", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rVListArchiveClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RV_ListArchiveClient interface {
+	Recv() (*ArchiveEntry, error)
+	grpc.ClientStream
+}
+
+type rVListArchiveClient struct {
+	grpc.ClientStream
+}
+
+func (x *rVListArchiveClient) Recv() (*ArchiveEntry, error) {
+	m := new(ArchiveEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *rVClient) RetractFile(ctx context.Context, in *RetractFileRequest, opts ...grpc.CallOption) (*RetractFileResponse, error) {
+	out := new(RetractFileResponse)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/RetractFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rVClient) ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error) {
+	out := new(ReloadConfigResponse)
+	err := c.cc.Invoke(ctx, "/rv.proto.RV/ReloadConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RVServer is the server API for RV service.
 // All implementations must embed UnimplementedRVServer
 // for forward compatibility
 type RVServer interface {
 	// FileUpload accepts a single file upload request and
 	// returns a status message to the caller.
+	//
+	// Failed uploads return an error status, with a google.rpc.ErrorInfo
+	// detail whose reason is one of:
+	//   BAD_REQUEST (INVALID_ARGUMENT, along with a google.rpc.BadRequest),
+	//   CHECKSUM_MISMATCH (DATA_LOSS),
+	//   UNSUPPORTED_PROJECT (UNIMPLEMENTED),
+	//   CONFLICT (ALREADY_EXISTS, with the reject conflict policy),
+	//   INVALID_ARCHIVE (INVALID_ARGUMENT, the content failed validation),
+	//   STORAGE_FAILURE (UNAVAILABLE, the call may be retried),
+	//   STREAM_FAILURE (ABORTED, or the code of a failed stream),
+	//   INTERNAL (INTERNAL).
+	// The other upload RPCs fail the same way.
 	FileUpload(context.Context, *FileRequest) (*FileResponse, error)
+	// FileUploadStream accepts a single file as a stream of chunks. The first
+	// message must carry the header, all following messages carry content.
+	// Use this for files too large to be sent in a single FileRequest.
+	FileUploadStream(RV_FileUploadStreamServer) error
+	// FileUploadBatch accepts many files at once, each is handled as a
+	// FileUpload. A response is returned for each file, in the order of the
+	// request, so some files may succeed while others fail.
+	FileUploadBatch(context.Context, *FileBatchRequest) (*FileBatchResponse, error)
+	// StartUpload opens a resumable upload session for a single file.
+	// Sessions expire after the session_ttl of the server, 7 days by default;
+	// calls on unknown, finished or expired sessions fail with NOT_FOUND.
+	StartUpload(context.Context, *StartUploadRequest) (*UploadSession, error)
+	// AppendUpload adds a piece of content to an upload session at the given
+	// offset, and returns the offset committed so far. An offset past the
+	// committed offset fails with FAILED_PRECONDITION and the reason
+	// BAD_OFFSET, whose committed_offset metadata is where to resume; a
+	// negative offset fails with OUT_OF_RANGE.
+	AppendUpload(context.Context, *AppendUploadRequest) (*UploadSession, error)
+	// QueryUpload returns the offset committed so far for an upload session.
+	// Clients resume an interrupted upload from the committed offset.
+	QueryUpload(context.Context, *QueryUploadRequest) (*UploadSession, error)
+	// FinishUpload verifies the checksum of all committed content and
+	// finalizes the stored file.
+	FinishUpload(context.Context, *FinishUploadRequest) (*FileResponse, error)
+	// GetFileStatus returns the processing state and metadata of a file.
+	GetFileStatus(context.Context, *FileStatusRequest) (*FileStatus, error)
+	// ListFileStatus returns the processing state and metadata of all files
+	// matching the request.
+	ListFileStatus(context.Context, *ListFileStatusRequest) (*ListFileStatusResponse, error)
+	// ListArchive streams an entry for each file stored for a project, so
+	// clients can find the files they are missing without access to the
+	// archive bucket.
+	ListArchive(*ListArchiveRequest, RV_ListArchiveServer) error
+	// RetractFile withdraws a stored file from the archive, admins only. The
+	// file is moved under a tombstone prefix and its converted updates are
+	// deleted. Each retraction is recorded in an audit log next to the archive.
+	RetractFile(context.Context, *RetractFileRequest) (*RetractFileResponse, error)
+	// ReloadConfig reads the server's config file again and swaps it in,
+	// admins only. Calls in flight finish with the config they started with.
+	// The config is unchanged if the file is invalid.
+	ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error)
 	mustEmbedUnimplementedRVServer()
 }
 
@@ -57,6 +310,39 @@ type UnimplementedRVServer struct {
 func (UnimplementedRVServer) FileUpload(context.Context, *FileRequest) (*FileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FileUpload not implemented")
 }
+func (UnimplementedRVServer) FileUploadStream(RV_FileUploadStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method FileUploadStream not implemented")
+}
+func (UnimplementedRVServer) FileUploadBatch(context.Context, *FileBatchRequest) (*FileBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileUploadBatch not implemented")
+}
+func (UnimplementedRVServer) StartUpload(context.Context, *StartUploadRequest) (*UploadSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartUpload not implemented")
+}
+func (UnimplementedRVServer) AppendUpload(context.Context, *AppendUploadRequest) (*UploadSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppendUpload not implemented")
+}
+func (UnimplementedRVServer) QueryUpload(context.Context, *QueryUploadRequest) (*UploadSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryUpload not implemented")
+}
+func (UnimplementedRVServer) FinishUpload(context.Context, *FinishUploadRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinishUpload not implemented")
+}
+func (UnimplementedRVServer) GetFileStatus(context.Context, *FileStatusRequest) (*FileStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileStatus not implemented")
+}
+func (UnimplementedRVServer) ListFileStatus(context.Context, *ListFileStatusRequest) (*ListFileStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFileStatus not implemented")
+}
+func (UnimplementedRVServer) ListArchive(*ListArchiveRequest, RV_ListArchiveServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListArchive not implemented")
+}
+func (UnimplementedRVServer) RetractFile(context.Context, *RetractFileRequest) (*RetractFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetractFile not implemented")
+}
+func (UnimplementedRVServer) ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
 func (UnimplementedRVServer) mustEmbedUnimplementedRVServer() {}
 
 // UnsafeRVServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +374,215 @@ func _RV_FileUpload_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RV_FileUploadStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RVServer).FileUploadStream(&rVFileUploadStreamServer{stream})
+}
+
+type RV_FileUploadStreamServer interface {
+	SendAndClose(*FileResponse) error
+	Recv() (*FileChunk, error)
+	grpc.ServerStream
+}
+
+type rVFileUploadStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *rVFileUploadStreamServer) SendAndClose(m *FileResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *rVFileUploadStreamServer) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _RV_FileUploadBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).FileUploadBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/FileUploadBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).FileUploadBatch(ctx, req.(*FileBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RV_StartUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).StartUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/StartUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).StartUpload(ctx, req.(*StartUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RV_AppendUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).AppendUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/AppendUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).AppendUpload(ctx, req.(*AppendUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RV_QueryUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).QueryUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/QueryUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).QueryUpload(ctx, req.(*QueryUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RV_FinishUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinishUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).FinishUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/FinishUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).FinishUpload(ctx, req.(*FinishUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RV_GetFileStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).GetFileStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/GetFileStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).GetFileStatus(ctx, req.(*FileStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RV_ListFileStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFileStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).ListFileStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/ListFileStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).ListFileStatus(ctx, req.(*ListFileStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RV_ListArchive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListArchiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RVServer).ListArchive(m, &rVListArchiveServer{stream})
+}
+
+type RV_ListArchiveServer interface {
+	Send(*ArchiveEntry) error
+	grpc.ServerStream
+}
+
+type rVListArchiveServer struct {
+	grpc.ServerStream
+}
+
+func (x *rVListArchiveServer) Send(m *ArchiveEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _RV_RetractFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetractFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).RetractFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/RetractFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).RetractFile(ctx, req.(*RetractFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RV_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RVServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rv.proto.RV/ReloadConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RVServer).ReloadConfig(ctx, req.(*ReloadConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RV_ServiceDesc is the grpc.ServiceDesc for RV service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,7 +594,54 @@ var RV_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FileUpload",
 			Handler:    _RV_FileUpload_Handler,
 		},
+		{
+			MethodName: "FileUploadBatch",
+			Handler:    _RV_FileUploadBatch_Handler,
+		},
+		{
+			MethodName: "StartUpload",
+			Handler:    _RV_StartUpload_Handler,
+		},
+		{
+			MethodName: "AppendUpload",
+			Handler:    _RV_AppendUpload_Handler,
+		},
+		{
+			MethodName: "QueryUpload",
+			Handler:    _RV_QueryUpload_Handler,
+		},
+		{
+			MethodName: "FinishUpload",
+			Handler:    _RV_FinishUpload_Handler,
+		},
+		{
+			MethodName: "GetFileStatus",
+			Handler:    _RV_GetFileStatus_Handler,
+		},
+		{
+			MethodName: "ListFileStatus",
+			Handler:    _RV_ListFileStatus_Handler,
+		},
+		{
+			MethodName: "RetractFile",
+			Handler:    _RV_RetractFile_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _RV_ReloadConfig_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FileUploadStream",
+			Handler:       _RV_FileUploadStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ListArchive",
+			Handler:       _RV_ListArchive_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "rv.proto",
 }
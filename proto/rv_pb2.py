@@ -12,16 +12,38 @@ from google.protobuf import symbol_database as _symbol_database
 _sym_db = _symbol_database.Default()
 
 
+from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x08rv.proto\x12\x08rv.proto\"\xde\x01\n\x0b\x46ileRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06md5sum\x18\x02 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x03 \x01(\x0c\x12\x13\n\x0b\x63onvert_sql\x18\x04 \x01(\x08\x12.\n\x07project\x18\x05 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\"W\n\x07Project\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0e\n\nROUTEVIEWS\x10\x01\x12\x12\n\x0eROUTEVIEWS_RIB\x10\x04\x12\x0c\n\x08RIPE_RIS\x10\x02\x12\r\n\tRPKI_RARC\x10\x03\"\x82\x01\n\x0c\x46ileResponse\x12-\n\x06status\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileResponse.Status\x12\x15\n\rerror_message\x18\x02 \x01(\t\",\n\x06Status\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0b\n\x07SUCCESS\x10\x01\x12\x08\n\x04\x46\x41IL\x10\x02\x32\x41\n\x02RV\x12;\n\nFileUpload\x12\x15.rv.proto.FileRequest\x1a\x16.rv.proto.FileResponseB5Z3github.com/routeviews/google-cloud-storage/proto/rvb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x08rv.proto\x12\x08rv.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8e\x02\n\x0b\x46ileRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06md5sum\x18\x02 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x03 \x01(\x0c\x12\x13\n\x0b\x63onvert_sql\x18\x04 \x01(\x08\x12.\n\x07project\x18\x05 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x10\n\x06sha256\x18\x06 \x01(\tH\x00\x12\x10\n\x06\x63rc32c\x18\x07 \x01(\x07H\x00\"W\n\x07Project\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0e\n\nROUTEVIEWS\x10\x01\x12\x12\n\x0eROUTEVIEWS_RIB\x10\x04\x12\x0c\n\x08RIPE_RIS\x10\x02\x12\r\n\tRPKI_RARC\x10\x03\x42\n\n\x08\x63hecksum\"8\n\x10\x46ileBatchRequest\x12$\n\x05\x66iles\x18\x01 \x03(\x0b\x32\x15.rv.proto.FileRequest\">\n\x11\x46ileBatchResponse\x12)\n\tresponses\x18\x01 \x03(\x0b\x32\x16.rv.proto.FileResponse\"\xf7\x01\n\tFileChunk\x12,\n\x06header\x18\x01 \x01(\x0b\x32\x1a.rv.proto.FileChunk.HeaderH\x00\x12\x11\n\x07\x63ontent\x18\x02 \x01(\x0cH\x00\x1a\x9f\x01\n\x06Header\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06md5sum\x18\x02 \x01(\t\x12\x13\n\x0b\x63onvert_sql\x18\x03 \x01(\x08\x12.\n\x07project\x18\x04 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x10\n\x06sha256\x18\x05 \x01(\tH\x00\x12\x10\n\x06\x63rc32c\x18\x06 \x01(\x07H\x00\x42\n\n\x08\x63hecksumB\x07\n\x05\x63hunk\"\xab\x01\n\x12StartUploadRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06md5sum\x18\x02 \x01(\t\x12\x13\n\x0b\x63onvert_sql\x18\x03 \x01(\x08\x12.\n\x07project\x18\x04 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x10\n\x06sha256\x18\x05 \x01(\tH\x00\x12\x10\n\x06\x63rc32c\x18\x06 \x01(\x07H\x00\x42\n\n\x08\x63hecksum\"=\n\rUploadSession\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x18\n\x10\x63ommitted_offset\x18\x02 \x01(\x03\"J\n\x13\x41ppendUploadRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x0e\n\x06offset\x18\x02 \x01(\x03\x12\x0f\n\x07\x63ontent\x18\x03 \x01(\x0c\"(\n\x12QueryUploadRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\")\n\x13\x46inishUploadRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\"\xef\x02\n\x0c\x46ileResponse\x12-\n\x06status\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileResponse.Status\x12\x15\n\rerror_message\x18\x02 \x01(\t\x12>\n\x0finvalid_archive\x18\x03 \x01(\x0b\x32%.rv.proto.FileResponse.InvalidArchive\x12\x0b\n\x03url\x18\x04 \x01(\t\x12\x12\n\ngeneration\x18\x05 \x01(\x03\x12\x0c\n\x04size\x18\x06 \x01(\x03\x12\x13\n\x06\x63rc32c\x18\x07 \x01(\x07H\x00\x88\x01\x01\x1aH\n\x0eInvalidArchive\x12\x0e\n\x06offset\x18\x01 \x01(\x03\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x16\n\x0equarantine_url\x18\x03 \x01(\t\"@\n\x06Status\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0b\n\x07SUCCESS\x10\x01\x12\x08\n\x04\x46\x41IL\x10\x02\x12\x12\n\x0e\x41LREADY_EXISTS\x10\x03\x42\t\n\x07_crc32c\"U\n\x11\x46ileStatusRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12.\n\x07project\x18\x02 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\"\x93\x01\n\x15ListFileStatusRequest\x12.\n\x07project\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x0e\n\x06prefix\x18\x02 \x01(\t\x12+\n\x06status\x18\x03 \x01(\x0e\x32\x1b.rv.proto.FileStatus.Status\x12\r\n\x05limit\x18\x04 \x01(\x05\"=\n\x16ListFileStatusResponse\x12#\n\x05\x66iles\x18\x01 \x03(\x0b\x32\x14.rv.proto.FileStatus\"\x99\x03\n\nFileStatus\x12+\n\x06status\x18\x01 \x01(\x0e\x32\x1b.rv.proto.FileStatus.Status\x12\x10\n\x08\x66ilename\x18\x02 \x01(\t\x12.\n\x07project\x18\x03 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x11\n\tcollector\x18\x04 \x01(\t\x12\x10\n\x08mrt_type\x18\x05 \x01(\t\x12\x14\n\x0c\x63ontent_type\x18\x06 \x01(\t\x12\x18\n\x10\x63ontent_encoding\x18\x07 \x01(\t\x12+\n\x07updated\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x15\n\rerror_message\x18\t \x01(\t\"\x82\x01\n\x06Status\x12\x0b\n\x07UNKNOWN\x10\x00\x12\r\n\tRECEIVING\x10\x01\x12\n\n\x06STORED\x10\x02\x12\x0e\n\nCONVERTING\x10\x03\x12\r\n\tCONVERTED\x10\x04\x12\x16\n\x12TRANSFER_SCHEDULED\x10\x05\x12\n\n\x06\x46\x41ILED\x10\x06\x12\r\n\tRETRACTED\x10\x07\"\xb2\x01\n\x12ListArchiveRequest\x12.\n\x07project\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x0e\n\x06prefix\x18\x02 \x01(\t\x12.\n\nstart_time\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12,\n\x08\x65nd_time\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xdc\x01\n\x0c\x41rchiveEntry\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0c\n\x04size\x18\x02 \x01(\x03\x12\x0e\n\x06md5sum\x18\x03 \x01(\t\x12\x0e\n\x06sha256\x18\x04 \x01(\t\x12\x13\n\x06\x63rc32c\x18\x05 \x01(\x07H\x00\x88\x01\x01\x12\x12\n\ngeneration\x18\x06 \x01(\x03\x12+\n\x07updated\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12+\n\x06status\x18\x08 \x01(\x0e\x32\x1b.rv.proto.FileStatus.StatusB\t\n\x07_crc32c\"f\n\x12RetractFileRequest\x12.\n\x07project\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x10\n\x08\x66ilename\x18\x02 \x01(\t\x12\x0e\n\x06reason\x18\x03 \x01(\t\"V\n\x13RetractFileResponse\x12\x15\n\rtombstone_url\x18\x01 \x01(\t\x12\x15\n\rconverted_url\x18\x02 \x01(\t\x12\x11\n\taudit_url\x18\x03 \x01(\t\"\x15\n\x13ReloadConfigRequest\"W\n\x14ReloadConfigResponse\x12/\n\x08projects\x18\x01 \x03(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x0e\n\x06sha256\x18\x02 \x01(\t2\xe6\x06\n\x02RV\x12;\n\nFileUpload\x12\x15.rv.proto.FileRequest\x1a\x16.rv.proto.FileResponse\x12\x41\n\x10\x46ileUploadStream\x12\x13.rv.proto.FileChunk\x1a\x16.rv.proto.FileResponse(\x01\x12J\n\x0f\x46ileUploadBatch\x12\x1a.rv.proto.FileBatchRequest\x1a\x1b.rv.proto.FileBatchResponse\x12\x44\n\x0bStartUpload\x12\x1c.rv.proto.StartUploadRequest\x1a\x17.rv.proto.UploadSession\x12\x46\n\x0c\x41ppendUpload\x12\x1d.rv.proto.AppendUploadRequest\x1a\x17.rv.proto.UploadSession\x12\x44\n\x0bQueryUpload\x12\x1c.rv.proto.QueryUploadRequest\x1a\x17.rv.proto.UploadSession\x12\x45\n\x0c\x46inishUpload\x12\x1d.rv.proto.FinishUploadRequest\x1a\x16.rv.proto.FileResponse\x12\x42\n\rGetFileStatus\x12\x1b.rv.proto.FileStatusRequest\x1a\x14.rv.proto.FileStatus\x12S\n\x0eListFileStatus\x12\x1f.rv.proto.ListFileStatusRequest\x1a .rv.proto.ListFileStatusResponse\x12\x45\n\x0bListArchive\x12\x1c.rv.proto.ListArchiveRequest\x1a\x16.rv.proto.ArchiveEntry0\x01\x12J\n\x0bRetractFile\x12\x1c.rv.proto.RetractFileRequest\x1a\x1d.rv.proto.RetractFileResponse\x12M\n\x0cReloadConfig\x12\x1d.rv.proto.ReloadConfigRequest\x1a\x1e.rv.proto.ReloadConfigResponseB5Z3github.com/routeviews/google-cloud-storage/proto/rvb\x06proto3')
 
 
 
 _FILEREQUEST = DESCRIPTOR.message_types_by_name['FileRequest']
+_FILEBATCHREQUEST = DESCRIPTOR.message_types_by_name['FileBatchRequest']
+_FILEBATCHRESPONSE = DESCRIPTOR.message_types_by_name['FileBatchResponse']
+_FILECHUNK = DESCRIPTOR.message_types_by_name['FileChunk']
+_FILECHUNK_HEADER = _FILECHUNK.nested_types_by_name['Header']
+_STARTUPLOADREQUEST = DESCRIPTOR.message_types_by_name['StartUploadRequest']
+_UPLOADSESSION = DESCRIPTOR.message_types_by_name['UploadSession']
+_APPENDUPLOADREQUEST = DESCRIPTOR.message_types_by_name['AppendUploadRequest']
+_QUERYUPLOADREQUEST = DESCRIPTOR.message_types_by_name['QueryUploadRequest']
+_FINISHUPLOADREQUEST = DESCRIPTOR.message_types_by_name['FinishUploadRequest']
 _FILERESPONSE = DESCRIPTOR.message_types_by_name['FileResponse']
+_FILERESPONSE_INVALIDARCHIVE = _FILERESPONSE.nested_types_by_name['InvalidArchive']
+_FILESTATUSREQUEST = DESCRIPTOR.message_types_by_name['FileStatusRequest']
+_LISTFILESTATUSREQUEST = DESCRIPTOR.message_types_by_name['ListFileStatusRequest']
+_LISTFILESTATUSRESPONSE = DESCRIPTOR.message_types_by_name['ListFileStatusResponse']
+_FILESTATUS = DESCRIPTOR.message_types_by_name['FileStatus']
+_LISTARCHIVEREQUEST = DESCRIPTOR.message_types_by_name['ListArchiveRequest']
+_ARCHIVEENTRY = DESCRIPTOR.message_types_by_name['ArchiveEntry']
+_RETRACTFILEREQUEST = DESCRIPTOR.message_types_by_name['RetractFileRequest']
+_RETRACTFILERESPONSE = DESCRIPTOR.message_types_by_name['RetractFileResponse']
+_RELOADCONFIGREQUEST = DESCRIPTOR.message_types_by_name['ReloadConfigRequest']
+_RELOADCONFIGRESPONSE = DESCRIPTOR.message_types_by_name['ReloadConfigResponse']
 _FILEREQUEST_PROJECT = _FILEREQUEST.enum_types_by_name['Project']
 _FILERESPONSE_STATUS = _FILERESPONSE.enum_types_by_name['Status']
+_FILESTATUS_STATUS = _FILESTATUS.enum_types_by_name['Status']
 FileRequest = _reflection.GeneratedProtocolMessageType('FileRequest', (_message.Message,), {
   'DESCRIPTOR' : _FILEREQUEST,
   '__module__' : 'rv_pb2'
@@ -29,26 +51,210 @@ FileRequest = _reflection.GeneratedProtocolMessageType('FileRequest', (_message.
   })
 _sym_db.RegisterMessage(FileRequest)
 
+FileBatchRequest = _reflection.GeneratedProtocolMessageType('FileBatchRequest', (_message.Message,), {
+  'DESCRIPTOR' : _FILEBATCHREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.FileBatchRequest)
+  })
+_sym_db.RegisterMessage(FileBatchRequest)
+
+FileBatchResponse = _reflection.GeneratedProtocolMessageType('FileBatchResponse', (_message.Message,), {
+  'DESCRIPTOR' : _FILEBATCHRESPONSE,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.FileBatchResponse)
+  })
+_sym_db.RegisterMessage(FileBatchResponse)
+
+FileChunk = _reflection.GeneratedProtocolMessageType('FileChunk', (_message.Message,), {
+
+  'Header' : _reflection.GeneratedProtocolMessageType('Header', (_message.Message,), {
+    'DESCRIPTOR' : _FILECHUNK_HEADER,
+    '__module__' : 'rv_pb2'
+    # @@protoc_insertion_point(class_scope:rv.proto.FileChunk.Header)
+    })
+  ,
+  'DESCRIPTOR' : _FILECHUNK,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.FileChunk)
+  })
+_sym_db.RegisterMessage(FileChunk)
+_sym_db.RegisterMessage(FileChunk.Header)
+
+StartUploadRequest = _reflection.GeneratedProtocolMessageType('StartUploadRequest', (_message.Message,), {
+  'DESCRIPTOR' : _STARTUPLOADREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.StartUploadRequest)
+  })
+_sym_db.RegisterMessage(StartUploadRequest)
+
+UploadSession = _reflection.GeneratedProtocolMessageType('UploadSession', (_message.Message,), {
+  'DESCRIPTOR' : _UPLOADSESSION,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.UploadSession)
+  })
+_sym_db.RegisterMessage(UploadSession)
+
+AppendUploadRequest = _reflection.GeneratedProtocolMessageType('AppendUploadRequest', (_message.Message,), {
+  'DESCRIPTOR' : _APPENDUPLOADREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.AppendUploadRequest)
+  })
+_sym_db.RegisterMessage(AppendUploadRequest)
+
+QueryUploadRequest = _reflection.GeneratedProtocolMessageType('QueryUploadRequest', (_message.Message,), {
+  'DESCRIPTOR' : _QUERYUPLOADREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.QueryUploadRequest)
+  })
+_sym_db.RegisterMessage(QueryUploadRequest)
+
+FinishUploadRequest = _reflection.GeneratedProtocolMessageType('FinishUploadRequest', (_message.Message,), {
+  'DESCRIPTOR' : _FINISHUPLOADREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.FinishUploadRequest)
+  })
+_sym_db.RegisterMessage(FinishUploadRequest)
+
 FileResponse = _reflection.GeneratedProtocolMessageType('FileResponse', (_message.Message,), {
+
+  'InvalidArchive' : _reflection.GeneratedProtocolMessageType('InvalidArchive', (_message.Message,), {
+    'DESCRIPTOR' : _FILERESPONSE_INVALIDARCHIVE,
+    '__module__' : 'rv_pb2'
+    # @@protoc_insertion_point(class_scope:rv.proto.FileResponse.InvalidArchive)
+    })
+  ,
   'DESCRIPTOR' : _FILERESPONSE,
   '__module__' : 'rv_pb2'
   # @@protoc_insertion_point(class_scope:rv.proto.FileResponse)
   })
 _sym_db.RegisterMessage(FileResponse)
+_sym_db.RegisterMessage(FileResponse.InvalidArchive)
+
+FileStatusRequest = _reflection.GeneratedProtocolMessageType('FileStatusRequest', (_message.Message,), {
+  'DESCRIPTOR' : _FILESTATUSREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.FileStatusRequest)
+  })
+_sym_db.RegisterMessage(FileStatusRequest)
+
+ListFileStatusRequest = _reflection.GeneratedProtocolMessageType('ListFileStatusRequest', (_message.Message,), {
+  'DESCRIPTOR' : _LISTFILESTATUSREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.ListFileStatusRequest)
+  })
+_sym_db.RegisterMessage(ListFileStatusRequest)
+
+ListFileStatusResponse = _reflection.GeneratedProtocolMessageType('ListFileStatusResponse', (_message.Message,), {
+  'DESCRIPTOR' : _LISTFILESTATUSRESPONSE,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.ListFileStatusResponse)
+  })
+_sym_db.RegisterMessage(ListFileStatusResponse)
+
+FileStatus = _reflection.GeneratedProtocolMessageType('FileStatus', (_message.Message,), {
+  'DESCRIPTOR' : _FILESTATUS,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.FileStatus)
+  })
+_sym_db.RegisterMessage(FileStatus)
+
+ListArchiveRequest = _reflection.GeneratedProtocolMessageType('ListArchiveRequest', (_message.Message,), {
+  'DESCRIPTOR' : _LISTARCHIVEREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.ListArchiveRequest)
+  })
+_sym_db.RegisterMessage(ListArchiveRequest)
+
+ArchiveEntry = _reflection.GeneratedProtocolMessageType('ArchiveEntry', (_message.Message,), {
+  'DESCRIPTOR' : _ARCHIVEENTRY,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.ArchiveEntry)
+  })
+_sym_db.RegisterMessage(ArchiveEntry)
+
+RetractFileRequest = _reflection.GeneratedProtocolMessageType('RetractFileRequest', (_message.Message,), {
+  'DESCRIPTOR' : _RETRACTFILEREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.RetractFileRequest)
+  })
+_sym_db.RegisterMessage(RetractFileRequest)
+
+RetractFileResponse = _reflection.GeneratedProtocolMessageType('RetractFileResponse', (_message.Message,), {
+  'DESCRIPTOR' : _RETRACTFILERESPONSE,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.RetractFileResponse)
+  })
+_sym_db.RegisterMessage(RetractFileResponse)
+
+ReloadConfigRequest = _reflection.GeneratedProtocolMessageType('ReloadConfigRequest', (_message.Message,), {
+  'DESCRIPTOR' : _RELOADCONFIGREQUEST,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.ReloadConfigRequest)
+  })
+_sym_db.RegisterMessage(ReloadConfigRequest)
+
+ReloadConfigResponse = _reflection.GeneratedProtocolMessageType('ReloadConfigResponse', (_message.Message,), {
+  'DESCRIPTOR' : _RELOADCONFIGRESPONSE,
+  '__module__' : 'rv_pb2'
+  # @@protoc_insertion_point(class_scope:rv.proto.ReloadConfigResponse)
+  })
+_sym_db.RegisterMessage(ReloadConfigResponse)
 
 _RV = DESCRIPTOR.services_by_name['RV']
 if _descriptor._USE_C_DESCRIPTORS == False:
 
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z3github.com/routeviews/google-cloud-storage/proto/rv'
-  _FILEREQUEST._serialized_start=23
-  _FILEREQUEST._serialized_end=245
-  _FILEREQUEST_PROJECT._serialized_start=158
-  _FILEREQUEST_PROJECT._serialized_end=245
-  _FILERESPONSE._serialized_start=248
-  _FILERESPONSE._serialized_end=378
-  _FILERESPONSE_STATUS._serialized_start=334
-  _FILERESPONSE_STATUS._serialized_end=378
-  _RV._serialized_start=380
-  _RV._serialized_end=445
+  _FILEREQUEST._serialized_start=56
+  _FILEREQUEST._serialized_end=326
+  _FILEREQUEST_PROJECT._serialized_start=227
+  _FILEREQUEST_PROJECT._serialized_end=314
+  _FILEBATCHREQUEST._serialized_start=328
+  _FILEBATCHREQUEST._serialized_end=384
+  _FILEBATCHRESPONSE._serialized_start=386
+  _FILEBATCHRESPONSE._serialized_end=448
+  _FILECHUNK._serialized_start=451
+  _FILECHUNK._serialized_end=698
+  _FILECHUNK_HEADER._serialized_start=530
+  _FILECHUNK_HEADER._serialized_end=689
+  _STARTUPLOADREQUEST._serialized_start=701
+  _STARTUPLOADREQUEST._serialized_end=872
+  _UPLOADSESSION._serialized_start=874
+  _UPLOADSESSION._serialized_end=935
+  _APPENDUPLOADREQUEST._serialized_start=937
+  _APPENDUPLOADREQUEST._serialized_end=1011
+  _QUERYUPLOADREQUEST._serialized_start=1013
+  _QUERYUPLOADREQUEST._serialized_end=1053
+  _FINISHUPLOADREQUEST._serialized_start=1055
+  _FINISHUPLOADREQUEST._serialized_end=1096
+  _FILERESPONSE._serialized_start=1099
+  _FILERESPONSE._serialized_end=1466
+  _FILERESPONSE_INVALIDARCHIVE._serialized_start=1317
+  _FILERESPONSE_INVALIDARCHIVE._serialized_end=1389
+  _FILERESPONSE_STATUS._serialized_start=1391
+  _FILERESPONSE_STATUS._serialized_end=1455
+  _FILESTATUSREQUEST._serialized_start=1468
+  _FILESTATUSREQUEST._serialized_end=1553
+  _LISTFILESTATUSREQUEST._serialized_start=1556
+  _LISTFILESTATUSREQUEST._serialized_end=1703
+  _LISTFILESTATUSRESPONSE._serialized_start=1705
+  _LISTFILESTATUSRESPONSE._serialized_end=1766
+  _FILESTATUS._serialized_start=1769
+  _FILESTATUS._serialized_end=2178
+  _FILESTATUS_STATUS._serialized_start=2048
+  _FILESTATUS_STATUS._serialized_end=2178
+  _LISTARCHIVEREQUEST._serialized_start=2181
+  _LISTARCHIVEREQUEST._serialized_end=2359
+  _ARCHIVEENTRY._serialized_start=2362
+  _ARCHIVEENTRY._serialized_end=2582
+  _RETRACTFILEREQUEST._serialized_start=2584
+  _RETRACTFILEREQUEST._serialized_end=2686
+  _RETRACTFILERESPONSE._serialized_start=2688
+  _RETRACTFILERESPONSE._serialized_end=2774
+  _RELOADCONFIGREQUEST._serialized_start=2776
+  _RELOADCONFIGREQUEST._serialized_end=2797
+  _RELOADCONFIGRESPONSE._serialized_start=2799
+  _RELOADCONFIGRESPONSE._serialized_end=2886
+  _RV._serialized_start=2889
+  _RV._serialized_end=3759
 # @@protoc_insertion_point(module_scope)
@@ -94,5 +94,8 @@ class Client:
         grpc_client = rv_pb2_grpc.RVStub(self.channel)
         response = grpc_client.FileUpload(payload)
         logger.info("Upload Status: " + str(response.status))
+        if response.url:
+            logger.info(f'Stored {response.url} --- generation: {response.generation}, '
+                        f'size: {response.size}, crc32c: {response.crc32c:08x}')
         if response.error_message:
             logger.error(f'Error uploading {file_path} --- Error message: {response.error_message}')
@@ -12,9 +12,10 @@ from google.protobuf.internal import builder as _builder
 _sym_db = _symbol_database.Default()
 
 
+from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x08rv.proto\x12\x08rv.proto\"\xde\x01\n\x0b\x46ileRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06md5sum\x18\x02 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x03 \x01(\x0c\x12\x13\n\x0b\x63onvert_sql\x18\x04 \x01(\x08\x12.\n\x07project\x18\x05 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\"W\n\x07Project\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0e\n\nROUTEVIEWS\x10\x01\x12\x12\n\x0eROUTEVIEWS_RIB\x10\x04\x12\x0c\n\x08RIPE_RIS\x10\x02\x12\r\n\tRPKI_RARC\x10\x03\"\x82\x01\n\x0c\x46ileResponse\x12-\n\x06status\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileResponse.Status\x12\x15\n\rerror_message\x18\x02 \x01(\t\",\n\x06Status\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0b\n\x07SUCCESS\x10\x01\x12\x08\n\x04\x46\x41IL\x10\x02\x32\x41\n\x02RV\x12;\n\nFileUpload\x12\x15.rv.proto.FileRequest\x1a\x16.rv.proto.FileResponseB5Z3github.com/routeviews/google-cloud-storage/proto/rvb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x08rv.proto\x12\x08rv.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8e\x02\n\x0b\x46ileRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06md5sum\x18\x02 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x03 \x01(\x0c\x12\x13\n\x0b\x63onvert_sql\x18\x04 \x01(\x08\x12.\n\x07project\x18\x05 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x10\n\x06sha256\x18\x06 \x01(\tH\x00\x12\x10\n\x06\x63rc32c\x18\x07 \x01(\x07H\x00\"W\n\x07Project\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0e\n\nROUTEVIEWS\x10\x01\x12\x12\n\x0eROUTEVIEWS_RIB\x10\x04\x12\x0c\n\x08RIPE_RIS\x10\x02\x12\r\n\tRPKI_RARC\x10\x03\x42\n\n\x08\x63hecksum\"8\n\x10\x46ileBatchRequest\x12$\n\x05\x66iles\x18\x01 \x03(\x0b\x32\x15.rv.proto.FileRequest\">\n\x11\x46ileBatchResponse\x12)\n\tresponses\x18\x01 \x03(\x0b\x32\x16.rv.proto.FileResponse\"\xf7\x01\n\tFileChunk\x12,\n\x06header\x18\x01 \x01(\x0b\x32\x1a.rv.proto.FileChunk.HeaderH\x00\x12\x11\n\x07\x63ontent\x18\x02 \x01(\x0cH\x00\x1a\x9f\x01\n\x06Header\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06md5sum\x18\x02 \x01(\t\x12\x13\n\x0b\x63onvert_sql\x18\x03 \x01(\x08\x12.\n\x07project\x18\x04 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x10\n\x06sha256\x18\x05 \x01(\tH\x00\x12\x10\n\x06\x63rc32c\x18\x06 \x01(\x07H\x00\x42\n\n\x08\x63hecksumB\x07\n\x05\x63hunk\"\xab\x01\n\x12StartUploadRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06md5sum\x18\x02 \x01(\t\x12\x13\n\x0b\x63onvert_sql\x18\x03 \x01(\x08\x12.\n\x07project\x18\x04 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x10\n\x06sha256\x18\x05 \x01(\tH\x00\x12\x10\n\x06\x63rc32c\x18\x06 \x01(\x07H\x00\x42\n\n\x08\x63hecksum\"=\n\rUploadSession\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x18\n\x10\x63ommitted_offset\x18\x02 \x01(\x03\"J\n\x13\x41ppendUploadRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x0e\n\x06offset\x18\x02 \x01(\x03\x12\x0f\n\x07\x63ontent\x18\x03 \x01(\x0c\"(\n\x12QueryUploadRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\")\n\x13\x46inishUploadRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\"\xef\x02\n\x0c\x46ileResponse\x12-\n\x06status\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileResponse.Status\x12\x15\n\rerror_message\x18\x02 \x01(\t\x12>\n\x0finvalid_archive\x18\x03 \x01(\x0b\x32%.rv.proto.FileResponse.InvalidArchive\x12\x0b\n\x03url\x18\x04 \x01(\t\x12\x12\n\ngeneration\x18\x05 \x01(\x03\x12\x0c\n\x04size\x18\x06 \x01(\x03\x12\x13\n\x06\x63rc32c\x18\x07 \x01(\x07H\x00\x88\x01\x01\x1aH\n\x0eInvalidArchive\x12\x0e\n\x06offset\x18\x01 \x01(\x03\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x16\n\x0equarantine_url\x18\x03 \x01(\t\"@\n\x06Status\x12\x0b\n\x07UNKNOWN\x10\x00\x12\x0b\n\x07SUCCESS\x10\x01\x12\x08\n\x04\x46\x41IL\x10\x02\x12\x12\n\x0e\x41LREADY_EXISTS\x10\x03\x42\t\n\x07_crc32c\"U\n\x11\x46ileStatusRequest\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12.\n\x07project\x18\x02 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\"\x93\x01\n\x15ListFileStatusRequest\x12.\n\x07project\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x0e\n\x06prefix\x18\x02 \x01(\t\x12+\n\x06status\x18\x03 \x01(\x0e\x32\x1b.rv.proto.FileStatus.Status\x12\r\n\x05limit\x18\x04 \x01(\x05\"=\n\x16ListFileStatusResponse\x12#\n\x05\x66iles\x18\x01 \x03(\x0b\x32\x14.rv.proto.FileStatus\"\x99\x03\n\nFileStatus\x12+\n\x06status\x18\x01 \x01(\x0e\x32\x1b.rv.proto.FileStatus.Status\x12\x10\n\x08\x66ilename\x18\x02 \x01(\t\x12.\n\x07project\x18\x03 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x11\n\tcollector\x18\x04 \x01(\t\x12\x10\n\x08mrt_type\x18\x05 \x01(\t\x12\x14\n\x0c\x63ontent_type\x18\x06 \x01(\t\x12\x18\n\x10\x63ontent_encoding\x18\x07 \x01(\t\x12+\n\x07updated\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x15\n\rerror_message\x18\t \x01(\t\"\x82\x01\n\x06Status\x12\x0b\n\x07UNKNOWN\x10\x00\x12\r\n\tRECEIVING\x10\x01\x12\n\n\x06STORED\x10\x02\x12\x0e\n\nCONVERTING\x10\x03\x12\r\n\tCONVERTED\x10\x04\x12\x16\n\x12TRANSFER_SCHEDULED\x10\x05\x12\n\n\x06\x46\x41ILED\x10\x06\x12\r\n\tRETRACTED\x10\x07\"\xb2\x01\n\x12ListArchiveRequest\x12.\n\x07project\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x0e\n\x06prefix\x18\x02 \x01(\t\x12.\n\nstart_time\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12,\n\x08\x65nd_time\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xdc\x01\n\x0c\x41rchiveEntry\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0c\n\x04size\x18\x02 \x01(\x03\x12\x0e\n\x06md5sum\x18\x03 \x01(\t\x12\x0e\n\x06sha256\x18\x04 \x01(\t\x12\x13\n\x06\x63rc32c\x18\x05 \x01(\x07H\x00\x88\x01\x01\x12\x12\n\ngeneration\x18\x06 \x01(\x03\x12+\n\x07updated\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12+\n\x06status\x18\x08 \x01(\x0e\x32\x1b.rv.proto.FileStatus.StatusB\t\n\x07_crc32c\"f\n\x12RetractFileRequest\x12.\n\x07project\x18\x01 \x01(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x10\n\x08\x66ilename\x18\x02 \x01(\t\x12\x0e\n\x06reason\x18\x03 \x01(\t\"V\n\x13RetractFileResponse\x12\x15\n\rtombstone_url\x18\x01 \x01(\t\x12\x15\n\rconverted_url\x18\x02 \x01(\t\x12\x11\n\taudit_url\x18\x03 \x01(\t\"\x15\n\x13ReloadConfigRequest\"W\n\x14ReloadConfigResponse\x12/\n\x08projects\x18\x01 \x03(\x0e\x32\x1d.rv.proto.FileRequest.Project\x12\x0e\n\x06sha256\x18\x02 \x01(\t2\xe6\x06\n\x02RV\x12;\n\nFileUpload\x12\x15.rv.proto.FileRequest\x1a\x16.rv.proto.FileResponse\x12\x41\n\x10\x46ileUploadStream\x12\x13.rv.proto.FileChunk\x1a\x16.rv.proto.FileResponse(\x01\x12J\n\x0f\x46ileUploadBatch\x12\x1a.rv.proto.FileBatchRequest\x1a\x1b.rv.proto.FileBatchResponse\x12\x44\n\x0bStartUpload\x12\x1c.rv.proto.StartUploadRequest\x1a\x17.rv.proto.UploadSession\x12\x46\n\x0c\x41ppendUpload\x12\x1d.rv.proto.AppendUploadRequest\x1a\x17.rv.proto.UploadSession\x12\x44\n\x0bQueryUpload\x12\x1c.rv.proto.QueryUploadRequest\x1a\x17.rv.proto.UploadSession\x12\x45\n\x0c\x46inishUpload\x12\x1d.rv.proto.FinishUploadRequest\x1a\x16.rv.proto.FileResponse\x12\x42\n\rGetFileStatus\x12\x1b.rv.proto.FileStatusRequest\x1a\x14.rv.proto.FileStatus\x12S\n\x0eListFileStatus\x12\x1f.rv.proto.ListFileStatusRequest\x1a .rv.proto.ListFileStatusResponse\x12\x45\n\x0bListArchive\x12\x1c.rv.proto.ListArchiveRequest\x1a\x16.rv.proto.ArchiveEntry0\x01\x12J\n\x0bRetractFile\x12\x1c.rv.proto.RetractFileRequest\x1a\x1d.rv.proto.RetractFileResponse\x12M\n\x0cReloadConfig\x12\x1d.rv.proto.ReloadConfigRequest\x1a\x1e.rv.proto.ReloadConfigResponseB5Z3github.com/routeviews/google-cloud-storage/proto/rvb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -22,14 +23,56 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'rv_pb2', _globals)
 if _descriptor._USE_C_DESCRIPTORS == False:
   _globals['DESCRIPTOR']._options = None
   _globals['DESCRIPTOR']._serialized_options = b'Z3github.com/routeviews/google-cloud-storage/proto/rv'
-  _globals['_FILEREQUEST']._serialized_start=23
-  _globals['_FILEREQUEST']._serialized_end=245
-  _globals['_FILEREQUEST_PROJECT']._serialized_start=158
-  _globals['_FILEREQUEST_PROJECT']._serialized_end=245
-  _globals['_FILERESPONSE']._serialized_start=248
-  _globals['_FILERESPONSE']._serialized_end=378
-  _globals['_FILERESPONSE_STATUS']._serialized_start=334
-  _globals['_FILERESPONSE_STATUS']._serialized_end=378
-  _globals['_RV']._serialized_start=380
-  _globals['_RV']._serialized_end=445
+  _globals['_FILEREQUEST']._serialized_start=56
+  _globals['_FILEREQUEST']._serialized_end=326
+  _globals['_FILEREQUEST_PROJECT']._serialized_start=227
+  _globals['_FILEREQUEST_PROJECT']._serialized_end=314
+  _globals['_FILEBATCHREQUEST']._serialized_start=328
+  _globals['_FILEBATCHREQUEST']._serialized_end=384
+  _globals['_FILEBATCHRESPONSE']._serialized_start=386
+  _globals['_FILEBATCHRESPONSE']._serialized_end=448
+  _globals['_FILECHUNK']._serialized_start=451
+  _globals['_FILECHUNK']._serialized_end=698
+  _globals['_FILECHUNK_HEADER']._serialized_start=530
+  _globals['_FILECHUNK_HEADER']._serialized_end=689
+  _globals['_STARTUPLOADREQUEST']._serialized_start=701
+  _globals['_STARTUPLOADREQUEST']._serialized_end=872
+  _globals['_UPLOADSESSION']._serialized_start=874
+  _globals['_UPLOADSESSION']._serialized_end=935
+  _globals['_APPENDUPLOADREQUEST']._serialized_start=937
+  _globals['_APPENDUPLOADREQUEST']._serialized_end=1011
+  _globals['_QUERYUPLOADREQUEST']._serialized_start=1013
+  _globals['_QUERYUPLOADREQUEST']._serialized_end=1053
+  _globals['_FINISHUPLOADREQUEST']._serialized_start=1055
+  _globals['_FINISHUPLOADREQUEST']._serialized_end=1096
+  _globals['_FILERESPONSE']._serialized_start=1099
+  _globals['_FILERESPONSE']._serialized_end=1466
+  _globals['_FILERESPONSE_INVALIDARCHIVE']._serialized_start=1317
+  _globals['_FILERESPONSE_INVALIDARCHIVE']._serialized_end=1389
+  _globals['_FILERESPONSE_STATUS']._serialized_start=1391
+  _globals['_FILERESPONSE_STATUS']._serialized_end=1455
+  _globals['_FILESTATUSREQUEST']._serialized_start=1468
+  _globals['_FILESTATUSREQUEST']._serialized_end=1553
+  _globals['_LISTFILESTATUSREQUEST']._serialized_start=1556
+  _globals['_LISTFILESTATUSREQUEST']._serialized_end=1703
+  _globals['_LISTFILESTATUSRESPONSE']._serialized_start=1705
+  _globals['_LISTFILESTATUSRESPONSE']._serialized_end=1766
+  _globals['_FILESTATUS']._serialized_start=1769
+  _globals['_FILESTATUS']._serialized_end=2178
+  _globals['_FILESTATUS_STATUS']._serialized_start=2048
+  _globals['_FILESTATUS_STATUS']._serialized_end=2178
+  _globals['_LISTARCHIVEREQUEST']._serialized_start=2181
+  _globals['_LISTARCHIVEREQUEST']._serialized_end=2359
+  _globals['_ARCHIVEENTRY']._serialized_start=2362
+  _globals['_ARCHIVEENTRY']._serialized_end=2582
+  _globals['_RETRACTFILEREQUEST']._serialized_start=2584
+  _globals['_RETRACTFILEREQUEST']._serialized_end=2686
+  _globals['_RETRACTFILERESPONSE']._serialized_start=2688
+  _globals['_RETRACTFILERESPONSE']._serialized_end=2774
+  _globals['_RELOADCONFIGREQUEST']._serialized_start=2776
+  _globals['_RELOADCONFIGREQUEST']._serialized_end=2797
+  _globals['_RELOADCONFIGRESPONSE']._serialized_start=2799
+  _globals['_RELOADCONFIGRESPONSE']._serialized_end=2886
+  _globals['_RV']._serialized_start=2889
+  _globals['_RV']._serialized_end=3759
 # @@protoc_insertion_point(module_scope)
@@ -2,6 +2,7 @@
 """Client and server classes corresponding to protobuf-defined services."""
 import grpc
 
+from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
 import rv_pb2 as rv__pb2
 
 
@@ -20,6 +21,61 @@ class RVStub(object):
                 request_serializer=rv__pb2.FileRequest.SerializeToString,
                 response_deserializer=rv__pb2.FileResponse.FromString,
                 )
+        self.FileUploadStream = channel.stream_unary(
+                '/rv.proto.RV/FileUploadStream',
+                request_serializer=rv__pb2.FileChunk.SerializeToString,
+                response_deserializer=rv__pb2.FileResponse.FromString,
+                )
+        self.FileUploadBatch = channel.unary_unary(
+                '/rv.proto.RV/FileUploadBatch',
+                request_serializer=rv__pb2.FileBatchRequest.SerializeToString,
+                response_deserializer=rv__pb2.FileBatchResponse.FromString,
+                )
+        self.StartUpload = channel.unary_unary(
+                '/rv.proto.RV/StartUpload',
+                request_serializer=rv__pb2.StartUploadRequest.SerializeToString,
+                response_deserializer=rv__pb2.UploadSession.FromString,
+                )
+        self.AppendUpload = channel.unary_unary(
+                '/rv.proto.RV/AppendUpload',
+                request_serializer=rv__pb2.AppendUploadRequest.SerializeToString,
+                response_deserializer=rv__pb2.UploadSession.FromString,
+                )
+        self.QueryUpload = channel.unary_unary(
+                '/rv.proto.RV/QueryUpload',
+                request_serializer=rv__pb2.QueryUploadRequest.SerializeToString,
+                response_deserializer=rv__pb2.UploadSession.FromString,
+                )
+        self.FinishUpload = channel.unary_unary(
+                '/rv.proto.RV/FinishUpload',
+                request_serializer=rv__pb2.FinishUploadRequest.SerializeToString,
+                response_deserializer=rv__pb2.FileResponse.FromString,
+                )
+        self.GetFileStatus = channel.unary_unary(
+                '/rv.proto.RV/GetFileStatus',
+                request_serializer=rv__pb2.FileStatusRequest.SerializeToString,
+                response_deserializer=rv__pb2.FileStatus.FromString,
+                )
+        self.ListFileStatus = channel.unary_unary(
+                '/rv.proto.RV/ListFileStatus',
+                request_serializer=rv__pb2.ListFileStatusRequest.SerializeToString,
+                response_deserializer=rv__pb2.ListFileStatusResponse.FromString,
+                )
+        self.ListArchive = channel.unary_stream(
+                '/rv.proto.RV/ListArchive',
+                request_serializer=rv__pb2.ListArchiveRequest.SerializeToString,
+                response_deserializer=rv__pb2.ArchiveEntry.FromString,
+                )
+        self.RetractFile = channel.unary_unary(
+                '/rv.proto.RV/RetractFile',
+                request_serializer=rv__pb2.RetractFileRequest.SerializeToString,
+                response_deserializer=rv__pb2.RetractFileResponse.FromString,
+                )
+        self.ReloadConfig = channel.unary_unary(
+                '/rv.proto.RV/ReloadConfig',
+                request_serializer=rv__pb2.ReloadConfigRequest.SerializeToString,
+                response_deserializer=rv__pb2.ReloadConfigResponse.FromString,
+                )
 
 
 class RVServicer(object):
@@ -29,6 +85,114 @@ class RVServicer(object):
     def FileUpload(self, request, context):
         """FileUpload accepts a single file upload request and
         returns a status message to the caller.
+
+        Failed uploads return an error status, with a google.rpc.ErrorInfo
+        detail whose reason is one of:
+          BAD_REQUEST (INVALID_ARGUMENT, along with a google.rpc.BadRequest),
+          CHECKSUM_MISMATCH (DATA_LOSS),
+          UNSUPPORTED_PROJECT (UNIMPLEMENTED),
+          CONFLICT (ALREADY_EXISTS, with the reject conflict policy),
+          INVALID_ARCHIVE (INVALID_ARGUMENT, the content failed validation),
+          STORAGE_FAILURE (UNAVAILABLE, the call may be retried),
+          STREAM_FAILURE (ABORTED, or the code of a failed stream),
+          INTERNAL (INTERNAL).
+        The other upload RPCs fail the same way.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def FileUploadStream(self, request_iterator, context):
+        """FileUploadStream accepts a single file as a stream of chunks. The first
+        message must carry the header, all following messages carry content.
+        Use this for files too large to be sent in a single FileRequest.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def FileUploadBatch(self, request, context):
+        """FileUploadBatch accepts many files at once, each is handled as a
+        FileUpload. A response is returned for each file, in the order of the
+        request, so some files may succeed while others fail.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def StartUpload(self, request, context):
+        """StartUpload opens a resumable upload session for a single file.
+        Sessions expire after the session_ttl of the server, 7 days by default;
+        calls on unknown, finished or expired sessions fail with NOT_FOUND.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AppendUpload(self, request, context):
+        """AppendUpload adds a piece of content to an upload session at the given
+        offset, and returns the offset committed so far. An offset past the
+        committed offset fails with FAILED_PRECONDITION and the reason
+        BAD_OFFSET, whose committed_offset metadata is where to resume; a
+        negative offset fails with OUT_OF_RANGE.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def QueryUpload(self, request, context):
+        """QueryUpload returns the offset committed so far for an upload session.
+        Clients resume an interrupted upload from the committed offset.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def FinishUpload(self, request, context):
+        """FinishUpload verifies the checksum of all committed content and
+        finalizes the stored file.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetFileStatus(self, request, context):
+        """GetFileStatus returns the processing state and metadata of a file.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListFileStatus(self, request, context):
+        """ListFileStatus returns the processing state and metadata of all files
+        matching the request.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListArchive(self, request, context):
+        """ListArchive streams an entry for each file stored for a project, so
+        clients can find the files they are missing without access to the
+        archive bucket.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RetractFile(self, request, context):
+        """RetractFile withdraws a stored file from the archive, admins only. The
+        file is moved under a tombstone prefix and its converted updates are
+        deleted. Each retraction is recorded in an audit log next to the archive.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReloadConfig(self, request, context):
+        """ReloadConfig reads the server's config file again and swaps it in,
+        admins only. Calls in flight finish with the config they started with.
+        The config is unchanged if the file is invalid.
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
@@ -42,6 +206,61 @@ def add_RVServicer_to_server(servicer, server):
                     request_deserializer=rv__pb2.FileRequest.FromString,
                     response_serializer=rv__pb2.FileResponse.SerializeToString,
             ),
+            'FileUploadStream': grpc.stream_unary_rpc_method_handler(
+                    servicer.FileUploadStream,
+                    request_deserializer=rv__pb2.FileChunk.FromString,
+                    response_serializer=rv__pb2.FileResponse.SerializeToString,
+            ),
+            'FileUploadBatch': grpc.unary_unary_rpc_method_handler(
+                    servicer.FileUploadBatch,
+                    request_deserializer=rv__pb2.FileBatchRequest.FromString,
+                    response_serializer=rv__pb2.FileBatchResponse.SerializeToString,
+            ),
+            'StartUpload': grpc.unary_unary_rpc_method_handler(
+                    servicer.StartUpload,
+                    request_deserializer=rv__pb2.StartUploadRequest.FromString,
+                    response_serializer=rv__pb2.UploadSession.SerializeToString,
+            ),
+            'AppendUpload': grpc.unary_unary_rpc_method_handler(
+                    servicer.AppendUpload,
+                    request_deserializer=rv__pb2.AppendUploadRequest.FromString,
+                    response_serializer=rv__pb2.UploadSession.SerializeToString,
+            ),
+            'QueryUpload': grpc.unary_unary_rpc_method_handler(
+                    servicer.QueryUpload,
+                    request_deserializer=rv__pb2.QueryUploadRequest.FromString,
+                    response_serializer=rv__pb2.UploadSession.SerializeToString,
+            ),
+            'FinishUpload': grpc.unary_unary_rpc_method_handler(
+                    servicer.FinishUpload,
+                    request_deserializer=rv__pb2.FinishUploadRequest.FromString,
+                    response_serializer=rv__pb2.FileResponse.SerializeToString,
+            ),
+            'GetFileStatus': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetFileStatus,
+                    request_deserializer=rv__pb2.FileStatusRequest.FromString,
+                    response_serializer=rv__pb2.FileStatus.SerializeToString,
+            ),
+            'ListFileStatus': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListFileStatus,
+                    request_deserializer=rv__pb2.ListFileStatusRequest.FromString,
+                    response_serializer=rv__pb2.ListFileStatusResponse.SerializeToString,
+            ),
+            'ListArchive': grpc.unary_stream_rpc_method_handler(
+                    servicer.ListArchive,
+                    request_deserializer=rv__pb2.ListArchiveRequest.FromString,
+                    response_serializer=rv__pb2.ArchiveEntry.SerializeToString,
+            ),
+            'RetractFile': grpc.unary_unary_rpc_method_handler(
+                    servicer.RetractFile,
+                    request_deserializer=rv__pb2.RetractFileRequest.FromString,
+                    response_serializer=rv__pb2.RetractFileResponse.SerializeToString,
+            ),
+            'ReloadConfig': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReloadConfig,
+                    request_deserializer=rv__pb2.ReloadConfigRequest.FromString,
+                    response_serializer=rv__pb2.ReloadConfigResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'rv.proto.RV', rpc_method_handlers)
@@ -69,3 +288,190 @@ class RV(object):
             rv__pb2.FileResponse.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def FileUploadStream(request_iterator,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.stream_unary(request_iterator, target, '/rv.proto.RV/FileUploadStream',
+            rv__pb2.FileChunk.SerializeToString,
+            rv__pb2.FileResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def FileUploadBatch(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/FileUploadBatch',
+            rv__pb2.FileBatchRequest.SerializeToString,
+            rv__pb2.FileBatchResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def StartUpload(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/StartUpload',
+            rv__pb2.StartUploadRequest.SerializeToString,
+            rv__pb2.UploadSession.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def AppendUpload(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/AppendUpload',
+            rv__pb2.AppendUploadRequest.SerializeToString,
+            rv__pb2.UploadSession.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def QueryUpload(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/QueryUpload',
+            rv__pb2.QueryUploadRequest.SerializeToString,
+            rv__pb2.UploadSession.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def FinishUpload(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/FinishUpload',
+            rv__pb2.FinishUploadRequest.SerializeToString,
+            rv__pb2.FileResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetFileStatus(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/GetFileStatus',
+            rv__pb2.FileStatusRequest.SerializeToString,
+            rv__pb2.FileStatus.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListFileStatus(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/ListFileStatus',
+            rv__pb2.ListFileStatusRequest.SerializeToString,
+            rv__pb2.ListFileStatusResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListArchive(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(request, target, '/rv.proto.RV/ListArchive',
+            rv__pb2.ListArchiveRequest.SerializeToString,
+            rv__pb2.ArchiveEntry.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RetractFile(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/RetractFile',
+            rv__pb2.RetractFileRequest.SerializeToString,
+            rv__pb2.RetractFileResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ReloadConfig(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/rv.proto.RV/ReloadConfig',
+            rv__pb2.ReloadConfigRequest.SerializeToString,
+            rv__pb2.ReloadConfigResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)